@@ -0,0 +1,43 @@
+package backups
+
+import (
+	"github.com/dbbackup-io/cli/cmd/shared"
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/spf13/cobra"
+)
+
+var BackupsCmd = &cobra.Command{
+	Use:   "backups",
+	Short: "Manage stored backups",
+	Long:  "Commands that work on backups already in storage",
+}
+
+var copyFlags shared.CopyFlags
+
+var copyCmd = &cobra.Command{
+	Use:   "copy",
+	Short: "Copy backups between storage backends",
+	Long: `Stream the backups under a prefix from one storage backend to another, e.g. to mirror a
+bucket into another region for disaster recovery. Manifests are copied along with their
+backups, after the copy is verified against the manifest's size and SHA-256.
+
+Azure Blob Storage and Google Cloud Storage cannot be copied from or to yet, because those
+backends cannot read or write backups. Storage is given as a URL:
+` + shared.StorageURLHelp,
+	Example: "  dbbackup backups copy --from s3://prod-backups/postgres --to \"s3://dr-backups/postgres?region=eu-west-1\" --only-missing",
+	Run: func(cmd *cobra.Command, args []string) {
+		shared.HandleBackupsCopy(copyFlags)
+	},
+}
+
+func init() {
+	copyCmd.Flags().StringVar(&copyFlags.From, "from", "", "Source storage URL (required)")
+	copyCmd.Flags().StringVar(&copyFlags.To, "to", "", "Destination storage URL (required)")
+	copyCmd.Flags().BoolVar(&copyFlags.OnlyMissing, "only-missing", false, "Skip backups the destination already holds with their manifest")
+	copyCmd.Flags().IntVar(&copyFlags.Parallel, "parallel", backup.DefaultCopyParallelism, "Number of objects copied at the same time")
+	copyCmd.Flags().BoolVar(&copyFlags.DryRun, "dry-run", false, "List the objects that would be copied without copying them")
+	copyCmd.MarkFlagRequired("from")
+	copyCmd.MarkFlagRequired("to")
+
+	BackupsCmd.AddCommand(copyCmd)
+}
@@ -0,0 +1,113 @@
+package binlog
+
+import (
+	"github.com/dbbackup-io/cli/cmd/shared"
+	"github.com/spf13/cobra"
+)
+
+var BinlogCmd = &cobra.Command{
+	Use:   "binlog",
+	Short: "Archive MySQL binary logs",
+	Long:  `Archive MySQL binary logs to storage for point-in-time recovery`,
+}
+
+var streamCmd = &cobra.Command{
+	Use:   "stream",
+	Short: "Stream MySQL binlogs to storage",
+	Long: `Continuously stream binlogs from a MySQL server with mysqlbinlog and upload each file
+once the server rotates to the next one. Combine with 'dump mysql --master-data' and
+'restore mysql --target-time' for point-in-time recovery.`,
+}
+
+func init() {
+	streamCmd.AddCommand(createS3StreamCommand())
+	streamCmd.AddCommand(createGCSStreamCommand())
+	streamCmd.AddCommand(createAzureStreamCommand())
+	streamCmd.AddCommand(createLocalStreamCommand())
+
+	BinlogCmd.AddCommand(streamCmd)
+}
+
+func createS3StreamCommand() *cobra.Command {
+	var (
+		binlogFlags shared.BinlogFlags
+		s3Flags     shared.S3Flags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "s3",
+		Short: "Stream MySQL binlogs to S3",
+		Long:  `Stream MySQL binlogs to AWS S3`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleBinlogStream(binlogFlags, shared.NewS3Uploader(s3Flags), s3Flags.Path)
+		},
+	}
+
+	shared.AddMySQLBinlogFlags(cmd, &binlogFlags)
+	shared.AddS3Flags(cmd, &s3Flags)
+
+	return cmd
+}
+
+func createGCSStreamCommand() *cobra.Command {
+	var (
+		binlogFlags shared.BinlogFlags
+		gcsFlags    shared.GCSFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "gcs",
+		Short: "Stream MySQL binlogs to Google Cloud Storage",
+		Long:  `Stream MySQL binlogs to Google Cloud Storage`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleBinlogStream(binlogFlags, shared.NewGCSUploader(gcsFlags), gcsFlags.Path)
+		},
+	}
+
+	shared.AddMySQLBinlogFlags(cmd, &binlogFlags)
+	shared.AddGCSFlags(cmd, &gcsFlags)
+
+	return cmd
+}
+
+func createAzureStreamCommand() *cobra.Command {
+	var (
+		binlogFlags shared.BinlogFlags
+		azureFlags  shared.AzureFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "azure",
+		Short: "Stream MySQL binlogs to Azure Blob Storage",
+		Long:  `Stream MySQL binlogs to Azure Blob Storage`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleBinlogStream(binlogFlags, shared.NewAzureUploader(azureFlags), azureFlags.Path)
+		},
+	}
+
+	shared.AddMySQLBinlogFlags(cmd, &binlogFlags)
+	shared.AddAzureFlags(cmd, &azureFlags)
+
+	return cmd
+}
+
+func createLocalStreamCommand() *cobra.Command {
+	var (
+		binlogFlags shared.BinlogFlags
+		localFlags  shared.LocalFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "local",
+		Short: "Stream MySQL binlogs to local storage",
+		Long:  `Stream MySQL binlogs to local filesystem`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleBinlogStream(binlogFlags, shared.NewLocalUploader(localFlags), "")
+		},
+	}
+
+	shared.AddMySQLBinlogFlags(cmd, &binlogFlags)
+	shared.AddLocalFlags(cmd, &localFlags)
+
+	return cmd
+}
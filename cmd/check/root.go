@@ -0,0 +1,33 @@
+package check
+
+import (
+	"github.com/dbbackup-io/cli/cmd/shared"
+	"github.com/spf13/cobra"
+)
+
+var CheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Verify a backup can run",
+	Long: `Run pre-flight checks for a backup without taking it: the client tool exists, the
+credentials work, the user holds the required privileges, the storage accepts a small
+write/read/delete probe, and the database size is estimated. Takes the same flags as dump, e.g.:
+  dbbackup check postgres s3 --db-host db.internal --db-name app --bucket my-backups`,
+}
+
+var storageTypes = []string{"s3", "gcs", "azure", "local"}
+
+// AddSourceCommands adds a subcommand for every database source registered by the dump
+// commands. It must run after their packages are initialized.
+func AddSourceCommands() {
+	for _, source := range shared.CheckSources() {
+		sourceCmd := &cobra.Command{
+			Use:   source.Name,
+			Short: "Check " + source.DBType + " backups",
+			Long:  "Check that " + source.DBType + " backups can run against a storage destination",
+		}
+		for _, storageType := range storageTypes {
+			sourceCmd.AddCommand(shared.CreateCheckCommand(source.DBType, storageType, source.Factory))
+		}
+		CheckCmd.AddCommand(sourceCmd)
+	}
+}
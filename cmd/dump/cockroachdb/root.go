@@ -0,0 +1,40 @@
+package cockroachdb
+
+import (
+	"github.com/dbbackup-io/cli/cmd/shared"
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/sources/cockroachdb"
+	"github.com/spf13/cobra"
+)
+
+var CockroachDBCmd = &cobra.Command{
+	Use:   "cockroachdb",
+	Short: "Dump CockroachDB database",
+	Long:  `Dump CockroachDB database or cluster to storage`,
+}
+
+// CockroachDB dumper factory
+func createCockroachDBDumper(flags shared.DatabaseFlags) backup.DatabaseDumper {
+	return &cockroachdb.Dumper{
+		Connection: cockroachdb.Connection{
+			Host:     flags.Host,
+			Port:     flags.Port,
+			Username: flags.Username,
+			Password: flags.Password,
+			TLS:      flags.TLS,
+		},
+		Database: flags.Database,
+
+		Method:    flags.Cockroach.Method,
+		ExternDir: flags.Cockroach.ExternDir,
+		NodeID:    flags.Cockroach.NodeID,
+	}
+}
+
+func init() {
+	// Dump to storage URLs with --to, or through the per-storage subcommands
+	shared.AddDumpCommands(CockroachDBCmd, "CockroachDB", createCockroachDBDumper)
+
+	// Make the source available to `dbbackup check` with the same flags
+	shared.RegisterCheckSource(CockroachDBCmd.Use, "CockroachDB", createCockroachDBDumper)
+}
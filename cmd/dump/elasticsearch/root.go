@@ -0,0 +1,44 @@
+package elasticsearch
+
+import (
+	"github.com/dbbackup-io/cli/cmd/shared"
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/sources/elasticsearch"
+	"github.com/spf13/cobra"
+)
+
+var ElasticsearchCmd = &cobra.Command{
+	Use:     "elasticsearch",
+	Aliases: []string{"opensearch"},
+	Short:   "Dump Elasticsearch indices",
+	Long:    `Dump Elasticsearch or OpenSearch indices to storage`,
+}
+
+// Elasticsearch dumper factory
+func createElasticsearchDumper(flags shared.DatabaseFlags) backup.DatabaseDumper {
+	return &elasticsearch.Dumper{
+		Connection: elasticsearch.Connection{
+			Host:     flags.Host,
+			Port:     flags.Port,
+			Username: flags.Username,
+			Password: flags.Password,
+			APIKey:   flags.Elastic.APIKey,
+			TLS:      flags.TLS,
+		},
+
+		Indices:        flags.Elastic.Indices,
+		ExcludeIndices: flags.Elastic.ExcludeIndices,
+		Method:         flags.Elastic.Method,
+		BatchSize:      flags.Elastic.BatchSize,
+		RepoDir:        flags.Elastic.RepoDir,
+		LocalRepoDir:   flags.Elastic.LocalRepoDir,
+	}
+}
+
+func init() {
+	// Dump to storage URLs with --to, or through the per-storage subcommands
+	shared.AddDumpCommands(ElasticsearchCmd, "Elasticsearch", createElasticsearchDumper)
+
+	// Make the source available to `dbbackup check` with the same flags
+	shared.RegisterCheckSource(ElasticsearchCmd.Use, "Elasticsearch", createElasticsearchDumper)
+}
@@ -0,0 +1,37 @@
+package mariadb
+
+import (
+	"github.com/dbbackup-io/cli/cmd/shared"
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/sources/mariadb"
+	"github.com/spf13/cobra"
+)
+
+var MariaDBCmd = &cobra.Command{
+	Use:   "mariadb",
+	Short: "Dump MariaDB database",
+	Long:  `Dump MariaDB database to storage`,
+}
+
+// MariaDB dumper factory
+func createMariaDBDumper(flags shared.DatabaseFlags) backup.DatabaseDumper {
+	return &mariadb.Dumper{
+		Host:     flags.Host,
+		Port:     flags.Port,
+		Database: flags.Database,
+		Username: flags.Username,
+		Password: flags.Password,
+		TLS:      flags.TLS,
+
+		Method:       flags.MariaDB.Method,
+		AllDatabases: flags.MariaDB.AllDatabases,
+	}
+}
+
+func init() {
+	// Dump to storage URLs with --to, or through the per-storage subcommands
+	shared.AddDumpCommands(MariaDBCmd, "MariaDB", createMariaDBDumper)
+
+	// Make the source available to `dbbackup check` with the same flags
+	shared.RegisterCheckSource(MariaDBCmd.Use, "MariaDB", createMariaDBDumper)
+}
@@ -21,18 +21,24 @@ func createMongoDBDumper(flags shared.DatabaseFlags) backup.DatabaseDumper {
 		Database: flags.Database,
 		Username: flags.Username,
 		Password: flags.Password,
+		TLS:      flags.TLS,
+		URI:      flags.ConnectionURI,
+		BinDir:   flags.BinDir,
+
+		Collection:             flags.Mongo.Collection,
+		ExcludeCollections:     flags.Mongo.ExcludeCollections,
+		Query:                  flags.Mongo.Query,
+		Oplog:                  flags.Mongo.Oplog,
+		ReadPreference:         flags.Mongo.ReadPreference,
+		AuthenticationDatabase: flags.Mongo.AuthenticationDatabase,
+		NumParallelCollections: flags.Mongo.NumParallelCollections,
 	}
 }
 
 func init() {
-	// Create storage destination commands
-	s3Cmd := shared.CreateS3Command("MongoDB", createMongoDBDumper)
-	gcsCmd := shared.CreateGCSCommand("MongoDB", createMongoDBDumper)
-	azureCmd := shared.CreateAzureCommand("MongoDB", createMongoDBDumper)
-	localCmd := shared.CreateLocalCommand("MongoDB", createMongoDBDumper)
+	// Dump to storage URLs with --to, or through the per-storage subcommands
+	shared.AddDumpCommands(MongoDBCmd, "MongoDB", createMongoDBDumper)
 
-	MongoDBCmd.AddCommand(s3Cmd)
-	MongoDBCmd.AddCommand(gcsCmd)
-	MongoDBCmd.AddCommand(azureCmd)
-	MongoDBCmd.AddCommand(localCmd)
+	// Make the source available to `dbbackup check` with the same flags
+	shared.RegisterCheckSource(MongoDBCmd.Use, "MongoDB", createMongoDBDumper)
 }
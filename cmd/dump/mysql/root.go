@@ -21,18 +21,28 @@ func createMySQLDumper(flags shared.DatabaseFlags) backup.DatabaseDumper {
 		Database: flags.Database,
 		Username: flags.Username,
 		Password: flags.Password,
+		TLS:      flags.TLS,
+
+		MasterData:           flags.MySQL.MasterData,
+		AllDatabases:         flags.MySQL.AllDatabases,
+		Tables:               flags.MySQL.Tables,
+		IgnoreTables:         flags.MySQL.IgnoreTables,
+		NoData:               flags.MySQL.NoData,
+		Events:               flags.MySQL.Events,
+		HexBlob:              flags.MySQL.HexBlob,
+		SetGTIDPurged:        flags.MySQL.SetGTIDPurged,
+		DefaultCharacterSet:  flags.MySQL.DefaultCharacterSet,
+		SkipColumnStatistics: flags.MySQL.SkipColumnStatistics,
+
+		Engine: flags.Engine,
+		BinDir: flags.BinDir,
 	}
 }
 
 func init() {
-	// Create storage destination commands
-	s3Cmd := shared.CreateS3Command("MySQL", createMySQLDumper)
-	gcsCmd := shared.CreateGCSCommand("MySQL", createMySQLDumper)
-	azureCmd := shared.CreateAzureCommand("MySQL", createMySQLDumper)
-	localCmd := shared.CreateLocalCommand("MySQL", createMySQLDumper)
+	// Dump to storage URLs with --to, or through the per-storage subcommands
+	shared.AddDumpCommands(MySQLCmd, "MySQL", createMySQLDumper)
 
-	MySQLCmd.AddCommand(s3Cmd)
-	MySQLCmd.AddCommand(gcsCmd)
-	MySQLCmd.AddCommand(azureCmd)
-	MySQLCmd.AddCommand(localCmd)
+	// Make the source available to `dbbackup check` with the same flags
+	shared.RegisterCheckSource(MySQLCmd.Use, "MySQL", createMySQLDumper)
 }
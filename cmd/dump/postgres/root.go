@@ -21,18 +21,16 @@ func createPostgresDumper(flags shared.DatabaseFlags) backup.DatabaseDumper {
 		Database: flags.Database,
 		Username: flags.Username,
 		Password: flags.Password,
+		TLS:      flags.TLS,
+		Options:  flags.Options,
+		BinDir:   flags.BinDir,
 	}
 }
 
 func init() {
-	// Create storage destination commands
-	s3Cmd := shared.CreateS3Command("PostgreSQL", createPostgresDumper)
-	gcsCmd := shared.CreateGCSCommand("PostgreSQL", createPostgresDumper)
-	azureCmd := shared.CreateAzureCommand("PostgreSQL", createPostgresDumper)
-	localCmd := shared.CreateLocalCommand("PostgreSQL", createPostgresDumper)
+	// Dump to storage URLs with --to, or through the per-storage subcommands
+	shared.AddDumpCommands(PostgresCmd, "PostgreSQL", createPostgresDumper)
 
-	PostgresCmd.AddCommand(s3Cmd)
-	PostgresCmd.AddCommand(gcsCmd)
-	PostgresCmd.AddCommand(azureCmd)
-	PostgresCmd.AddCommand(localCmd)
+	// Make the source available to `dbbackup check` with the same flags
+	shared.RegisterCheckSource(PostgresCmd.Use, "PostgreSQL", createPostgresDumper)
 }
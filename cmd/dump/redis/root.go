@@ -18,19 +18,24 @@ func createRedisDumper(flags shared.DatabaseFlags) backup.DatabaseDumper {
 	return &redis.Dumper{
 		Host:     flags.Host,
 		Port:     flags.Port,
+		Username: flags.Username,
 		Password: flags.Password,
+		TLS:      flags.TLS,
+
+		DBIndex:          flags.Redis.DBIndex,
+		Sentinels:        flags.Redis.Sentinels,
+		SentinelMaster:   flags.Redis.SentinelMaster,
+		SentinelPassword: flags.Redis.SentinelPassword,
+		Cluster:          flags.Redis.Cluster,
+		Engine:           flags.Engine,
+		BinDir:           flags.BinDir,
 	}
 }
 
 func init() {
-	// Create storage destination commands
-	s3Cmd := shared.CreateS3Command("Redis", createRedisDumper)
-	gcsCmd := shared.CreateGCSCommand("Redis", createRedisDumper)
-	azureCmd := shared.CreateAzureCommand("Redis", createRedisDumper)
-	localCmd := shared.CreateLocalCommand("Redis", createRedisDumper)
+	// Dump to storage URLs with --to, or through the per-storage subcommands
+	shared.AddDumpCommands(RedisCmd, "Redis", createRedisDumper)
 
-	RedisCmd.AddCommand(s3Cmd)
-	RedisCmd.AddCommand(gcsCmd)
-	RedisCmd.AddCommand(azureCmd)
-	RedisCmd.AddCommand(localCmd)
+	// Make the source available to `dbbackup check` with the same flags
+	shared.RegisterCheckSource(RedisCmd.Use, "Redis", createRedisDumper)
 }
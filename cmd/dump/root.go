@@ -1,10 +1,15 @@
 package dump
 
 import (
+	"github.com/dbbackup-io/cli/cmd/dump/cockroachdb"
+	"github.com/dbbackup-io/cli/cmd/dump/elasticsearch"
+	"github.com/dbbackup-io/cli/cmd/dump/mariadb"
 	"github.com/dbbackup-io/cli/cmd/dump/mongodb"
 	"github.com/dbbackup-io/cli/cmd/dump/mysql"
 	"github.com/dbbackup-io/cli/cmd/dump/postgres"
 	"github.com/dbbackup-io/cli/cmd/dump/redis"
+	"github.com/dbbackup-io/cli/cmd/dump/sqlite"
+	"github.com/dbbackup-io/cli/cmd/dump/sqlserver"
 	"github.com/spf13/cobra"
 )
 
@@ -20,4 +25,9 @@ func init() {
 	DumpCmd.AddCommand(mysql.MySQLCmd)
 	DumpCmd.AddCommand(mongodb.MongoDBCmd)
 	DumpCmd.AddCommand(redis.RedisCmd)
+	DumpCmd.AddCommand(sqlite.SQLiteCmd)
+	DumpCmd.AddCommand(mariadb.MariaDBCmd)
+	DumpCmd.AddCommand(cockroachdb.CockroachDBCmd)
+	DumpCmd.AddCommand(sqlserver.SQLServerCmd)
+	DumpCmd.AddCommand(elasticsearch.ElasticsearchCmd)
 }
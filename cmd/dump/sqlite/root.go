@@ -0,0 +1,31 @@
+package sqlite
+
+import (
+	"github.com/dbbackup-io/cli/cmd/shared"
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/sources/sqlite"
+	"github.com/spf13/cobra"
+)
+
+var SQLiteCmd = &cobra.Command{
+	Use:   "sqlite",
+	Short: "Dump SQLite database",
+	Long:  `Dump SQLite database file to storage`,
+}
+
+// SQLite dumper factory
+func createSQLiteDumper(flags shared.DatabaseFlags) backup.DatabaseDumper {
+	return &sqlite.Dumper{
+		Path:        flags.SQLite.Path,
+		Method:      flags.SQLite.Method,
+		BusyTimeout: flags.SQLite.BusyTimeout,
+	}
+}
+
+func init() {
+	// Dump to storage URLs with --to, or through the per-storage subcommands
+	shared.AddDumpCommands(SQLiteCmd, "SQLite", createSQLiteDumper)
+
+	// Make the source available to `dbbackup check` with the same flags
+	shared.RegisterCheckSource(SQLiteCmd.Use, "SQLite", createSQLiteDumper)
+}
@@ -0,0 +1,42 @@
+package sqlserver
+
+import (
+	"github.com/dbbackup-io/cli/cmd/shared"
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/sources/sqlserver"
+	"github.com/spf13/cobra"
+)
+
+var SQLServerCmd = &cobra.Command{
+	Use:   "sqlserver",
+	Short: "Dump SQL Server database",
+	Long:  `Dump Microsoft SQL Server database to storage`,
+}
+
+// SQL Server dumper factory
+func createSQLServerDumper(flags shared.DatabaseFlags) backup.DatabaseDumper {
+	return &sqlserver.Dumper{
+		Connection: sqlserver.Connection{
+			Host:     flags.Host,
+			Port:     flags.Port,
+			Username: flags.Username,
+			Password: flags.Password,
+			TLS:      flags.TLS,
+
+			AllowPasswordArg: flags.SQLServer.AllowPasswordArg,
+		},
+		Database: flags.Database,
+
+		Method:         flags.SQLServer.Method,
+		BackupDir:      flags.SQLServer.BackupDir,
+		LocalBackupDir: flags.SQLServer.LocalBackupDir,
+	}
+}
+
+func init() {
+	// Dump to storage URLs with --to, or through the per-storage subcommands
+	shared.AddDumpCommands(SQLServerCmd, "SQL Server", createSQLServerDumper)
+
+	// Make the source available to `dbbackup check` with the same flags
+	shared.RegisterCheckSource(SQLServerCmd.Use, "SQL Server", createSQLServerDumper)
+}
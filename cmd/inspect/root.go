@@ -0,0 +1,48 @@
+package inspect
+
+import (
+	"github.com/dbbackup-io/cli/cmd/shared"
+	"github.com/spf13/cobra"
+)
+
+var InspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "Inspect backup contents",
+	Long:  `Inspect the contents of a backup without restoring it`,
+}
+
+var redisCmd = &cobra.Command{
+	Use:   "redis",
+	Short: "Inspect a Redis backup",
+	Long: `Parse a Redis RDB backup (or Redis Cluster archive) and print key counts, memory by
+prefix, TTL distribution and the largest keys, or export keys as JSON lines or RESP
+commands for a selective restore, e.g.:
+  dbbackup inspect redis s3 --bucket my-bucket --backup-file prod/redis.rdb \
+    --format resp --match 'session:*' | redis-cli --pipe`,
+}
+
+func init() {
+	// Create storage source commands
+	redisCmd.AddCommand(createRedisInspectCommand("s3", "S3", shared.AddS3RestoreFlags))
+	redisCmd.AddCommand(createRedisInspectCommand("gcs", "Google Cloud Storage", shared.AddGCSRestoreFlags))
+	redisCmd.AddCommand(createRedisInspectCommand("azure", "Azure Blob Storage", shared.AddAzureRestoreFlags))
+	redisCmd.AddCommand(createRedisInspectCommand("local", "local storage", shared.AddLocalRestoreFlags))
+
+	InspectCmd.AddCommand(redisCmd)
+}
+
+func createRedisInspectCommand(storageType, storageName string, addFlags func(cmd *cobra.Command)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   storageType,
+		Short: "Inspect a Redis backup from " + storageName,
+		Long:  "Inspect a Redis backup stored in " + storageName,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleRedisInspect(cmd, storageType)
+		},
+	}
+
+	shared.AddRedisInspectFlags(cmd)
+	addFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,93 @@
+package cockroachdb
+
+import (
+	"github.com/dbbackup-io/cli/cmd/shared"
+	"github.com/spf13/cobra"
+)
+
+var CockroachDBCmd = &cobra.Command{
+	Use:   "cockroachdb",
+	Short: "Restore CockroachDB database",
+	Long:  `Restore CockroachDB database from cloud storage`,
+}
+
+func init() {
+	// Create storage destination restore commands
+	s3RestoreCmd := createS3RestoreCommand()
+	gcsRestoreCmd := createGCSRestoreCommand()
+	azureRestoreCmd := createAzureRestoreCommand()
+	localRestoreCmd := createLocalRestoreCommand()
+
+	CockroachDBCmd.AddCommand(s3RestoreCmd)
+	CockroachDBCmd.AddCommand(gcsRestoreCmd)
+	CockroachDBCmd.AddCommand(azureRestoreCmd)
+	CockroachDBCmd.AddCommand(localRestoreCmd)
+}
+
+func createS3RestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "s3",
+		Short: "Restore CockroachDB database from S3",
+		Long:  `Restore CockroachDB database from AWS S3`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleCockroachDBRestore(cmd, args, "s3")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddCockroachDBRestoreFlags(cmd)
+	shared.AddS3RestoreFlags(cmd)
+
+	return cmd
+}
+
+func createGCSRestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gcs",
+		Short: "Restore CockroachDB database from Google Cloud Storage",
+		Long:  `Restore CockroachDB database from Google Cloud Storage`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleCockroachDBRestore(cmd, args, "gcs")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddCockroachDBRestoreFlags(cmd)
+	shared.AddGCSRestoreFlags(cmd)
+
+	return cmd
+}
+
+func createAzureRestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "azure",
+		Short: "Restore CockroachDB database from Azure Blob Storage",
+		Long:  `Restore CockroachDB database from Azure Blob Storage`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleCockroachDBRestore(cmd, args, "azure")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddCockroachDBRestoreFlags(cmd)
+	shared.AddAzureRestoreFlags(cmd)
+
+	return cmd
+}
+
+func createLocalRestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "local",
+		Short: "Restore CockroachDB database from local storage",
+		Long:  `Restore CockroachDB database from local filesystem`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleCockroachDBRestore(cmd, args, "local")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddCockroachDBRestoreFlags(cmd)
+	shared.AddLocalRestoreFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,94 @@
+package elasticsearch
+
+import (
+	"github.com/dbbackup-io/cli/cmd/shared"
+	"github.com/spf13/cobra"
+)
+
+var ElasticsearchCmd = &cobra.Command{
+	Use:     "elasticsearch",
+	Aliases: []string{"opensearch"},
+	Short:   "Restore Elasticsearch indices",
+	Long:    `Restore Elasticsearch or OpenSearch indices from cloud storage`,
+}
+
+func init() {
+	// Create storage destination restore commands
+	s3RestoreCmd := createS3RestoreCommand()
+	gcsRestoreCmd := createGCSRestoreCommand()
+	azureRestoreCmd := createAzureRestoreCommand()
+	localRestoreCmd := createLocalRestoreCommand()
+
+	ElasticsearchCmd.AddCommand(s3RestoreCmd)
+	ElasticsearchCmd.AddCommand(gcsRestoreCmd)
+	ElasticsearchCmd.AddCommand(azureRestoreCmd)
+	ElasticsearchCmd.AddCommand(localRestoreCmd)
+}
+
+func createS3RestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "s3",
+		Short: "Restore Elasticsearch indices from S3",
+		Long:  `Restore Elasticsearch indices from AWS S3`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleElasticsearchRestore(cmd, args, "s3")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddElasticsearchRestoreFlags(cmd)
+	shared.AddS3RestoreFlags(cmd)
+
+	return cmd
+}
+
+func createGCSRestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gcs",
+		Short: "Restore Elasticsearch indices from Google Cloud Storage",
+		Long:  `Restore Elasticsearch indices from Google Cloud Storage`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleElasticsearchRestore(cmd, args, "gcs")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddElasticsearchRestoreFlags(cmd)
+	shared.AddGCSRestoreFlags(cmd)
+
+	return cmd
+}
+
+func createAzureRestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "azure",
+		Short: "Restore Elasticsearch indices from Azure Blob Storage",
+		Long:  `Restore Elasticsearch indices from Azure Blob Storage`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleElasticsearchRestore(cmd, args, "azure")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddElasticsearchRestoreFlags(cmd)
+	shared.AddAzureRestoreFlags(cmd)
+
+	return cmd
+}
+
+func createLocalRestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "local",
+		Short: "Restore Elasticsearch indices from local storage",
+		Long:  `Restore Elasticsearch indices from local filesystem`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleElasticsearchRestore(cmd, args, "local")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddElasticsearchRestoreFlags(cmd)
+	shared.AddLocalRestoreFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,93 @@
+package mariadb
+
+import (
+	"github.com/dbbackup-io/cli/cmd/shared"
+	"github.com/spf13/cobra"
+)
+
+var MariaDBCmd = &cobra.Command{
+	Use:   "mariadb",
+	Short: "Restore MariaDB database",
+	Long:  `Restore MariaDB database from cloud storage`,
+}
+
+func init() {
+	// Create storage destination restore commands
+	s3RestoreCmd := createS3RestoreCommand()
+	gcsRestoreCmd := createGCSRestoreCommand()
+	azureRestoreCmd := createAzureRestoreCommand()
+	localRestoreCmd := createLocalRestoreCommand()
+
+	MariaDBCmd.AddCommand(s3RestoreCmd)
+	MariaDBCmd.AddCommand(gcsRestoreCmd)
+	MariaDBCmd.AddCommand(azureRestoreCmd)
+	MariaDBCmd.AddCommand(localRestoreCmd)
+}
+
+func createS3RestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "s3",
+		Short: "Restore MariaDB database from S3",
+		Long:  `Restore MariaDB database from AWS S3`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleMariaDBRestore(cmd, args, "s3")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddMariaDBRestoreFlags(cmd)
+	shared.AddS3RestoreFlags(cmd)
+
+	return cmd
+}
+
+func createGCSRestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gcs",
+		Short: "Restore MariaDB database from Google Cloud Storage",
+		Long:  `Restore MariaDB database from Google Cloud Storage`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleMariaDBRestore(cmd, args, "gcs")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddMariaDBRestoreFlags(cmd)
+	shared.AddGCSRestoreFlags(cmd)
+
+	return cmd
+}
+
+func createAzureRestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "azure",
+		Short: "Restore MariaDB database from Azure Blob Storage",
+		Long:  `Restore MariaDB database from Azure Blob Storage`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleMariaDBRestore(cmd, args, "azure")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddMariaDBRestoreFlags(cmd)
+	shared.AddAzureRestoreFlags(cmd)
+
+	return cmd
+}
+
+func createLocalRestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "local",
+		Short: "Restore MariaDB database from local storage",
+		Long:  `Restore MariaDB database from local filesystem`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleMariaDBRestore(cmd, args, "local")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddMariaDBRestoreFlags(cmd)
+	shared.AddLocalRestoreFlags(cmd)
+
+	return cmd
+}
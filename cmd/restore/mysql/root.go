@@ -81,7 +81,7 @@ func createLocalRestoreCommand() *cobra.Command {
 		Short: "Restore MySQL database from local storage",
 		Long:  `Restore MySQL database from local filesystem`,
 		Run: func(cmd *cobra.Command, args []string) {
-			shared.HandleLocalRestore(cmd, args, "MySQL")
+			shared.HandleMySQLRestore(cmd, args, "local")
 		},
 	}
 
@@ -81,7 +81,7 @@ func createLocalRestoreCommand() *cobra.Command {
 		Short: "Restore PostgreSQL database from local storage",
 		Long:  `Restore PostgreSQL database from local filesystem`,
 		Run: func(cmd *cobra.Command, args []string) {
-			shared.HandleLocalRestore(cmd, args, "PostgreSQL")
+			shared.HandlePostgresRestore(cmd, args, "local")
 		},
 	}
 
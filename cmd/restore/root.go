@@ -1,10 +1,15 @@
 package restore
 
 import (
+	"github.com/dbbackup-io/cli/cmd/restore/cockroachdb"
+	"github.com/dbbackup-io/cli/cmd/restore/elasticsearch"
+	"github.com/dbbackup-io/cli/cmd/restore/mariadb"
 	"github.com/dbbackup-io/cli/cmd/restore/mongodb"
 	"github.com/dbbackup-io/cli/cmd/restore/mysql"
 	"github.com/dbbackup-io/cli/cmd/restore/postgres"
 	"github.com/dbbackup-io/cli/cmd/restore/redis"
+	"github.com/dbbackup-io/cli/cmd/restore/sqlite"
+	"github.com/dbbackup-io/cli/cmd/restore/sqlserver"
 	"github.com/spf13/cobra"
 )
 
@@ -20,4 +25,9 @@ func init() {
 	RestoreCmd.AddCommand(mysql.MySQLCmd)
 	RestoreCmd.AddCommand(mongodb.MongoDBCmd)
 	RestoreCmd.AddCommand(redis.RedisCmd)
+	RestoreCmd.AddCommand(sqlite.SQLiteCmd)
+	RestoreCmd.AddCommand(mariadb.MariaDBCmd)
+	RestoreCmd.AddCommand(cockroachdb.CockroachDBCmd)
+	RestoreCmd.AddCommand(sqlserver.SQLServerCmd)
+	RestoreCmd.AddCommand(elasticsearch.ElasticsearchCmd)
 }
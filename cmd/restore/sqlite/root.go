@@ -0,0 +1,93 @@
+package sqlite
+
+import (
+	"github.com/dbbackup-io/cli/cmd/shared"
+	"github.com/spf13/cobra"
+)
+
+var SQLiteCmd = &cobra.Command{
+	Use:   "sqlite",
+	Short: "Restore SQLite database",
+	Long:  `Restore SQLite database from cloud storage`,
+}
+
+func init() {
+	// Create storage destination restore commands
+	s3RestoreCmd := createS3RestoreCommand()
+	gcsRestoreCmd := createGCSRestoreCommand()
+	azureRestoreCmd := createAzureRestoreCommand()
+	localRestoreCmd := createLocalRestoreCommand()
+
+	SQLiteCmd.AddCommand(s3RestoreCmd)
+	SQLiteCmd.AddCommand(gcsRestoreCmd)
+	SQLiteCmd.AddCommand(azureRestoreCmd)
+	SQLiteCmd.AddCommand(localRestoreCmd)
+}
+
+func createS3RestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "s3",
+		Short: "Restore SQLite database from S3",
+		Long:  `Restore SQLite database from AWS S3`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleSQLiteRestore(cmd, args, "s3")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddSQLiteRestoreFlags(cmd)
+	shared.AddS3RestoreFlags(cmd)
+
+	return cmd
+}
+
+func createGCSRestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gcs",
+		Short: "Restore SQLite database from Google Cloud Storage",
+		Long:  `Restore SQLite database from Google Cloud Storage`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleSQLiteRestore(cmd, args, "gcs")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddSQLiteRestoreFlags(cmd)
+	shared.AddGCSRestoreFlags(cmd)
+
+	return cmd
+}
+
+func createAzureRestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "azure",
+		Short: "Restore SQLite database from Azure Blob Storage",
+		Long:  `Restore SQLite database from Azure Blob Storage`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleSQLiteRestore(cmd, args, "azure")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddSQLiteRestoreFlags(cmd)
+	shared.AddAzureRestoreFlags(cmd)
+
+	return cmd
+}
+
+func createLocalRestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "local",
+		Short: "Restore SQLite database from local storage",
+		Long:  `Restore SQLite database from local filesystem`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleSQLiteRestore(cmd, args, "local")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddSQLiteRestoreFlags(cmd)
+	shared.AddLocalRestoreFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,93 @@
+package sqlserver
+
+import (
+	"github.com/dbbackup-io/cli/cmd/shared"
+	"github.com/spf13/cobra"
+)
+
+var SQLServerCmd = &cobra.Command{
+	Use:   "sqlserver",
+	Short: "Restore SQL Server database",
+	Long:  `Restore SQL Server database from cloud storage`,
+}
+
+func init() {
+	// Create storage destination restore commands
+	s3RestoreCmd := createS3RestoreCommand()
+	gcsRestoreCmd := createGCSRestoreCommand()
+	azureRestoreCmd := createAzureRestoreCommand()
+	localRestoreCmd := createLocalRestoreCommand()
+
+	SQLServerCmd.AddCommand(s3RestoreCmd)
+	SQLServerCmd.AddCommand(gcsRestoreCmd)
+	SQLServerCmd.AddCommand(azureRestoreCmd)
+	SQLServerCmd.AddCommand(localRestoreCmd)
+}
+
+func createS3RestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "s3",
+		Short: "Restore SQL Server database from S3",
+		Long:  `Restore SQL Server database from AWS S3`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleSQLServerRestore(cmd, args, "s3")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddSQLServerRestoreFlags(cmd)
+	shared.AddS3RestoreFlags(cmd)
+
+	return cmd
+}
+
+func createGCSRestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gcs",
+		Short: "Restore SQL Server database from Google Cloud Storage",
+		Long:  `Restore SQL Server database from Google Cloud Storage`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleSQLServerRestore(cmd, args, "gcs")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddSQLServerRestoreFlags(cmd)
+	shared.AddGCSRestoreFlags(cmd)
+
+	return cmd
+}
+
+func createAzureRestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "azure",
+		Short: "Restore SQL Server database from Azure Blob Storage",
+		Long:  `Restore SQL Server database from Azure Blob Storage`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleSQLServerRestore(cmd, args, "azure")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddSQLServerRestoreFlags(cmd)
+	shared.AddAzureRestoreFlags(cmd)
+
+	return cmd
+}
+
+func createLocalRestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "local",
+		Short: "Restore SQL Server database from local storage",
+		Long:  `Restore SQL Server database from local filesystem`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleSQLServerRestore(cmd, args, "local")
+		},
+	}
+
+	// Add restore-specific flags
+	shared.AddSQLServerRestoreFlags(cmd)
+	shared.AddLocalRestoreFlags(cmd)
+
+	return cmd
+}
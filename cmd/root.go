@@ -3,23 +3,32 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/dbbackup-io/cli/cmd/backups"
+	"github.com/dbbackup-io/cli/cmd/binlog"
+	"github.com/dbbackup-io/cli/cmd/check"
 	"github.com/dbbackup-io/cli/cmd/database_source"
 	"github.com/dbbackup-io/cli/cmd/dump"
+	"github.com/dbbackup-io/cli/cmd/inspect"
 	"github.com/dbbackup-io/cli/cmd/job"
 	"github.com/dbbackup-io/cli/cmd/login"
 	"github.com/dbbackup-io/cli/cmd/logout"
 	"github.com/dbbackup-io/cli/cmd/restore"
 	"github.com/dbbackup-io/cli/cmd/server"
+	"github.com/dbbackup-io/cli/cmd/shared"
 	"github.com/dbbackup-io/cli/cmd/status"
 	"github.com/dbbackup-io/cli/cmd/storage_destination"
+	"github.com/dbbackup-io/cli/cmd/wal_fetch"
 	"github.com/dbbackup-io/cli/pkg/logger"
 	"github.com/spf13/cobra"
 )
 
 var (
-	logLevel string
-	version  = "dev"
+	logLevel  string
+	logFormat string
+	logFile   string
+	version   = "dev"
 )
 
 var rootCmd = &cobra.Command{
@@ -29,9 +38,15 @@ var rootCmd = &cobra.Command{
 	Long: `A CLI tool to backup and restore databases to/from cloud storage.
 Supports PostgreSQL, MySQL, MongoDB, Redis with S3, GCS, and Azure storage.`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		// Set log level before any command runs
-		if err := logger.SetLevel(logLevel); err != nil {
-			fmt.Printf("Invalid log level: %v\n", err)
+		// Set up logging before any command runs
+		if err := logger.Setup(logger.Options{Level: logLevel, Format: logFormat, File: logFile}); err != nil {
+			fmt.Printf("Invalid logging options: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Resolve secret references (env:, file:, cmd:, vault:, awssm:) in credential flags
+		if err := shared.ResolveSecretFlags(cmd); err != nil {
+			fmt.Printf("Failed to resolve secrets: %v\n", err)
 			os.Exit(1)
 		}
 	},
@@ -71,15 +86,27 @@ func SetVersion(v string) {
 }
 
 func Execute() {
+	defer logger.Close()
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
+		logger.Close()
 		os.Exit(1)
 	}
 }
 
+// defaultLogLevel returns $LOG_LEVEL, which the dumpers used to read directly, or info
+func defaultLogLevel() string {
+	if level := os.Getenv("LOG_LEVEL"); level != "" {
+		return strings.ToLower(level)
+	}
+	return "info"
+}
+
 func init() {
 	// Add global flags
-	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Set the logging level (error, warn, info, debug)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", defaultLogLevel(), "Set the logging level (error, warn, info, debug); defaults to $LOG_LEVEL")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logger.FormatText, "Log format (text, json)")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Also append logs to this file")
 
 	// Configure logs command
 	logsCmd.Flags().Bool("table", false, "Display backup runs in table format instead of interactive selection")
@@ -94,5 +121,13 @@ func init() {
 	rootCmd.AddCommand(server.ServerCmd)
 	rootCmd.AddCommand(database_source.DatabaseSourceCmd)
 	rootCmd.AddCommand(storage_destination.StorageDestinationCmd)
+	rootCmd.AddCommand(binlog.BinlogCmd)
+	rootCmd.AddCommand(wal_fetch.WalFetchCmd)
+	rootCmd.AddCommand(inspect.InspectCmd)
+	rootCmd.AddCommand(backups.BackupsCmd)
+
+	// The dump packages register their sources for check during their own init
+	check.AddSourceCommands()
+	rootCmd.AddCommand(check.CheckCmd)
 	rootCmd.AddCommand(logsCmd)
 }
@@ -0,0 +1,39 @@
+package shared
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+	"github.com/dbbackup-io/cli/pkg/sources/mysql"
+)
+
+// HandleBinlogStream archives MySQL binlogs to storage until interrupted
+func HandleBinlogStream(binlogFlags BinlogFlags, uploader backup.StorageUploader, pathPrefix string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	streamer := &mysql.BinlogStreamer{
+		Host:         binlogFlags.Host,
+		Port:         binlogFlags.Port,
+		Username:     binlogFlags.Username,
+		Password:     binlogFlags.Password,
+		TLS:          binlogFlags.TLS,
+		StartFile:    binlogFlags.StartFile,
+		ServerID:     binlogFlags.ServerID,
+		PollInterval: binlogFlags.PollInterval,
+		Uploader:     uploader,
+		PathPrefix:   pathPrefix,
+	}
+
+	logger.Infof("🔄 Starting MySQL binlog archiving to %s...", uploader.GetStorageType())
+
+	if err := streamer.Run(ctx); err != nil {
+		logger.Fatalf("❌ Binlog archiving failed: %v", err)
+	}
+
+	logger.Infof("✅ Binlog archiving stopped")
+}
@@ -0,0 +1,142 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// CheckSource is a database source that `dbbackup check` can verify
+type CheckSource struct {
+	Name    string // subcommand name, e.g. postgres
+	DBType  string // database type as passed to the command factories, e.g. PostgreSQL
+	Factory DatabaseDumperFactory
+}
+
+var checkSources []CheckSource
+
+// RegisterCheckSource makes a database source available to `dbbackup check`, with the same
+// flags as its dump commands
+func RegisterCheckSource(name, dbType string, dumperFactory DatabaseDumperFactory) {
+	checkSources = append(checkSources, CheckSource{Name: name, DBType: dbType, Factory: dumperFactory})
+}
+
+// CheckSources returns the registered database sources in registration order
+func CheckSources() []CheckSource {
+	return checkSources
+}
+
+// CreateCheckCommand creates a check command for a database and storage type. It takes the
+// same flags as the matching dump command.
+func CreateCheckCommand(dbType, storageType string, dumperFactory DatabaseDumperFactory) *cobra.Command {
+	var (
+		dbFlags    DatabaseFlags
+		s3Flags    S3Flags
+		gcsFlags   GCSFlags
+		azureFlags AzureFlags
+		localFlags LocalFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   storageType,
+		Short: "Check " + dbType + " backups to " + storageNames[storageType],
+		Long: "Verify that " + dbType + " backups to " + storageNames[storageType] + ` can run: the client tool is
+installed, the credentials work, the user holds the required privileges, and the storage
+accepts a small probe object, which is read back and deleted. Exits non-zero on problems.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := ApplyDatabaseURL(cmd, &dbFlags, dbType); err != nil {
+				logger.Fatalf("❌ %v", err)
+			}
+
+			var uploader backup.StorageUploader
+			var prefix string
+			switch storageType {
+			case "s3":
+				uploader, prefix = NewS3Uploader(s3Flags), s3Flags.Path
+			case "gcs":
+				uploader, prefix = NewGCSUploader(gcsFlags), gcsFlags.Path
+			case "azure":
+				uploader, prefix = NewAzureUploader(azureFlags), azureFlags.Path
+			case "local":
+				uploader = NewLocalUploader(localFlags)
+			}
+
+			HandleCheck(dbType, dumperFactory(dbFlags), uploader, prefix)
+		},
+	}
+
+	AddDatabaseFlags(cmd, dbType, &dbFlags)
+
+	switch storageType {
+	case "s3":
+		AddS3Flags(cmd, &s3Flags)
+	case "gcs":
+		AddGCSFlags(cmd, &gcsFlags)
+	case "azure":
+		AddAzureFlags(cmd, &azureFlags)
+	case "local":
+		AddLocalFlags(cmd, &localFlags)
+	}
+
+	return cmd
+}
+
+// storageNames are the display names of the storage types
+var storageNames = map[string]string{
+	"s3":    "S3",
+	"gcs":   "Google Cloud Storage",
+	"azure": "Azure Blob Storage",
+	"local": "local storage",
+	"sftp":  "SFTP",
+}
+
+// HandleCheck runs the database and storage checks, prints a checklist and exits non-zero
+// when any check failed
+func HandleCheck(dbType string, dumper backup.DatabaseDumper, uploader backup.StorageUploader, prefix string) {
+	ctx := context.Background()
+	logRunContext(dumper.GetDatabaseType(), uploader.GetStorageType())
+
+	logger.Infof("🔍 Checking %s backup to %s...", dbType, storageNames[uploader.GetStorageType()])
+
+	var databaseChecks []backup.Check
+	if prober, ok := dumper.(backup.Prober); ok {
+		databaseChecks = prober.Probe(ctx)
+	} else {
+		databaseChecks = []backup.Check{{Name: "Database", Err: fmt.Errorf("no pre-flight checks for %s", dbType), Warning: true}}
+	}
+	storageChecks := backup.CheckStorage(ctx, uploader, prefix)
+
+	failed := printChecks(dbType, databaseChecks) + printChecks("Storage ("+uploader.GetStorageType()+")", storageChecks)
+	total := len(databaseChecks) + len(storageChecks)
+
+	fmt.Println()
+	if failed > 0 {
+		logger.Fatalf("❌ %d of %d checks failed", failed, total)
+	}
+	logger.Infof("✅ All %d checks passed", total)
+}
+
+// printChecks prints a section of the checklist and returns the number of failed checks
+func printChecks(title string, checks []backup.Check) int {
+	failed := 0
+
+	fmt.Printf("\n%s\n", title)
+	for _, check := range checks {
+		switch {
+		case check.Failed():
+			failed++
+			fmt.Printf("  ❌ %s: %v\n", check.Name, check.Err)
+		case check.Err != nil:
+			fmt.Printf("  ⚠️  %s: %v\n", check.Name, check.Err)
+		case check.Detail != "":
+			fmt.Printf("  ✅ %s: %s\n", check.Name, check.Detail)
+		default:
+			fmt.Printf("  ✅ %s\n", check.Name)
+		}
+	}
+
+	return failed
+}
@@ -2,12 +2,46 @@ package shared
 
 import (
 	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
 	"github.com/spf13/cobra"
 )
 
 // DatabaseDumperFactory creates database dumpers from flags
 type DatabaseDumperFactory func(flags DatabaseFlags) backup.DatabaseDumper
 
+// AddDumpCommands makes a database command dump to the storage URLs given with --to, and
+// adds the per-storage subcommands, which keep working with their own flags
+func AddDumpCommands(cmd *cobra.Command, dbType string, dumperFactory DatabaseDumperFactory) {
+	var (
+		dbFlags     DatabaseFlags
+		toURLs      []string
+		commonFlags CommonFlags
+	)
+
+	cmd.Long += "\n\nGive storage with --to as a URL, repeated to store one dump in several places:\n" + StorageURLHelp
+	cmd.Example = "  dbbackup dump " + cmd.Use + " --db-url ... --to s3://my-backups/" + cmd.Use + "?region=eu-west-1\n" +
+		"  dbbackup dump " + cmd.Use + " --db-url ... --to /var/backups --to sftp://backup@vault.internal/srv/backups"
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		if err := ApplyDatabaseURL(cmd, &dbFlags, dbType); err != nil {
+			logger.Fatalf("❌ %v", err)
+		}
+		dumper := dumperFactory(dbFlags)
+		HandleURLExport(dumper, toURLs, commonFlags)
+	}
+
+	AddDatabaseFlags(cmd, dbType, &dbFlags)
+
+	cmd.Flags().StringArrayVar(&toURLs, "to", nil, "Storage URL to write the backup to, e.g. s3://bucket/prefix; repeat for several destinations")
+	cmd.MarkFlagRequired("to")
+	AddCommonFlags(cmd, &commonFlags)
+
+	cmd.AddCommand(CreateS3Command(dbType, dumperFactory))
+	cmd.AddCommand(CreateGCSCommand(dbType, dumperFactory))
+	cmd.AddCommand(CreateAzureCommand(dbType, dumperFactory))
+	cmd.AddCommand(CreateLocalCommand(dbType, dumperFactory))
+}
+
 // CreateS3Command creates a generic S3 command for any database
 func CreateS3Command(dbType string, dumperFactory DatabaseDumperFactory) *cobra.Command {
 	var (
@@ -21,22 +55,15 @@ func CreateS3Command(dbType string, dumperFactory DatabaseDumperFactory) *cobra.
 		Short: "Export " + dbType + " database to S3",
 		Long:  "Export " + dbType + " database backup to AWS S3",
 		Run: func(cmd *cobra.Command, args []string) {
+			if err := ApplyDatabaseURL(cmd, &dbFlags, dbType); err != nil {
+				logger.Fatalf("❌ %v", err)
+			}
 			dumper := dumperFactory(dbFlags)
 			HandleS3Export(cmd, args, dumper, s3Flags, commonFlags)
 		},
 	}
 
-	// Add appropriate flags based on database type
-	switch dbType {
-	case "PostgreSQL":
-		AddPostgreSQLFlags(cmd, &dbFlags)
-	case "MySQL":
-		AddMySQLFlags(cmd, &dbFlags)
-	case "MongoDB":
-		AddMongoDBFlags(cmd, &dbFlags)
-	case "Redis":
-		AddRedisFlags(cmd, &dbFlags)
-	}
+	AddDatabaseFlags(cmd, dbType, &dbFlags)
 
 	AddS3Flags(cmd, &s3Flags)
 	AddCommonFlags(cmd, &commonFlags)
@@ -57,22 +84,15 @@ func CreateGCSCommand(dbType string, dumperFactory DatabaseDumperFactory) *cobra
 		Short: "Export " + dbType + " database to Google Cloud Storage",
 		Long:  "Export " + dbType + " database backup to Google Cloud Storage",
 		Run: func(cmd *cobra.Command, args []string) {
+			if err := ApplyDatabaseURL(cmd, &dbFlags, dbType); err != nil {
+				logger.Fatalf("❌ %v", err)
+			}
 			dumper := dumperFactory(dbFlags)
 			HandleGCSExport(cmd, args, dumper, gcsFlags, commonFlags)
 		},
 	}
 
-	// Add appropriate flags based on database type
-	switch dbType {
-	case "PostgreSQL":
-		AddPostgreSQLFlags(cmd, &dbFlags)
-	case "MySQL":
-		AddMySQLFlags(cmd, &dbFlags)
-	case "MongoDB":
-		AddMongoDBFlags(cmd, &dbFlags)
-	case "Redis":
-		AddRedisFlags(cmd, &dbFlags)
-	}
+	AddDatabaseFlags(cmd, dbType, &dbFlags)
 
 	AddGCSFlags(cmd, &gcsFlags)
 	AddCommonFlags(cmd, &commonFlags)
@@ -93,22 +113,15 @@ func CreateAzureCommand(dbType string, dumperFactory DatabaseDumperFactory) *cob
 		Short: "Export " + dbType + " database to Azure Blob Storage",
 		Long:  "Export " + dbType + " database backup to Azure Blob Storage",
 		Run: func(cmd *cobra.Command, args []string) {
+			if err := ApplyDatabaseURL(cmd, &dbFlags, dbType); err != nil {
+				logger.Fatalf("❌ %v", err)
+			}
 			dumper := dumperFactory(dbFlags)
 			HandleAzureExport(cmd, args, dumper, azureFlags, commonFlags)
 		},
 	}
 
-	// Add appropriate flags based on database type
-	switch dbType {
-	case "PostgreSQL":
-		AddPostgreSQLFlags(cmd, &dbFlags)
-	case "MySQL":
-		AddMySQLFlags(cmd, &dbFlags)
-	case "MongoDB":
-		AddMongoDBFlags(cmd, &dbFlags)
-	case "Redis":
-		AddRedisFlags(cmd, &dbFlags)
-	}
+	AddDatabaseFlags(cmd, dbType, &dbFlags)
 
 	AddAzureFlags(cmd, &azureFlags)
 	AddCommonFlags(cmd, &commonFlags)
@@ -129,25 +142,42 @@ func CreateLocalCommand(dbType string, dumperFactory DatabaseDumperFactory) *cob
 		Short: "Export " + dbType + " database to local storage",
 		Long:  "Export " + dbType + " database backup to local filesystem",
 		Run: func(cmd *cobra.Command, args []string) {
+			if err := ApplyDatabaseURL(cmd, &dbFlags, dbType); err != nil {
+				logger.Fatalf("❌ %v", err)
+			}
 			dumper := dumperFactory(dbFlags)
 			HandleLocalExport(cmd, args, dumper, localFlags, commonFlags)
 		},
 	}
 
-	// Add appropriate flags based on database type
+	AddDatabaseFlags(cmd, dbType, &dbFlags)
+
+	AddLocalFlags(cmd, &localFlags)
+	AddCommonFlags(cmd, &commonFlags)
+
+	return cmd
+}
+
+// AddDatabaseFlags adds the connection and dump flags of a database type to a command
+func AddDatabaseFlags(cmd *cobra.Command, dbType string, flags *DatabaseFlags) {
 	switch dbType {
 	case "PostgreSQL":
-		AddPostgreSQLFlags(cmd, &dbFlags)
+		AddPostgreSQLFlags(cmd, flags)
 	case "MySQL":
-		AddMySQLFlags(cmd, &dbFlags)
+		AddMySQLFlags(cmd, flags)
 	case "MongoDB":
-		AddMongoDBFlags(cmd, &dbFlags)
+		AddMongoDBFlags(cmd, flags)
 	case "Redis":
-		AddRedisFlags(cmd, &dbFlags)
+		AddRedisFlags(cmd, flags)
+	case "SQLite":
+		AddSQLiteFlags(cmd, flags)
+	case "MariaDB":
+		AddMariaDBFlags(cmd, flags)
+	case "CockroachDB":
+		AddCockroachDBFlags(cmd, flags)
+	case "SQL Server":
+		AddSQLServerFlags(cmd, flags)
+	case "Elasticsearch":
+		AddElasticsearchFlags(cmd, flags)
 	}
-
-	AddLocalFlags(cmd, &localFlags)
-	AddCommonFlags(cmd, &commonFlags)
-
-	return cmd
 }
@@ -0,0 +1,57 @@
+package shared
+
+import (
+	"context"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// CopyFlags holds the flags of backups copy
+type CopyFlags struct {
+	From        string
+	To          string
+	OnlyMissing bool
+	Parallel    int
+	DryRun      bool
+}
+
+// HandleBackupsCopy copies backups between two storage URLs
+func HandleBackupsCopy(flags CopyFlags) {
+	source, fromPrefix, err := OpenStorageURL(flags.From)
+	if err != nil {
+		logger.Fatalf("❌ --from: %v", err)
+	}
+	from, ok := source.(backup.StorageDownloader)
+	if !ok {
+		logger.Fatalf("❌ --from: %s storage cannot read backups back", source.GetStorageType())
+	}
+	to, toPrefix, err := OpenStorageURL(flags.To)
+	if err != nil {
+		logger.Fatalf("❌ --to: %v", err)
+	}
+	logger.With("run_id", logger.NewRunID(), "from", from.GetStorageType(), "to", to.GetStorageType())
+
+	logger.Infof("🔄 Copying backups from %s to %s...", flags.From, flags.To)
+
+	result, err := backup.CopyBackups(context.Background(), from, to, backup.CopyOptions{
+		SourcePrefix:      fromPrefix,
+		DestinationPrefix: toPrefix,
+		OnlyMissing:       flags.OnlyMissing,
+		Parallelism:       flags.Parallel,
+		DryRun:            flags.DryRun,
+	})
+
+	if flags.DryRun {
+		if err != nil {
+			logger.Fatalf("❌ %v", err)
+		}
+		logger.Infof("🧪 Dry run: %d objects would be copied, %d already at the destination", result.Copied, result.Skipped)
+		return
+	}
+	logger.Infof("   Copied: %d (%s), skipped: %d, failed: %d", result.Copied, backup.FormatBytes(result.Bytes), result.Skipped, result.Failed)
+	if err != nil {
+		logger.Fatalf("❌ Copy failed: %v", err)
+	}
+	logger.Info("✅ Copy completed successfully")
+}
@@ -0,0 +1,158 @@
+package shared
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dbbackup-io/cli/pkg/sources/dburl"
+	"github.com/spf13/cobra"
+)
+
+// databaseEngines maps command database types to connection URL engines
+var databaseEngines = map[string]string{
+	"PostgreSQL":    "postgres",
+	"MySQL":         "mysql",
+	"MongoDB":       "mongodb",
+	"Redis":         "redis",
+	"MariaDB":       "mysql",
+	"CockroachDB":   "postgres",
+	"SQL Server":    "sqlserver",
+	"Elasticsearch": "elasticsearch",
+}
+
+// tlsOptionFlags maps connection URL query options to the TLS flag they fill in
+var tlsOptionFlags = map[string]string{
+	"sslmode":     "db-sslmode",
+	"ssl-mode":    "db-sslmode",
+	"sslrootcert": "db-ssl-ca",
+	"ssl-ca":      "db-ssl-ca",
+	"sslcert":     "db-ssl-cert",
+	"ssl-cert":    "db-ssl-cert",
+	"sslkey":      "db-ssl-key",
+	"ssl-key":     "db-ssl-key",
+}
+
+// ApplyDatabaseURL fills database flags from --db-url. Flags given explicitly on the
+// command line take precedence over values from the URL.
+func ApplyDatabaseURL(cmd *cobra.Command, flags *DatabaseFlags, dbType string) error {
+	if flags.URL == "" {
+		return nil
+	}
+
+	u, err := dburl.Parse(flags.URL)
+	if err != nil {
+		return err
+	}
+
+	engine := databaseEngines[dbType]
+	if u.Engine != engine {
+		return fmt.Errorf("--db-url is a %s URL, expected %s", u.Engine, engine)
+	}
+
+	changed := cmd.Flags().Changed
+
+	if !changed("db-user") && u.Username != "" {
+		flags.Username = u.Username
+	}
+	if !changed("db-password") && u.Password != "" {
+		flags.Password = u.Password
+	}
+	if !changed("db-name") && u.Database != "" {
+		flags.Database = u.Database
+	}
+
+	// MongoDB tools understand the full URI, including replica sets, SRV records,
+	// authSource and TLS options, so it is passed through with credentials re-escaped
+	if engine == "mongodb" {
+		u.Username, u.Password = flags.Username, flags.Password
+		flags.ConnectionURI = u.String()
+		return nil
+	}
+
+	if len(u.Hosts) > 1 {
+		return fmt.Errorf("--db-url with multiple hosts is only supported for MongoDB")
+	}
+	if !changed("db-host") && u.Host != "" {
+		flags.Host = u.Host
+	}
+	if !changed("db-port") && u.Port != 0 {
+		flags.Port = u.Port
+	}
+
+	// rediss:// and https:// imply TLS
+	if u.TLS && !changed("db-sslmode") && flags.TLS.Mode == "" {
+		flags.TLS.Mode = "verify-full"
+	}
+
+	remaining := map[string]string{}
+	for key, value := range u.Options {
+		flagName, isTLS := tlsOptionFlags[strings.ToLower(key)]
+		if !isTLS {
+			remaining[key] = value
+			continue
+		}
+		if changed(flagName) {
+			continue
+		}
+		switch flagName {
+		case "db-sslmode":
+			flags.TLS.Mode = value
+		case "db-ssl-ca":
+			flags.TLS.CAFile = value
+		case "db-ssl-cert":
+			flags.TLS.CertFile = value
+		case "db-ssl-key":
+			flags.TLS.KeyFile = value
+		}
+	}
+
+	switch engine {
+	case "postgres":
+		// Everything else is passed through as libpq connection parameters
+		if dbType == "PostgreSQL" {
+			flags.Options = remaining
+			return nil
+		}
+	case "redis":
+		// The path of a redis:// URL is the database index
+		if flags.Database != "" && !changed("db-index") {
+			index, err := strconv.Atoi(flags.Database)
+			if err != nil {
+				return fmt.Errorf("invalid Redis database index %q in --db-url", flags.Database)
+			}
+			flags.Redis.DBIndex = index
+		}
+	case "mysql":
+		if charset, ok := remaining["charset"]; ok && dbType == "MySQL" {
+			if !changed("default-character-set") {
+				flags.MySQL.DefaultCharacterSet = charset
+			}
+			delete(remaining, "charset")
+		}
+	case "elasticsearch":
+		if flags.Database != "" {
+			return fmt.Errorf("--db-url must not have a path for Elasticsearch; use --index to select indices")
+		}
+	case "sqlserver":
+		// sqlserver:// URLs name the database in a query option rather than the path
+		if database, ok := remaining["database"]; ok {
+			if !changed("db-name") {
+				flags.Database = database
+			}
+			delete(remaining, "database")
+		}
+	}
+
+	if len(remaining) > 0 {
+		keys := make([]string, 0, len(remaining))
+		for key := range remaining {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return fmt.Errorf("unsupported %s URL option(s): %s", engine, strings.Join(keys, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package shared
+
+import (
+	"fmt"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// HandleDryRun prints what a backup would do without connecting to the database or storage:
+// the object key, the dump command with secrets redacted, the destinations and the pipeline
+func HandleDryRun(executor *backup.BackupExecutor) {
+	dumper, config := executor.Dumper, executor.Config
+	destinations := executor.AllDestinations()
+
+	name, err := executor.ObjectName()
+	if err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	logger.Infof("🧪 Dry run of %s backup to %s: nothing is executed", dumper.GetDatabaseType(), destinationNames(destinations))
+
+	fmt.Println("\nDump")
+	if describer, ok := dumper.(backup.CommandDescriber); ok {
+		lines, err := describer.DescribeCommand()
+		if err != nil {
+			logger.Fatalf("❌ %v", err)
+		}
+		for _, line := range lines {
+			fmt.Printf("  %s\n", line)
+		}
+	} else {
+		fmt.Printf("  ⚠️  cannot describe the %s dump command\n", dumper.GetDatabaseType())
+	}
+
+	for _, destination := range destinations {
+		key := destination.Key(name)
+		location := key
+		if locator, ok := destination.Uploader.(backup.StorageLocator); ok {
+			location = locator.URL(key)
+		}
+		fmt.Println("\nDestination")
+		fmt.Printf("  Key:      %s\n", key)
+		fmt.Printf("  URL:      %s\n", location)
+		fmt.Printf("  Manifest: %s\n", backup.ManifestKey(location))
+		if describer, ok := destination.Uploader.(backup.UploadDescriber); ok {
+			for _, line := range describer.DescribeUpload() {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+	}
+
+	fmt.Println("\nPipeline")
+	fmt.Println("  1. Dump stream")
+	fmt.Println("  2. SHA-256 checksum and size")
+	if len(destinations) > 1 {
+		policy := "all must succeed"
+		if config.DestinationPolicy == backup.DestinationPolicyAny {
+			policy = "at least one must succeed"
+		}
+		fmt.Printf("  3. Upload to %s concurrently (%s)\n", destinationNames(destinations), policy)
+		fmt.Println("  4. Upload a manifest to each")
+	} else {
+		fmt.Printf("  3. Upload to %s\n", destinationNames(destinations))
+		fmt.Println("  4. Upload manifest")
+	}
+	if config.Compression == "gz" {
+		fmt.Println("  Compression: gz (key suffix only, the stream is uploaded as the dump tool writes it)")
+	} else {
+		fmt.Println("  Compression: none")
+	}
+	fmt.Println("  Encryption:  none")
+	if config.MaxUploadRate > 0 {
+		fmt.Printf("  Upload rate: at most %s/s\n", backup.FormatBytes(config.MaxUploadRate))
+	}
+	if priority := backup.CurrentProcessPriority().Describe(); priority != "" {
+		fmt.Printf("  Priority:    %s for the dump tool\n", priority)
+	}
+}
@@ -0,0 +1,289 @@
+package shared
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/logger"
+	"github.com/dbbackup-io/cli/pkg/sources/redis/rdb"
+	"github.com/spf13/cobra"
+)
+
+// AddRedisInspectFlags adds flags for inspecting a Redis backup to a command
+func AddRedisInspectFlags(cmd *cobra.Command) {
+	cmd.Flags().String("backup-file", "", "Backup file path/key to inspect (required)")
+	cmd.Flags().String("format", "stats", "Output format: stats, json (JSON lines) or resp (commands for redis-cli --pipe)")
+	cmd.Flags().String("output", "", "Write the export to this file instead of stdout")
+	cmd.Flags().String("match", "", "Only include keys matching this glob pattern (* and ?)")
+	cmd.Flags().Int("db", -1, "Only include keys from this database index")
+	cmd.Flags().StringSlice("type", nil, "Only include keys of these types (string, list, set, zset, hash, stream)")
+	cmd.Flags().String("prefix-delimiter", ":", "Delimiter used to group keys by prefix in stats")
+	cmd.Flags().Int("top", 20, "Number of largest keys and prefixes to show in stats")
+
+	cmd.MarkFlagRequired("backup-file")
+}
+
+// redisKeyFilter selects the keys to include in an inspection
+type redisKeyFilter struct {
+	pattern *regexp.Regexp
+	db      int
+	types   map[rdb.Type]bool
+}
+
+func (f *redisKeyFilter) match(entry *rdb.Entry) bool {
+	if f.db >= 0 && entry.DB != f.db {
+		return false
+	}
+	if len(f.types) > 0 && !f.types[entry.Type] {
+		return false
+	}
+	return f.pattern == nil || f.pattern.MatchString(entry.Key)
+}
+
+// HandleRedisInspect reads a Redis backup from storage and prints statistics or exports its keys
+func HandleRedisInspect(cmd *cobra.Command, storageType string) {
+	ctx := context.Background()
+
+	backupFile, _ := cmd.Flags().GetString("backup-file")
+	format, _ := cmd.Flags().GetString("format")
+	outputPath, _ := cmd.Flags().GetString("output")
+	match, _ := cmd.Flags().GetString("match")
+	db, _ := cmd.Flags().GetInt("db")
+	types, _ := cmd.Flags().GetStringSlice("type")
+	delimiter, _ := cmd.Flags().GetString("prefix-delimiter")
+	top, _ := cmd.Flags().GetInt("top")
+
+	filter := &redisKeyFilter{db: db, types: map[rdb.Type]bool{}}
+	if match != "" {
+		filter.pattern = globPattern(match)
+	}
+	for _, t := range types {
+		filter.types[rdb.Type(strings.ToLower(t))] = true
+	}
+
+	var (
+		stats    *rdb.Stats
+		exporter rdb.Exporter
+	)
+	if format == "stats" {
+		stats = rdb.NewStats(delimiter, top, time.Now())
+	} else {
+		output := io.Writer(os.Stdout)
+		if outputPath != "" {
+			file, err := os.Create(outputPath)
+			if err != nil {
+				logger.Fatalf("❌ Failed to create %s: %v", outputPath, err)
+			}
+			defer file.Close()
+			output = file
+		}
+
+		var err error
+		if exporter, err = rdb.NewExporter(format, output); err != nil {
+			logger.Fatalf("❌ %v", err)
+		}
+	}
+
+	downloader, err := NewStorageDownloader(cmd, storageType)
+	if err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	reader := openDownload(ctx, downloader, backupFile)
+	defer reader.Close()
+
+	logger.Infof("🔍 Inspecting Redis backup %s", backupFile)
+
+	skippedStreams := 0
+	visit := func(entry *rdb.Entry) error {
+		if !filter.match(entry) {
+			return nil
+		}
+		if stats != nil {
+			stats.Add(entry)
+			return nil
+		}
+		if entry.Type == rdb.TypeStream {
+			skippedStreams++
+		}
+		return exporter.Write(entry)
+	}
+
+	if err := walkRedisBackup(reader, visit); err != nil {
+		logger.Fatalf("❌ Failed to read %s: %v", backupFile, err)
+	}
+
+	if exporter != nil {
+		if err := exporter.Flush(); err != nil {
+			logger.Fatalf("❌ Failed to write export: %v", err)
+		}
+		if skippedStreams > 0 {
+			logger.Warnf("⚠️  Skipped %d stream key(s): stream export is not supported", skippedStreams)
+		}
+		return
+	}
+
+	printRedisStats(stats, top)
+}
+
+// walkRedisBackup calls visit for every key of an RDB file, optionally gzip-compressed,
+// or of every shard of a Redis Cluster backup archive
+func walkRedisBackup(reader io.Reader, visit func(*rdb.Entry) error) error {
+	buffered := bufio.NewReader(reader)
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return fmt.Errorf("failed to open gzip stream: %w", err)
+		}
+		defer gz.Close()
+		buffered = bufio.NewReader(gz)
+	}
+
+	if magic, err := buffered.Peek(5); err == nil && bytes.Equal(magic, []byte("REDIS")) {
+		return walkRDB(buffered, visit)
+	}
+
+	// Cluster backups are tar archives with one RDB per master shard
+	archive := tar.NewReader(buffered)
+	shards := 0
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, tar.ErrHeader) {
+			return fmt.Errorf("not an RDB file or cluster archive")
+		}
+		if err != nil {
+			return err
+		}
+		if !strings.HasSuffix(header.Name, ".rdb") {
+			continue
+		}
+
+		logger.Infof("📦 Reading shard %s", header.Name)
+		if err := walkRDB(archive, visit); err != nil {
+			return fmt.Errorf("%s: %w", header.Name, err)
+		}
+		shards++
+	}
+
+	if shards == 0 {
+		return fmt.Errorf("no RDB files found in archive")
+	}
+
+	return nil
+}
+
+func walkRDB(reader io.Reader, visit func(*rdb.Entry) error) error {
+	parser := rdb.NewParser(reader)
+	for {
+		entry, err := parser.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := visit(entry); err != nil {
+			return err
+		}
+	}
+}
+
+// globPattern converts a Redis-style glob with * and ? wildcards to a regular expression
+func globPattern(glob string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(glob)
+	quoted = strings.ReplaceAll(quoted, `\*`, ".*")
+	quoted = strings.ReplaceAll(quoted, `\?`, ".")
+	return regexp.MustCompile("^(?s:" + quoted + ")$")
+}
+
+func printRedisStats(stats *rdb.Stats, top int) {
+	fmt.Printf("Keys:                %d\n", stats.Total.Keys)
+	fmt.Printf("Serialized Size:     %s\n", formatSize(stats.Total.Bytes))
+	fmt.Println()
+
+	fmt.Println("By database:")
+	for index := 0; len(stats.ByDB) > 0 && index <= maxDB(stats); index++ {
+		if usage, ok := stats.ByDB[index]; ok {
+			fmt.Printf("  db%-6d %10d keys  %10s\n", index, usage.Keys, formatSize(usage.Bytes))
+		}
+	}
+	fmt.Println()
+
+	fmt.Println("By type:")
+	for _, t := range []rdb.Type{rdb.TypeString, rdb.TypeList, rdb.TypeSet, rdb.TypeZSet, rdb.TypeHash, rdb.TypeStream} {
+		if usage, ok := stats.ByType[t]; ok {
+			fmt.Printf("  %-8s %10d keys  %10s\n", t, usage.Keys, formatSize(usage.Bytes))
+		}
+	}
+	fmt.Println()
+
+	fmt.Println("Memory by prefix:")
+	for i, prefix := range stats.Prefixes() {
+		if i >= top {
+			fmt.Printf("  ... %d more\n", len(stats.ByPrefix)-top)
+			break
+		}
+		usage := stats.ByPrefix[prefix]
+		fmt.Printf("  %-30s %10d keys  %10s\n", truncate(prefix, 30), usage.Keys, formatSize(usage.Bytes))
+	}
+	fmt.Println()
+
+	fmt.Println("TTL distribution:")
+	for _, bucket := range rdb.TTLBuckets {
+		if count := stats.TTL[bucket]; count > 0 {
+			fmt.Printf("  %-10s %10d keys\n", bucket, count)
+		}
+	}
+	fmt.Println()
+
+	if len(stats.Largest) > 0 {
+		fmt.Println("Largest keys:")
+		for _, key := range stats.Largest {
+			fmt.Printf("  db%d %-6s %10s  %s\n", key.DB, key.Type, formatSize(key.Size), key.Key)
+		}
+	}
+}
+
+func maxDB(stats *rdb.Stats) int {
+	highest := 0
+	for index := range stats.ByDB {
+		if index > highest {
+			highest = index
+		}
+	}
+	return highest
+}
+
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen-3] + "..."
+}
+
+// formatSize formats byte size in human readable format
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,297 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+	"github.com/dbbackup-io/cli/pkg/sources/mysql"
+	"github.com/dbbackup-io/cli/pkg/sources/postgres"
+	"github.com/spf13/cobra"
+)
+
+// HandlePostgresPITR prepares a PostgreSQL data directory for point-in-time recovery
+func HandlePostgresPITR(cmd *cobra.Command, args []string, storageType string) {
+	ctx := context.Background()
+
+	targetTimeFlag, _ := cmd.Flags().GetString("target-time")
+	dataDir, _ := cmd.Flags().GetString("data-dir")
+	targetAction, _ := cmd.Flags().GetString("recovery-target-action")
+	pathPrefix, _ := cmd.Flags().GetString("path")
+
+	if dataDir == "" {
+		logger.Fatalf("❌ --data-dir is required for point-in-time recovery")
+	}
+
+	targetTime, err := backup.ParseTargetTime(targetTimeFlag)
+	if err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	downloader, err := NewStorageDownloader(cmd, storageType)
+	if err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	locationArgs, err := storageLocationArgs(cmd, storageType)
+	if err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	logger.Infof("🔄 Looking for PostgreSQL base backups in %s...", storageType)
+
+	keys, err := downloader.List(ctx, postgres.BaseBackupPrefix(pathPrefix))
+	if err != nil {
+		logger.Fatalf("❌ Failed to list base backups: %v", err)
+	}
+
+	// Base backups without a manifest were named in local time
+	baseBackup, err := postgres.SelectBaseBackup(ctx, downloader, keys, targetTime, time.Local)
+	if err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	logger.Infof("   Using base backup %s (taken %s)", baseBackup.Key, baseBackup.Timestamp.Format("2006-01-02 15:04:05 MST"))
+
+	if err := postgres.PrepareDataDirectory(dataDir); err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	// Stream the base backup straight into the extractor
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(downloader.Download(ctx, baseBackup.Key, pw))
+	}()
+
+	if err := postgres.ExtractBaseBackup(pr, baseBackup.Key, dataDir); err != nil {
+		pr.CloseWithError(err)
+		logger.Fatalf("❌ Failed to restore base backup: %v", err)
+	}
+	pr.Close()
+
+	walFetchArgs := append([]string{"dbbackup", "wal-fetch"}, locationArgs...)
+	walFetchArgs = append(walFetchArgs, "%f", "%p")
+
+	config := postgres.RecoveryConfig{
+		RestoreCommand: shellJoin(walFetchArgs),
+		TargetTime:     targetTime,
+		TargetAction:   targetAction,
+	}
+
+	if err := postgres.WriteRecoveryConfig(dataDir, config); err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	logger.Infof("✅ Data directory prepared for point-in-time recovery: %s", dataDir)
+	logger.Infof("   Recovery target: %s (%s)", targetTime.Format("2006-01-02 15:04:05 MST"), config.TargetAction)
+	logger.Infof("   restore_command: %s", config.RestoreCommand)
+	logger.Infof("   Start PostgreSQL with: pg_ctl -D %s start", dataDir)
+}
+
+// HandleWALFetch downloads a single archived WAL file; it is invoked by PostgreSQL's restore_command
+func HandleWALFetch(cmd *cobra.Command, args []string, storageType string) {
+	ctx := context.Background()
+
+	walFile, destination := args[0], args[1]
+	pathPrefix, _ := cmd.Flags().GetString("path")
+
+	downloader, err := NewStorageDownloader(cmd, storageType)
+	if err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	key := postgres.WALKey(pathPrefix, walFile)
+	if err := fetchWAL(ctx, downloader, key, destination); err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+}
+
+// fetchWAL writes to a temporary file first so PostgreSQL never sees a partial segment. It
+// returns errors instead of exiting so the temporary file is removed, since PostgreSQL asks
+// for files that were never archived.
+func fetchWAL(ctx context.Context, downloader backup.StorageDownloader, key, destination string) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(destination), ".wal-fetch-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if err := downloader.Download(ctx, key, tmpFile); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to fetch WAL file %s: %w", key, err)
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write WAL file: %w", err)
+	}
+
+	if err := os.Rename(tmpFile.Name(), destination); err != nil {
+		return fmt.Errorf("failed to move WAL file into place: %w", err)
+	}
+	return nil
+}
+
+// shellJoin quotes arguments for /bin/sh, leaving PostgreSQL placeholders untouched
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "%f" || arg == "%p" || isShellSafe(arg) {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
+func isShellSafe(arg string) bool {
+	if arg == "" {
+		return false
+	}
+	for _, r := range arg {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@", r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// validatePostgresRestoreFlags checks flags required for a regular (non point-in-time) restore
+func validatePostgresRestoreFlags(cmd *cobra.Command) error {
+	for _, name := range []string{"target-db", "backup-file"} {
+		if value, _ := cmd.Flags().GetString(name); value == "" {
+			return fmt.Errorf("--%s is required unless --target-time is set", name)
+		}
+	}
+	return nil
+}
+
+// HandleMySQLPITR restores a MySQL dump and replays archived binlogs up to the recovery target
+func HandleMySQLPITR(cmd *cobra.Command, args []string, storageType string) {
+	ctx := context.Background()
+
+	backupFile, _ := cmd.Flags().GetString("backup-file")
+	targetTimeFlag, _ := cmd.Flags().GetString("target-time")
+	targetGTID, _ := cmd.Flags().GetString("target-gtid")
+	pathPrefix, _ := cmd.Flags().GetString("path")
+
+	options := mysql.ReplayOptions{StopGTID: targetGTID}
+	if targetTimeFlag != "" {
+		targetTime, err := backup.ParseTargetTime(targetTimeFlag)
+		if err != nil {
+			logger.Fatalf("❌ %v", err)
+		}
+		options.StopDatetime = targetTime
+	}
+
+	downloader, err := NewStorageDownloader(cmd, storageType)
+	if err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	manifest, err := backup.ReadManifest(ctx, downloader, backupFile)
+	if err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	coordinates, err := mysql.CoordinatesFromMetadata(manifest.Metadata)
+	if err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+	options.StartPosition = coordinates.Position
+	options.SourceDatabase = manifest.DatabaseName
+
+	restorer := newMySQLRestorer(cmd)
+
+	logger.Infof("🔄 Loading dump %s into %s...", backupFile, restorer.Database)
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(downloader.Download(ctx, backupFile, pw))
+	}()
+
+	if err := restorer.LoadDump(ctx, pr); err != nil {
+		pr.CloseWithError(err)
+		logger.Fatalf("❌ Failed to load dump: %v", err)
+	}
+	pr.Close()
+
+	keys, err := downloader.List(ctx, mysql.BinlogPrefix(pathPrefix))
+	if err != nil {
+		logger.Fatalf("❌ Failed to list archived binlogs: %v", err)
+	}
+
+	binlogKeys := mysql.SelectBinlogs(keys, coordinates.File)
+	if len(binlogKeys) == 0 {
+		logger.Fatalf("❌ No archived binlogs found from %s onwards", coordinates.File)
+	}
+
+	logger.Infof("🔄 Replaying %d binlog file(s) from %s:%d...", len(binlogKeys), coordinates.File, coordinates.Position)
+
+	if err := replayArchivedBinlogs(ctx, downloader, restorer, binlogKeys, options); err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	logger.Infof("✅ MySQL point-in-time restore completed: %s", restorer.Database)
+}
+
+// replayArchivedBinlogs downloads binlogs to a working directory and replays them. It returns
+// errors instead of exiting so the working directory is removed.
+func replayArchivedBinlogs(ctx context.Context, downloader backup.StorageDownloader, restorer *mysql.Restorer, keys []string, options mysql.ReplayOptions) error {
+	workDir, err := os.MkdirTemp("", "dbbackup-mysql-restore-*")
+	if err != nil {
+		return fmt.Errorf("failed to create working directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	var files []string
+	for _, key := range keys {
+		localPath := filepath.Join(workDir, filepath.Base(key))
+		if err := downloadToFile(ctx, downloader, key, localPath); err != nil {
+			return err
+		}
+		files = append(files, localPath)
+	}
+
+	if err := restorer.ReplayBinlogs(ctx, files, options); err != nil {
+		return fmt.Errorf("failed to replay binlogs: %w", err)
+	}
+	return nil
+}
+
+func newMySQLRestorer(cmd *cobra.Command) *mysql.Restorer {
+	host, _ := cmd.Flags().GetString("target-host")
+	port, _ := cmd.Flags().GetInt("target-port")
+	database, _ := cmd.Flags().GetString("target-db")
+	username, _ := cmd.Flags().GetString("target-user")
+	password, _ := cmd.Flags().GetString("target-password")
+
+	return &mysql.Restorer{
+		Host:     host,
+		Port:     port,
+		Database: database,
+		Username: username,
+		Password: password,
+		TLS:      TLSFromRestoreFlags(cmd),
+	}
+}
+
+func downloadToFile(ctx context.Context, downloader backup.StorageDownloader, key, filePath string) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", filePath, err)
+	}
+
+	if err := downloader.Download(ctx, key, file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to download %s: %w", key, err)
+	}
+
+	return file.Close()
+}
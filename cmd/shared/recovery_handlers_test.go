@@ -0,0 +1,54 @@
+package shared
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mapDownloader serves objects from a map and fails for anything else
+type mapDownloader map[string]string
+
+func (d mapDownloader) Download(ctx context.Context, key string, writer io.Writer) error {
+	data, ok := d[key]
+	if !ok {
+		return os.ErrNotExist
+	}
+	_, err := io.WriteString(writer, data)
+	return err
+}
+
+func (d mapDownloader) List(ctx context.Context, prefix string) ([]string, error) {
+	return nil, nil
+}
+
+func (d mapDownloader) GetStorageType() string { return "map" }
+
+func TestFetchWAL(t *testing.T) {
+	walDir := t.TempDir()
+	downloader := mapDownloader{"wal/000000010000000000000001": "segment"}
+
+	destination := filepath.Join(walDir, "RECOVERYXLOG")
+	if err := fetchWAL(context.Background(), downloader, "wal/000000010000000000000001", destination); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(destination); err != nil || string(data) != "segment" {
+		t.Errorf("fetched WAL file = %q, %v", data, err)
+	}
+
+	// PostgreSQL routinely asks for history files that were never archived
+	err := fetchWAL(context.Background(), downloader, "wal/00000002.history", filepath.Join(walDir, "RECOVERYHISTORY"))
+	if err == nil {
+		t.Fatal("fetchWAL() of a missing file succeeded")
+	}
+
+	entries, _ := os.ReadDir(walDir)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".wal-fetch-") {
+			t.Errorf("temporary file %s was left in the WAL directory", entry.Name())
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/dbbackup-io/cli/pkg/logger"
+	"github.com/dbbackup-io/cli/pkg/secrets"
+	"github.com/spf13/cobra"
+)
+
+// secretFlags lists flags that accept secret references (env:, file:, cmd:, vault:, awssm:)
+var secretFlags = []string{
+	"db-url",
+	"db-password",
+	"target-password",
+	"sentinel-password",
+	"api-key",
+	"target-api-key",
+	"aws-access-key",
+	"aws-secret-key",
+	"account-key",
+	"service-account-key",
+}
+
+// ResolveSecretFlags replaces secret references in credential flags with the secrets they point to.
+// It runs before any dumper or uploader is constructed from the flags.
+func ResolveSecretFlags(cmd *cobra.Command) error {
+	ctx := context.Background()
+
+	for _, name := range secretFlags {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || !flag.Changed {
+			continue
+		}
+		if !secrets.IsReference(flag.Value.String()) {
+			registerSecret(name, flag.Value.String())
+			continue
+		}
+
+		secret, err := secrets.Resolve(ctx, flag.Value.String())
+		if err != nil {
+			return fmt.Errorf("--%s: %w", name, err)
+		}
+
+		if err := flag.Value.Set(secret); err != nil {
+			return fmt.Errorf("--%s: %w", name, err)
+		}
+		registerSecret(name, secret)
+	}
+
+	return nil
+}
+
+// registerSecret masks a credential in log output. Of a database URL only the password is
+// secret; the URL itself stays readable.
+func registerSecret(name, value string) {
+	if name == "db-url" {
+		if u, err := url.Parse(value); err == nil && u.User != nil {
+			password, _ := u.User.Password()
+			logger.AddSecret(password)
+		}
+		return
+	}
+	logger.AddSecret(value)
+}
@@ -0,0 +1,38 @@
+package shared
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestResolveSecretFlags(t *testing.T) {
+	t.Setenv("DBBACKUP_TEST_PASSWORD", "s3cret")
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("db-password", "", "")
+	cmd.Flags().String("db-host", "", "")
+	cmd.Flags().String("aws-secret-key", "", "")
+	cmd.Flags().Set("db-password", "env:DBBACKUP_TEST_PASSWORD")
+	// Only credential flags are resolved
+	cmd.Flags().Set("db-host", "env:DBBACKUP_TEST_PASSWORD")
+
+	if err := ResolveSecretFlags(cmd); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := cmd.Flags().GetString("db-password"); got != "s3cret" {
+		t.Errorf("--db-password = %q, want the resolved secret", got)
+	}
+	if got, _ := cmd.Flags().GetString("db-host"); got != "env:DBBACKUP_TEST_PASSWORD" {
+		t.Errorf("--db-host = %q, want it unchanged", got)
+	}
+	if got, _ := cmd.Flags().GetString("aws-secret-key"); got != "" {
+		t.Errorf("unset --aws-secret-key = %q, want it left empty", got)
+	}
+
+	cmd.Flags().Set("aws-secret-key", "env:DBBACKUP_TEST_UNSET")
+	if err := ResolveSecretFlags(cmd); err == nil || !strings.Contains(err.Error(), "--aws-secret-key") {
+		t.Errorf("unresolvable reference error = %v, want one naming --aws-secret-key", err)
+	}
+}
@@ -0,0 +1,114 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/destinations/azure"
+	"github.com/dbbackup-io/cli/pkg/destinations/gcs"
+	"github.com/dbbackup-io/cli/pkg/destinations/local"
+	"github.com/dbbackup-io/cli/pkg/destinations/s3"
+	"github.com/spf13/cobra"
+)
+
+// NewStorageDownloader creates a storage downloader from the restore storage flags of a command.
+// Backends that cannot read backups yet are rejected before a restore or restore_command is set up.
+func NewStorageDownloader(cmd *cobra.Command, storageType string) (backup.StorageDownloader, error) {
+	downloader, err := newStorageDownloader(cmd, storageType)
+	if err != nil {
+		return nil, err
+	}
+	if err := backup.StorageAvailable(downloader); err != nil {
+		return nil, err
+	}
+	return downloader, nil
+}
+
+func newStorageDownloader(cmd *cobra.Command, storageType string) (backup.StorageDownloader, error) {
+	switch storageType {
+	case "s3":
+		region, _ := cmd.Flags().GetString("region")
+		bucket, _ := cmd.Flags().GetString("bucket")
+		accessKey, _ := cmd.Flags().GetString("aws-access-key")
+		secretKey, _ := cmd.Flags().GetString("aws-secret-key")
+		return &s3.Uploader{
+			Region:    region,
+			Bucket:    bucket,
+			AccessKey: accessKey,
+			SecretKey: secretKey,
+		}, nil
+	case "gcs":
+		projectID, _ := cmd.Flags().GetString("project-id")
+		bucket, _ := cmd.Flags().GetString("bucket")
+		serviceAccountKey, _ := cmd.Flags().GetString("service-account-key")
+		return &gcs.Uploader{
+			ProjectID:         projectID,
+			Bucket:            bucket,
+			ServiceAccountKey: serviceAccountKey,
+		}, nil
+	case "azure":
+		accountName, _ := cmd.Flags().GetString("account-name")
+		accountKey, _ := cmd.Flags().GetString("account-key")
+		container, _ := cmd.Flags().GetString("container")
+		return &azure.Uploader{
+			AccountName: accountName,
+			AccountKey:  accountKey,
+			Container:   container,
+		}, nil
+	case "local":
+		directory, _ := cmd.Flags().GetString("directory")
+		return &local.Uploader{
+			Directory: directory,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported storage type: %s", storageType)
+	}
+}
+
+// openDownload streams a backup from storage. Closing the reader aborts the download.
+func openDownload(ctx context.Context, downloader backup.StorageDownloader, key string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(downloader.Download(ctx, key, pw))
+	}()
+	return pr
+}
+
+// storageLocationArgs returns the non-secret flags needed to address the same storage location
+// from another dbbackup invocation. Credentials are expected to come from the environment.
+func storageLocationArgs(cmd *cobra.Command, storageType string) ([]string, error) {
+	var names []string
+	switch storageType {
+	case "s3":
+		names = []string{"region", "bucket", "path"}
+	case "gcs":
+		names = []string{"project-id", "bucket", "path"}
+	case "azure":
+		names = []string{"account-name", "container", "path"}
+	case "local":
+		names = []string{"directory"}
+	default:
+		return nil, fmt.Errorf("unsupported storage type: %s", storageType)
+	}
+
+	args := []string{storageType}
+	for _, name := range names {
+		value, _ := cmd.Flags().GetString(name)
+		if value == "" {
+			continue
+		}
+		if name == "directory" {
+			absolute, err := filepath.Abs(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve directory %s: %w", value, err)
+			}
+			value = absolute
+		}
+		args = append(args, "--"+name, value)
+	}
+
+	return args, nil
+}
@@ -0,0 +1,20 @@
+package shared
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestNewStorageDownloaderUnimplemented(t *testing.T) {
+	cmd := &cobra.Command{}
+	AddAzureRestoreFlags(cmd)
+	cmd.Flags().Set("account-name", "backups")
+	cmd.Flags().Set("account-key", "key")
+	cmd.Flags().Set("container", "postgres")
+
+	if _, err := NewStorageDownloader(cmd, "azure"); err == nil || !strings.Contains(err.Error(), "not implemented") {
+		t.Errorf("NewStorageDownloader(azure) error = %v, want it rejected as not implemented", err)
+	}
+}
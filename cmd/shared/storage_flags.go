@@ -1,6 +1,11 @@
 package shared
 
-import "github.com/spf13/cobra"
+import (
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/spf13/cobra"
+)
 
 // S3Flags holds S3-specific flags
 type S3Flags struct {
@@ -9,6 +14,19 @@ type S3Flags struct {
 	Path      string
 	AccessKey string
 	SecretKey string
+
+	// Multipart upload tuning
+	PartSize        string
+	Concurrency     int
+	MaxBufferMemory string
+	ExpectedSize    string
+
+	// Object options
+	StorageClass string
+	SSE          string
+	SSEKMSKeyID  string
+	Tags         map[string]string
+	ACL          string
 }
 
 // GCSFlags holds Google Cloud Storage flags
@@ -35,6 +53,20 @@ type LocalFlags struct {
 // CommonFlags holds common backup flags
 type CommonFlags struct {
 	Compression string
+	DryRun      bool
+	KeyTemplate string
+	JobName     string
+
+	Progress         string
+	ProgressInterval time.Duration
+
+	MaxUploadRate string
+	Nice          int
+	IONice        string
+
+	LocalCopy         string
+	CopyTo            []string
+	DestinationPolicy string
 }
 
 // AddS3Flags adds S3 flags to a command
@@ -43,7 +75,17 @@ func AddS3Flags(cmd *cobra.Command, flags *S3Flags) {
 	cmd.Flags().StringVar(&flags.Bucket, "bucket", "", "S3 bucket name (required)")
 	cmd.Flags().StringVar(&flags.Path, "path", "", "S3 path prefix")
 	cmd.Flags().StringVar(&flags.AccessKey, "aws-access-key", "", "AWS access key")
-	cmd.Flags().StringVar(&flags.SecretKey, "aws-secret-key", "", "AWS secret key")
+	cmd.Flags().StringVar(&flags.SecretKey, "aws-secret-key", "", "AWS secret key (or secret reference)")
+
+	cmd.Flags().StringVar(&flags.PartSize, "part-size", "", "Multipart upload part size, at least 5MB (default: sized from the expected size)")
+	cmd.Flags().IntVar(&flags.Concurrency, "concurrency", 0, "Parts uploaded in parallel (default 5)")
+	cmd.Flags().StringVar(&flags.MaxBufferMemory, "max-buffer-memory", "", "Limit the memory used to buffer parts, lowering the concurrency to fit")
+	cmd.Flags().StringVar(&flags.ExpectedSize, "expected-size", "", "Expected backup size used to size parts, e.g. 200GB (default: the estimated database size)")
+	cmd.Flags().StringVar(&flags.StorageClass, "storage-class", "", "S3 storage class, e.g. STANDARD_IA, GLACIER_IR, DEEP_ARCHIVE")
+	cmd.Flags().StringVar(&flags.SSE, "sse", "", "Server-side encryption: AES256 (SSE-S3) or aws:kms (SSE-KMS)")
+	cmd.Flags().StringVar(&flags.SSEKMSKeyID, "sse-kms-key-id", "", "KMS key ID or ARN for --sse aws:kms (default: the AWS managed key)")
+	cmd.Flags().StringToStringVar(&flags.Tags, "tags", nil, "Object tags as key=value pairs, e.g. env=prod,team=db")
+	cmd.Flags().StringVar(&flags.ACL, "acl", "", "Canned ACL, e.g. bucket-owner-full-control")
 
 	cmd.MarkFlagRequired("bucket")
 }
@@ -62,7 +104,7 @@ func AddGCSFlags(cmd *cobra.Command, flags *GCSFlags) {
 // AddAzureFlags adds Azure Blob Storage flags to a command
 func AddAzureFlags(cmd *cobra.Command, flags *AzureFlags) {
 	cmd.Flags().StringVar(&flags.AccountName, "account-name", "", "Azure storage account name (required)")
-	cmd.Flags().StringVar(&flags.AccountKey, "account-key", "", "Azure storage account key (required)")
+	cmd.Flags().StringVar(&flags.AccountKey, "account-key", "", "Azure storage account key, or secret reference (required)")
 	cmd.Flags().StringVar(&flags.Container, "container", "", "Azure blob container name (required)")
 	cmd.Flags().StringVar(&flags.Path, "path", "", "Azure blob path prefix")
 
@@ -79,14 +121,28 @@ func AddLocalFlags(cmd *cobra.Command, flags *LocalFlags) {
 // AddCommonFlags adds common backup flags to a command
 func AddCommonFlags(cmd *cobra.Command, flags *CommonFlags) {
 	cmd.Flags().StringVar(&flags.Compression, "compression", "gz", "Compression type (gz, none)")
+	cmd.Flags().StringVar(&flags.KeyTemplate, "key-template", backup.DefaultKeyTemplate,
+		"Object key template (variables: .DBType .DBName .Host .Hostname .JobName .Time, e.g. {{.Time.UTC | date \"2006/01/02\"}}); the file extension is appended")
+	cmd.Flags().StringVar(&flags.JobName, "job-name", "", "Job name available to --key-template as {{.JobName}}")
+	cmd.Flags().StringVar(&flags.Progress, "progress", backup.ProgressAuto, "Progress reporting (auto, bar, log, none); auto draws a bar on a terminal and logs otherwise")
+	cmd.Flags().DurationVar(&flags.ProgressInterval, "progress-interval", backup.DefaultProgressInterval, "How often progress is logged when it is not drawn as a bar")
+	cmd.Flags().StringVar(&flags.MaxUploadRate, "max-upload-rate", "", "Limit the upload rate, e.g. 10MB, 512K/s or 100Mbps (default: unlimited)")
+	cmd.Flags().IntVar(&flags.Nice, "nice", 0, "CPU niceness of the dump tool, from -20 to 19 (higher runs at a lower priority)")
+	cmd.Flags().StringVar(&flags.IONice, "ionice", "", "I/O priority of the dump tool on Linux: idle, best-effort[:0-7] or realtime[:0-7]")
+	cmd.Flags().StringVar(&flags.LocalCopy, "local-copy", "", "Also write the backup to this local directory, from the same dump")
+	cmd.Flags().StringArrayVar(&flags.CopyTo, "copy-to", nil, "Also write the backup to this storage URL (e.g. s3://bucket/prefix), from the same dump; repeatable")
+	cmd.Flags().StringVar(&flags.DestinationPolicy, "destination-policy", backup.DestinationPolicyAll,
+		"With several destinations: all (every destination must succeed) or any (at least one must succeed)")
+	cmd.Flags().BoolVar(&flags.DryRun, "dry-run", false, "Print the dump command, destination and pipeline without running the backup")
 }
 
 // Restore-specific storage flags
 func AddS3RestoreFlags(cmd *cobra.Command) {
 	cmd.Flags().String("region", "us-east-1", "AWS region")
 	cmd.Flags().String("bucket", "", "S3 bucket name (required)")
+	cmd.Flags().String("path", "", "S3 path prefix")
 	cmd.Flags().String("aws-access-key", "", "AWS access key")
-	cmd.Flags().String("aws-secret-key", "", "AWS secret key")
+	cmd.Flags().String("aws-secret-key", "", "AWS secret key (or secret reference)")
 
 	cmd.MarkFlagRequired("bucket")
 }
@@ -94,6 +150,7 @@ func AddS3RestoreFlags(cmd *cobra.Command) {
 func AddGCSRestoreFlags(cmd *cobra.Command) {
 	cmd.Flags().String("project-id", "", "GCS project ID (required)")
 	cmd.Flags().String("bucket", "", "GCS bucket name (required)")
+	cmd.Flags().String("path", "", "GCS path prefix")
 	cmd.Flags().String("service-account-key", "", "Service account key JSON")
 
 	cmd.MarkFlagRequired("project-id")
@@ -102,8 +159,9 @@ func AddGCSRestoreFlags(cmd *cobra.Command) {
 
 func AddAzureRestoreFlags(cmd *cobra.Command) {
 	cmd.Flags().String("account-name", "", "Azure storage account name (required)")
-	cmd.Flags().String("account-key", "", "Azure storage account key (required)")
+	cmd.Flags().String("account-key", "", "Azure storage account key, or secret reference (required)")
 	cmd.Flags().String("container", "", "Azure blob container name (required)")
+	cmd.Flags().String("path", "", "Azure blob path prefix")
 
 	cmd.MarkFlagRequired("account-name")
 	cmd.MarkFlagRequired("account-key")
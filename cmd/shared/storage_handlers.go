@@ -2,76 +2,276 @@ package shared
 
 import (
 	"context"
-	"log"
+	"path/filepath"
+	"strings"
 
 	"github.com/dbbackup-io/cli/pkg/backup"
 	"github.com/dbbackup-io/cli/pkg/destinations/azure"
 	"github.com/dbbackup-io/cli/pkg/destinations/gcs"
 	"github.com/dbbackup-io/cli/pkg/destinations/local"
 	"github.com/dbbackup-io/cli/pkg/destinations/s3"
+	"github.com/dbbackup-io/cli/pkg/logger"
+	"github.com/dbbackup-io/cli/pkg/sources/cockroachdb"
+	"github.com/dbbackup-io/cli/pkg/sources/elasticsearch"
+	"github.com/dbbackup-io/cli/pkg/sources/mariadb"
+	"github.com/dbbackup-io/cli/pkg/sources/sqlite"
+	"github.com/dbbackup-io/cli/pkg/sources/sqlserver"
 	"github.com/spf13/cobra"
 )
 
+// NewS3Uploader creates an S3 uploader from flags
+func NewS3Uploader(s3Flags S3Flags) *s3.Uploader {
+	uploader := &s3.Uploader{
+		Region:       s3Flags.Region,
+		Bucket:       s3Flags.Bucket,
+		AccessKey:    s3Flags.AccessKey,
+		SecretKey:    s3Flags.SecretKey,
+		Concurrency:  s3Flags.Concurrency,
+		StorageClass: s3Flags.StorageClass,
+		SSE:          s3Flags.SSE,
+		SSEKMSKeyID:  s3Flags.SSEKMSKeyID,
+		Tags:         s3Flags.Tags,
+		ACL:          s3Flags.ACL,
+	}
+
+	sizes := []struct {
+		flag   string
+		value  string
+		target *int64
+	}{
+		{"part-size", s3Flags.PartSize, &uploader.PartSize},
+		{"max-buffer-memory", s3Flags.MaxBufferMemory, &uploader.MaxBufferMemory},
+		{"expected-size", s3Flags.ExpectedSize, &uploader.SizeHint},
+	}
+	for _, size := range sizes {
+		value, err := backup.ParseSize(size.value)
+		if err != nil {
+			logger.Fatalf("❌ --%s: %v", size.flag, err)
+		}
+		*size.target = value
+	}
+
+	if err := uploader.Validate(); err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+	return uploader
+}
+
+// NewGCSUploader creates a Google Cloud Storage uploader from flags
+func NewGCSUploader(gcsFlags GCSFlags) *gcs.Uploader {
+	return &gcs.Uploader{
+		ProjectID:         gcsFlags.ProjectID,
+		Bucket:            gcsFlags.Bucket,
+		ServiceAccountKey: gcsFlags.ServiceAccountKey,
+	}
+}
+
+// NewAzureUploader creates an Azure Blob Storage uploader from flags
+func NewAzureUploader(azureFlags AzureFlags) *azure.Uploader {
+	return &azure.Uploader{
+		AccountName: azureFlags.AccountName,
+		AccountKey:  azureFlags.AccountKey,
+		Container:   azureFlags.Container,
+	}
+}
+
+// NewLocalUploader creates a local storage uploader from flags
+func NewLocalUploader(localFlags LocalFlags) *local.Uploader {
+	return &local.Uploader{
+		Directory: localFlags.Directory,
+	}
+}
+
 // HandleS3Export handles export to S3 for any database
 func HandleS3Export(cmd *cobra.Command, args []string, dumper backup.DatabaseDumper, s3Flags S3Flags, commonFlags CommonFlags) {
+	logRunContext(dumper.GetDatabaseType(), "s3")
+
 	ctx := context.Background()
 
 	// Create S3 uploader
-	uploader := &s3.Uploader{
-		Region:    s3Flags.Region,
-		Bucket:    s3Flags.Bucket,
-		AccessKey: s3Flags.AccessKey,
-		SecretKey: s3Flags.SecretKey,
+	uploader := NewS3Uploader(s3Flags)
+
+	// Create backup executor
+	executor := &backup.BackupExecutor{
+		Dumper:       dumper,
+		Uploader:     uploader,
+		Destinations: extraDestinations(commonFlags, s3Flags.Path),
+		Config:       newBackupConfig(dumper, commonFlags, s3Flags.Path),
 	}
 
-	// Create backup config
-	config := backup.BackupConfig{
-		DatabaseType: dumper.GetDatabaseType(),
-		DatabaseName: getDatabaseNameFromDumper(dumper),
-		Compression:  commonFlags.Compression,
-		PathPrefix:   s3Flags.Path,
+	if commonFlags.DryRun {
+		HandleDryRun(executor)
+		return
 	}
 
-	// Create and execute backup
+	logger.Infof("🔄 Starting %s backup to %s...", dumper.GetDatabaseType(), destinationNames(executor.AllDestinations()))
+
+	if err := executor.Execute(ctx); err != nil {
+		logger.Fatalf("❌ Backup failed: %v", err)
+	}
+}
+
+// HandleURLExport handles export to the storage URLs given with --to for any database
+func HandleURLExport(dumper backup.DatabaseDumper, toURLs []string, commonFlags CommonFlags) {
+	var (
+		destinations []backup.Destination
+		storageTypes []string
+	)
+	for _, raw := range toURLs {
+		uploader, prefix, err := OpenStorageURL(raw)
+		if err != nil {
+			logger.Fatalf("❌ --to: %v", err)
+		}
+		destinations = append(destinations, backup.Destination{Uploader: uploader, PathPrefix: prefix})
+		storageTypes = append(storageTypes, uploader.GetStorageType())
+	}
+	logRunContext(dumper.GetDatabaseType(), strings.Join(storageTypes, ","))
+
+	ctx := context.Background()
+
+	// Create backup executor; each destination keeps the prefix from its URL
 	executor := &backup.BackupExecutor{
-		Dumper:   dumper,
-		Uploader: uploader,
-		Config:   config,
+		Dumper:       dumper,
+		Destinations: append(destinations, extraDestinations(commonFlags, destinations[0].PathPrefix)...),
+		Config:       newBackupConfig(dumper, commonFlags, ""),
 	}
 
-	log.Printf("🔄 Starting %s backup to S3...", dumper.GetDatabaseType())
+	if commonFlags.DryRun {
+		HandleDryRun(executor)
+		return
+	}
+
+	logger.Infof("🔄 Starting %s backup to %s...", dumper.GetDatabaseType(), destinationNames(executor.AllDestinations()))
 
 	if err := executor.Execute(ctx); err != nil {
-		log.Fatalf("❌ Backup failed: %v", err)
+		logger.Fatalf("❌ Backup failed: %v", err)
 	}
 }
 
 // HandleGCSExport handles export to Google Cloud Storage for any database
 func HandleGCSExport(cmd *cobra.Command, args []string, dumper backup.DatabaseDumper, gcsFlags GCSFlags, commonFlags CommonFlags) {
+	logRunContext(dumper.GetDatabaseType(), "gcs")
+
 	// Create GCS uploader
-	uploader := &gcs.Uploader{
-		ProjectID:         gcsFlags.ProjectID,
-		Bucket:            gcsFlags.Bucket,
-		ServiceAccountKey: gcsFlags.ServiceAccountKey,
+	uploader := NewGCSUploader(gcsFlags)
+
+	if commonFlags.DryRun {
+		HandleDryRun(&backup.BackupExecutor{
+			Dumper:       dumper,
+			Uploader:     uploader,
+			Destinations: extraDestinations(commonFlags, gcsFlags.Path),
+			Config:       newBackupConfig(dumper, commonFlags, gcsFlags.Path),
+		})
+		logger.Warnf("⚠️  %s to Google Cloud Storage export is not implemented yet", dumper.GetDatabaseType())
+		return
 	}
 
-	log.Printf("🔄 %s to Google Cloud Storage export not implemented yet", dumper.GetDatabaseType())
+	logger.Infof("🔄 %s to Google Cloud Storage export not implemented yet", dumper.GetDatabaseType())
 	_ = uploader // Avoid unused variable warning
 }
 
 // HandleAzureExport handles export to Azure Blob Storage for any database
 func HandleAzureExport(cmd *cobra.Command, args []string, dumper backup.DatabaseDumper, azureFlags AzureFlags, commonFlags CommonFlags) {
+	logRunContext(dumper.GetDatabaseType(), "azure")
+
 	// Create Azure uploader
-	uploader := &azure.Uploader{
-		AccountName: azureFlags.AccountName,
-		AccountKey:  azureFlags.AccountKey,
-		Container:   azureFlags.Container,
+	uploader := NewAzureUploader(azureFlags)
+
+	if commonFlags.DryRun {
+		HandleDryRun(&backup.BackupExecutor{
+			Dumper:       dumper,
+			Uploader:     uploader,
+			Destinations: extraDestinations(commonFlags, azureFlags.Path),
+			Config:       newBackupConfig(dumper, commonFlags, azureFlags.Path),
+		})
+		logger.Warnf("⚠️  %s to Azure Blob Storage export is not implemented yet", dumper.GetDatabaseType())
+		return
 	}
 
-	log.Printf("🔄 %s to Azure Blob Storage export not implemented yet", dumper.GetDatabaseType())
+	logger.Infof("🔄 %s to Azure Blob Storage export not implemented yet", dumper.GetDatabaseType())
 	_ = uploader // Avoid unused variable warning
 }
 
+// extraDestinations returns the destinations that receive the dump in addition to the
+// command's storage: --local-copy under the same path prefix, and each --copy-to URL under
+// the prefix from its URL
+func extraDestinations(commonFlags CommonFlags, pathPrefix string) []backup.Destination {
+	var destinations []backup.Destination
+	if commonFlags.LocalCopy != "" {
+		destinations = append(destinations, backup.Destination{
+			Uploader:   NewLocalUploader(LocalFlags{Directory: commonFlags.LocalCopy}),
+			PathPrefix: pathPrefix,
+		})
+	}
+	for _, raw := range commonFlags.CopyTo {
+		uploader, prefix, err := OpenStorageURL(raw)
+		if err != nil {
+			logger.Fatalf("❌ --copy-to: %v", err)
+		}
+		destinations = append(destinations, backup.Destination{Uploader: uploader, PathPrefix: prefix})
+	}
+	return destinations
+}
+
+// destinationNames lists the display names of storage destinations, e.g. "S3 and local storage"
+func destinationNames(destinations []backup.Destination) string {
+	names := make([]string, len(destinations))
+	for i, destination := range destinations {
+		names[i] = storageNames[destination.Uploader.GetStorageType()]
+	}
+	if len(names) <= 1 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// logRunContext tags the following log records with a run id and the database and storage types
+func logRunContext(dbType, storageType string) {
+	logger.With("run_id", logger.NewRunID(), "db", dbType, "storage", storageType)
+}
+
+// newBackupConfig creates the backup config for a dump command
+func newBackupConfig(dumper backup.DatabaseDumper, commonFlags CommonFlags, pathPrefix string) backup.BackupConfig {
+	config := backup.BackupConfig{
+		DatabaseType: dumper.GetDatabaseType(),
+		DatabaseName: getDatabaseNameFromDumper(dumper),
+		Compression:  commonFlags.Compression,
+		PathPrefix:   pathPrefix,
+		KeyTemplate:  commonFlags.KeyTemplate,
+		JobName:      commonFlags.JobName,
+
+		Progress:         commonFlags.Progress,
+		ProgressInterval: commonFlags.ProgressInterval,
+	}
+	if err := backup.ValidateProgressMode(config.Progress); err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	rate, err := backup.ParseRate(commonFlags.MaxUploadRate)
+	if err != nil {
+		logger.Fatalf("❌ --max-upload-rate: %v", err)
+	}
+	config.MaxUploadRate = rate
+
+	if err := backup.ValidateDestinationPolicy(commonFlags.DestinationPolicy); err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+	config.DestinationPolicy = commonFlags.DestinationPolicy
+
+	// The priority applies to every dump tool the sources start from here on
+	ioClass, ioLevel, err := backup.ParseIONice(commonFlags.IONice)
+	if err != nil {
+		logger.Fatalf("❌ --ionice: %v", err)
+	}
+	if err := backup.SetProcessPriority(backup.ProcessPriority{Nice: commonFlags.Nice, IOClass: ioClass, IOLevel: ioLevel}); err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+	if d, ok := dumper.(interface{ GetDatabaseHost() string }); ok {
+		config.Host = d.GetDatabaseHost()
+	}
+	return config
+}
+
 // Helper function to extract database name from dumper
 func getDatabaseNameFromDumper(dumper backup.DatabaseDumper) string {
 	// This is a bit hacky, but we can use type assertion to get the database name
@@ -86,78 +286,367 @@ func getDatabaseNameFromDumper(dumper backup.DatabaseDumper) string {
 
 // Restore handlers (placeholder implementations)
 func HandlePostgresRestore(cmd *cobra.Command, args []string, storageType string) {
-	log.Printf("🔄 PostgreSQL restore from %s not implemented yet", storageType)
-	log.Println("   This would:")
-	log.Println("   1. Download backup file from storage")
-	log.Println("   2. Connect to target PostgreSQL database")
-	log.Println("   3. Execute pg_restore command")
-	log.Println("   4. Verify restoration success")
+	logRunContext("postgres", storageType)
+
+	if targetTime, _ := cmd.Flags().GetString("target-time"); targetTime != "" {
+		HandlePostgresPITR(cmd, args, storageType)
+		return
+	}
+
+	if err := validatePostgresRestoreFlags(cmd); err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	if storageType == "local" {
+		HandleLocalRestore(cmd, args, "PostgreSQL")
+		return
+	}
+
+	logger.Infof("🔄 PostgreSQL restore from %s not implemented yet", storageType)
+	logger.Info("   This would:")
+	logger.Info("   1. Download backup file from storage")
+	logger.Info("   2. Connect to target PostgreSQL database")
+	logger.Info("   3. Execute pg_restore command")
+	logger.Info("   4. Verify restoration success")
 }
 
 func HandleMySQLRestore(cmd *cobra.Command, args []string, storageType string) {
-	log.Printf("🔄 MySQL restore from %s not implemented yet", storageType)
-	log.Println("   This would:")
-	log.Println("   1. Download backup file from storage")
-	log.Println("   2. Connect to target MySQL database")
-	log.Println("   3. Execute mysql restore command")
-	log.Println("   4. Verify restoration success")
+	logRunContext("mysql", storageType)
+
+	targetTime, _ := cmd.Flags().GetString("target-time")
+	targetGTID, _ := cmd.Flags().GetString("target-gtid")
+	if targetTime != "" || targetGTID != "" {
+		HandleMySQLPITR(cmd, args, storageType)
+		return
+	}
+
+	if storageType == "local" {
+		HandleLocalRestore(cmd, args, "MySQL")
+		return
+	}
+
+	logger.Infof("🔄 MySQL restore from %s not implemented yet", storageType)
+	logger.Info("   This would:")
+	logger.Info("   1. Download backup file from storage")
+	logger.Info("   2. Connect to target MySQL database")
+	logger.Info("   3. Execute mysql restore command")
+	logger.Info("   4. Verify restoration success")
 }
 
 func HandleMongoDBRestore(cmd *cobra.Command, args []string, storageType string) {
-	log.Printf("🔄 MongoDB restore from %s not implemented yet", storageType)
-	log.Println("   This would:")
-	log.Println("   1. Download backup file from storage")
-	log.Println("   2. Connect to target MongoDB database")
-	log.Println("   3. Execute mongorestore command")
-	log.Println("   4. Verify restoration success")
+	logRunContext("mongodb", storageType)
+
+	logger.Infof("🔄 MongoDB restore from %s not implemented yet", storageType)
+	logger.Info("   This would:")
+	logger.Info("   1. Download backup file from storage")
+	logger.Info("   2. Connect to target MongoDB database")
+	logger.Info("   3. Execute mongorestore command")
+	logger.Info("   4. Verify restoration success")
 }
 
 func HandleRedisRestore(cmd *cobra.Command, args []string, storageType string) {
-	log.Printf("🔄 Redis restore from %s not implemented yet", storageType)
-	log.Println("   This would:")
-	log.Println("   1. Download backup file from storage")
-	log.Println("   2. Stop target Redis instance")
-	log.Println("   3. Replace RDB file")
-	log.Println("   4. Restart Redis instance")
+	logRunContext("redis", storageType)
+
+	logger.Infof("🔄 Redis restore from %s not implemented yet", storageType)
+	logger.Info("   This would:")
+	logger.Info("   1. Download backup file from storage")
+	logger.Info("   2. Stop target Redis instance")
+	logger.Info("   3. Replace RDB file")
+	logger.Info("   4. Restart Redis instance")
+}
+
+func HandleSQLiteRestore(cmd *cobra.Command, args []string, storageType string) {
+	logRunContext("sqlite", storageType)
+
+	ctx := context.Background()
+
+	backupFile, _ := cmd.Flags().GetString("backup-file")
+	targetPath, _ := cmd.Flags().GetString("target-path")
+	force, _ := cmd.Flags().GetBool("force")
+
+	downloader, err := NewStorageDownloader(cmd, storageType)
+	if err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	restorer := &sqlite.Restorer{
+		Path:  targetPath,
+		Force: force,
+	}
+
+	logger.Infof("🔄 Restoring SQLite backup %s to %s...", backupFile, targetPath)
+
+	reader := openDownload(ctx, downloader, backupFile)
+	defer reader.Close()
+
+	if err := restorer.Restore(ctx, reader); err != nil {
+		logger.Fatalf("❌ Restore failed: %v", err)
+	}
+
+	logger.Infof("✅ SQLite database restored to %s", targetPath)
+}
+
+func HandleMariaDBRestore(cmd *cobra.Command, args []string, storageType string) {
+	logRunContext("mariadb", storageType)
+
+	ctx := context.Background()
+
+	backupFile, _ := cmd.Flags().GetString("backup-file")
+	targetHost, _ := cmd.Flags().GetString("target-host")
+	targetPort, _ := cmd.Flags().GetInt("target-port")
+	targetDB, _ := cmd.Flags().GetString("target-db")
+	targetUser, _ := cmd.Flags().GetString("target-user")
+	targetPassword, _ := cmd.Flags().GetString("target-password")
+	dataDir, _ := cmd.Flags().GetString("data-dir")
+
+	downloader, err := NewStorageDownloader(cmd, storageType)
+	if err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	restorer := &mariadb.Restorer{
+		Host:     targetHost,
+		Port:     targetPort,
+		Database: targetDB,
+		Username: targetUser,
+		Password: targetPassword,
+		TLS:      TLSFromRestoreFlags(cmd),
+		DataDir:  dataDir,
+	}
+
+	reader := openDownload(ctx, downloader, backupFile)
+	defer reader.Close()
+
+	if backupExtension(backupFile) == ".xbstream" {
+		logger.Infof("🔄 Restoring mariabackup backup %s into %s...", backupFile, dataDir)
+		err = restorer.RestorePhysical(ctx, reader)
+	} else {
+		logger.Infof("🔄 Loading MariaDB dump %s into %s:%d...", backupFile, targetHost, targetPort)
+		err = restorer.LoadDump(ctx, reader)
+	}
+	if err != nil {
+		logger.Fatalf("❌ Restore failed: %v", err)
+	}
+
+	logger.Infof("✅ MariaDB backup %s restored", backupFile)
+}
+
+func HandleCockroachDBRestore(cmd *cobra.Command, args []string, storageType string) {
+	logRunContext("cockroachdb", storageType)
+
+	ctx := context.Background()
+
+	backupFile, _ := cmd.Flags().GetString("backup-file")
+	targetHost, _ := cmd.Flags().GetString("target-host")
+	targetPort, _ := cmd.Flags().GetInt("target-port")
+	targetDB, _ := cmd.Flags().GetString("target-db")
+	targetUser, _ := cmd.Flags().GetString("target-user")
+	targetPassword, _ := cmd.Flags().GetString("target-password")
+	externDir, _ := cmd.Flags().GetString("extern-dir")
+	nodeID, _ := cmd.Flags().GetInt("node-id")
+
+	downloader, err := NewStorageDownloader(cmd, storageType)
+	if err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	restorer := &cockroachdb.Restorer{
+		Connection: cockroachdb.Connection{
+			Host:     targetHost,
+			Port:     targetPort,
+			Username: targetUser,
+			Password: targetPassword,
+			TLS:      TLSFromRestoreFlags(cmd),
+		},
+		Database:  targetDB,
+		ExternDir: externDir,
+		NodeID:    nodeID,
+	}
+
+	if backupExtension(backupFile) != ".tar" {
+		logger.Infof("🔄 Loading CockroachDB dump %s into %s:%d...", backupFile, targetHost, targetPort)
+
+		reader := openDownload(ctx, downloader, backupFile)
+		defer reader.Close()
+
+		if err := restorer.LoadDump(ctx, reader); err != nil {
+			logger.Fatalf("❌ Restore failed: %v", err)
+		}
+		logger.Infof("✅ CockroachDB dump %s restored", backupFile)
+		return
+	}
+
+	// RESTORE needs to know whether the backup holds one database or the whole cluster
+	manifest, err := backup.ReadManifest(ctx, downloader, backupFile)
+	if err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+	sourceDatabase := manifest.DatabaseName
+	if manifest.Metadata["backup_scope"] == "cluster" {
+		sourceDatabase = ""
+	}
+
+	logger.Infof("🔄 Restoring CockroachDB backup %s to %s:%d...", backupFile, targetHost, targetPort)
+
+	reader := openDownload(ctx, downloader, backupFile)
+	defer reader.Close()
+
+	if err := restorer.RestoreBackup(ctx, reader, sourceDatabase); err != nil {
+		logger.Fatalf("❌ Restore failed: %v", err)
+	}
+
+	logger.Infof("✅ CockroachDB backup %s restored", backupFile)
+}
+
+func HandleSQLServerRestore(cmd *cobra.Command, args []string, storageType string) {
+	logRunContext("sqlserver", storageType)
+
+	ctx := context.Background()
+
+	backupFile, _ := cmd.Flags().GetString("backup-file")
+	targetHost, _ := cmd.Flags().GetString("target-host")
+	targetPort, _ := cmd.Flags().GetInt("target-port")
+	targetDB, _ := cmd.Flags().GetString("target-db")
+	targetUser, _ := cmd.Flags().GetString("target-user")
+	targetPassword, _ := cmd.Flags().GetString("target-password")
+	backupDir, _ := cmd.Flags().GetString("backup-dir")
+	localBackupDir, _ := cmd.Flags().GetString("local-backup-dir")
+	move, _ := cmd.Flags().GetStringToString("move")
+	allowPasswordArg, _ := cmd.Flags().GetBool("bcp-password-arg")
+
+	downloader, err := NewStorageDownloader(cmd, storageType)
+	if err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	restorer := &sqlserver.Restorer{
+		Connection: sqlserver.Connection{
+			Host:     targetHost,
+			Port:     targetPort,
+			Username: targetUser,
+			Password: targetPassword,
+			TLS:      TLSFromRestoreFlags(cmd),
+
+			AllowPasswordArg: allowPasswordArg,
+		},
+		Database:       targetDB,
+		BackupDir:      backupDir,
+		LocalBackupDir: localBackupDir,
+		Move:           move,
+	}
+
+	logger.Infof("🔄 Restoring SQL Server backup %s to database %s...", backupFile, targetDB)
+
+	reader := openDownload(ctx, downloader, backupFile)
+	defer reader.Close()
+
+	if backupExtension(backupFile) == ".tar" {
+		err = restorer.RestoreBCP(ctx, reader)
+	} else {
+		err = restorer.RestoreBackup(ctx, reader)
+	}
+	if err != nil {
+		logger.Fatalf("❌ Restore failed: %v", err)
+	}
+
+	logger.Infof("✅ SQL Server database %s restored", targetDB)
+}
+
+func HandleElasticsearchRestore(cmd *cobra.Command, args []string, storageType string) {
+	logRunContext("elasticsearch", storageType)
+
+	ctx := context.Background()
+
+	backupFile, _ := cmd.Flags().GetString("backup-file")
+	targetHost, _ := cmd.Flags().GetString("target-host")
+	targetPort, _ := cmd.Flags().GetInt("target-port")
+	targetUser, _ := cmd.Flags().GetString("target-user")
+	targetPassword, _ := cmd.Flags().GetString("target-password")
+	targetAPIKey, _ := cmd.Flags().GetString("target-api-key")
+	indices, _ := cmd.Flags().GetStringSlice("index")
+	indexPrefix, _ := cmd.Flags().GetString("index-prefix")
+	batchSize, _ := cmd.Flags().GetInt("batch-size")
+	repoDir, _ := cmd.Flags().GetString("repo-dir")
+	localRepoDir, _ := cmd.Flags().GetString("local-repo-dir")
+
+	downloader, err := NewStorageDownloader(cmd, storageType)
+	if err != nil {
+		logger.Fatalf("❌ %v", err)
+	}
+
+	restorer := &elasticsearch.Restorer{
+		Connection: elasticsearch.Connection{
+			Host:     targetHost,
+			Port:     targetPort,
+			Username: targetUser,
+			Password: targetPassword,
+			APIKey:   targetAPIKey,
+			TLS:      TLSFromRestoreFlags(cmd),
+		},
+		Indices:      indices,
+		IndexPrefix:  indexPrefix,
+		BatchSize:    batchSize,
+		RepoDir:      repoDir,
+		LocalRepoDir: localRepoDir,
+	}
+
+	logger.Infof("🔄 Restoring Elasticsearch backup %s to %s:%d...", backupFile, targetHost, targetPort)
+
+	reader := openDownload(ctx, downloader, backupFile)
+	defer reader.Close()
+
+	if backupExtension(backupFile) == ".tar" {
+		err = restorer.RestoreSnapshot(ctx, reader)
+	} else {
+		err = restorer.LoadNDJSON(ctx, reader)
+	}
+	if err != nil {
+		logger.Fatalf("❌ Restore failed: %v", err)
+	}
+
+	logger.Infof("✅ Elasticsearch backup %s restored", backupFile)
+}
+
+// backupExtension returns the extension of a backup key, ignoring compression
+func backupExtension(key string) string {
+	return filepath.Ext(strings.TrimSuffix(key, ".gz"))
 }
 
 // HandleLocalExport handles export to local storage for any database
 func HandleLocalExport(cmd *cobra.Command, args []string, dumper backup.DatabaseDumper, localFlags LocalFlags, commonFlags CommonFlags) {
+	logRunContext(dumper.GetDatabaseType(), "local")
+
 	ctx := context.Background()
 
 	// Create local uploader
-	uploader := &local.Uploader{
-		Directory: localFlags.Directory,
-	}
+	uploader := NewLocalUploader(localFlags)
 
-	// Create backup config
-	config := backup.BackupConfig{
-		DatabaseType: dumper.GetDatabaseType(),
-		DatabaseName: getDatabaseNameFromDumper(dumper),
-		Compression:  commonFlags.Compression,
-		PathPrefix:   "",
+	// Create backup executor
+	executor := &backup.BackupExecutor{
+		Dumper:       dumper,
+		Uploader:     uploader,
+		Destinations: extraDestinations(commonFlags, ""),
+		Config:       newBackupConfig(dumper, commonFlags, ""),
 	}
 
-	// Create and execute backup
-	executor := &backup.BackupExecutor{
-		Dumper:   dumper,
-		Uploader: uploader,
-		Config:   config,
+	if commonFlags.DryRun {
+		HandleDryRun(executor)
+		return
 	}
 
-	log.Printf("🔄 Starting %s backup to local storage...", dumper.GetDatabaseType())
+	logger.Infof("🔄 Starting %s backup to %s...", dumper.GetDatabaseType(), destinationNames(executor.AllDestinations()))
 
 	if err := executor.Execute(ctx); err != nil {
-		log.Fatalf("❌ Backup failed: %v", err)
+		logger.Fatalf("❌ Backup failed: %v", err)
 	}
 }
 
 // HandleLocalRestore handles restore from local storage for any database
 func HandleLocalRestore(cmd *cobra.Command, args []string, dbType string) {
-	log.Printf("🔄 %s restore from local storage not implemented yet", dbType)
-	log.Println("   This would:")
-	log.Println("   1. Read backup file from local directory")
-	log.Println("   2. Connect to target database")
-	log.Println("   3. Execute restore command")
-	log.Println("   4. Verify restoration success")
+	logger.Infof("🔄 %s restore from local storage not implemented yet", dbType)
+	logger.Info("   This would:")
+	logger.Info("   1. Read backup file from local directory")
+	logger.Info("   2. Connect to target database")
+	logger.Info("   3. Execute restore command")
+	logger.Info("   4. Verify restoration success")
 }
@@ -0,0 +1,207 @@
+package shared
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/destinations/azure"
+	"github.com/dbbackup-io/cli/pkg/destinations/gcs"
+	"github.com/dbbackup-io/cli/pkg/destinations/local"
+	"github.com/dbbackup-io/cli/pkg/destinations/s3"
+	"github.com/dbbackup-io/cli/pkg/destinations/sftp"
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// StorageOpener creates the storage backend a URL addresses and returns the key prefix
+// within it
+type StorageOpener func(u *url.URL) (backup.StorageUploader, string, error)
+
+// storageSchemes maps storage URL schemes to the backends they open
+var storageSchemes = map[string]StorageOpener{}
+
+// RegisterStorageScheme makes a URL scheme usable wherever storage is given as a URL
+func RegisterStorageScheme(scheme string, opener StorageOpener) {
+	storageSchemes[scheme] = opener
+}
+
+func init() {
+	RegisterStorageScheme("s3", openS3URL)
+	RegisterStorageScheme("gs", openGCSURL)
+	RegisterStorageScheme("gcs", openGCSURL)
+	RegisterStorageScheme("azblob", openAzureURL)
+	RegisterStorageScheme("azure", openAzureURL)
+	RegisterStorageScheme("file", openFileURL)
+	RegisterStorageScheme("sftp", openSFTPURL)
+}
+
+// StorageURLHelp describes the storage URL forms for command help
+const StorageURLHelp = `  s3://bucket/prefix?region=eu-west-1&storage-class=STANDARD_IA
+  file:///var/backups (or a plain directory path)
+  sftp://user@host:22/var/backups (sftp://host/~/backups for the home directory)
+Credentials come from the environment (the AWS credential chain, the SSH agent and
+~/.ssh/config), never from the URL.`
+
+// OpenStorageURL returns the storage backend and key prefix a URL addresses. A value
+// without a scheme is a local directory.
+func OpenStorageURL(raw string) (backup.StorageUploader, string, error) {
+	if !strings.Contains(raw, "://") {
+		return &local.Uploader{Directory: raw}, "", nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid storage URL %q: %w", raw, err)
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		return nil, "", fmt.Errorf("storage URL %q must not contain a password; use the environment", u.Redacted())
+	}
+
+	opener, ok := storageSchemes[u.Scheme]
+	if !ok {
+		schemes := make([]string, 0, len(storageSchemes))
+		for scheme := range storageSchemes {
+			schemes = append(schemes, scheme)
+		}
+		sort.Strings(schemes)
+		return nil, "", fmt.Errorf("unsupported storage URL scheme %q (expected one of %s)", u.Scheme, strings.Join(schemes, ", "))
+	}
+
+	storage, prefix, err := opener(u)
+	if err == nil {
+		err = backup.StorageAvailable(storage)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("storage URL %q: %w", u.Redacted(), err)
+	}
+	return storage, prefix, nil
+}
+
+// checkStorageURL rejects URL parts and query options a backend does not use
+func checkStorageURL(u *url.URL, allowUser bool, options ...string) error {
+	if u.User != nil && !allowUser {
+		return fmt.Errorf("a user is not supported; use the environment for credentials")
+	}
+	for name := range u.Query() {
+		if !contains(options, name) {
+			if len(options) == 0 {
+				return fmt.Errorf("unknown option %q", name)
+			}
+			return fmt.Errorf("unknown option %q (expected %s)", name, strings.Join(options, ", "))
+		}
+	}
+	return nil
+}
+
+func openS3URL(u *url.URL) (backup.StorageUploader, string, error) {
+	if err := checkStorageURL(u, false, "region", "storage-class", "sse", "sse-kms-key-id", "acl", "part-size", "concurrency"); err != nil {
+		return nil, "", err
+	}
+	if u.Host == "" {
+		return nil, "", fmt.Errorf("a bucket is required: s3://bucket/prefix")
+	}
+
+	query := u.Query()
+	region := query.Get("region")
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	uploader := &s3.Uploader{
+		Region:       region,
+		Bucket:       u.Host,
+		StorageClass: query.Get("storage-class"),
+		SSE:          query.Get("sse"),
+		SSEKMSKeyID:  query.Get("sse-kms-key-id"),
+		ACL:          query.Get("acl"),
+	}
+	partSize, err := backup.ParseSize(query.Get("part-size"))
+	if err != nil {
+		return nil, "", err
+	}
+	uploader.PartSize = partSize
+	if value := query.Get("concurrency"); value != "" {
+		if uploader.Concurrency, err = strconv.Atoi(value); err != nil {
+			return nil, "", fmt.Errorf("invalid concurrency %q", value)
+		}
+	}
+	if err := uploader.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	return uploader, strings.Trim(u.Path, "/"), nil
+}
+
+func openGCSURL(u *url.URL) (backup.StorageUploader, string, error) {
+	if err := checkStorageURL(u, false, "project"); err != nil {
+		return nil, "", err
+	}
+	if u.Host == "" {
+		return nil, "", fmt.Errorf("a bucket is required: gs://bucket/prefix")
+	}
+	return &gcs.Uploader{ProjectID: u.Query().Get("project"), Bucket: u.Host}, strings.Trim(u.Path, "/"), nil
+}
+
+func openAzureURL(u *url.URL) (backup.StorageUploader, string, error) {
+	if err := checkStorageURL(u, false); err != nil {
+		return nil, "", err
+	}
+	container, prefix, _ := strings.Cut(strings.Trim(u.Path, "/"), "/")
+	if u.Host == "" || container == "" {
+		return nil, "", fmt.Errorf("an account and container are required: %s://account/container/prefix", u.Scheme)
+	}
+
+	accountKey := os.Getenv("AZURE_STORAGE_KEY")
+	logger.AddSecret(accountKey)
+	return &azure.Uploader{AccountName: u.Host, AccountKey: accountKey, Container: container}, prefix, nil
+}
+
+func openFileURL(u *url.URL) (backup.StorageUploader, string, error) {
+	if err := checkStorageURL(u, false); err != nil {
+		return nil, "", err
+	}
+	if u.Host != "" {
+		return nil, "", fmt.Errorf("a host is not supported; use file:///path")
+	}
+	return &local.Uploader{Directory: u.Path}, "", nil
+}
+
+func openSFTPURL(u *url.URL) (backup.StorageUploader, string, error) {
+	if err := checkStorageURL(u, true, "identity-file"); err != nil {
+		return nil, "", err
+	}
+	if u.Hostname() == "" {
+		return nil, "", fmt.Errorf("a host is required: sftp://user@host/path")
+	}
+
+	uploader := &sftp.Uploader{
+		Host:         u.Hostname(),
+		User:         u.User.Username(),
+		Directory:    u.Path,
+		IdentityFile: u.Query().Get("identity-file"),
+	}
+	if port := u.Port(); port != "" {
+		uploader.Port, _ = strconv.Atoi(port)
+	}
+	// sftp://host/~/backups addresses a directory relative to the home directory
+	if u.Path == "/~" || strings.HasPrefix(u.Path, "/~/") {
+		uploader.Directory = strings.TrimPrefix(strings.TrimPrefix(u.Path, "/~"), "/")
+	}
+	return uploader, "", nil
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
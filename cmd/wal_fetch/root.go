@@ -0,0 +1,38 @@
+package wal_fetch
+
+import (
+	"github.com/dbbackup-io/cli/cmd/shared"
+	"github.com/spf13/cobra"
+)
+
+var WalFetchCmd = &cobra.Command{
+	Use:   "wal-fetch",
+	Short: "Fetch an archived PostgreSQL WAL file",
+	Long: `Fetch an archived PostgreSQL WAL file from storage.
+Intended to be used as PostgreSQL's restore_command, e.g.:
+  restore_command = 'dbbackup wal-fetch s3 --bucket my-bucket --path prod %f %p'`,
+}
+
+func init() {
+	// Create storage source commands
+	WalFetchCmd.AddCommand(createWALFetchCommand("s3", "S3", shared.AddS3RestoreFlags))
+	WalFetchCmd.AddCommand(createWALFetchCommand("gcs", "Google Cloud Storage", shared.AddGCSRestoreFlags))
+	WalFetchCmd.AddCommand(createWALFetchCommand("azure", "Azure Blob Storage", shared.AddAzureRestoreFlags))
+	WalFetchCmd.AddCommand(createWALFetchCommand("local", "local storage", shared.AddLocalRestoreFlags))
+}
+
+func createWALFetchCommand(storageType, storageName string, addFlags func(cmd *cobra.Command)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   storageType + " <wal-file> <destination-path>",
+		Short: "Fetch an archived WAL file from " + storageName,
+		Long:  "Fetch an archived WAL file from " + storageName + " and write it to the destination path",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			shared.HandleWALFetch(cmd, args, storageType)
+		},
+	}
+
+	addFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,161 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaybeGunzip returns a reader that transparently decompresses gzip input. Other input is
+// passed through unchanged. The returned close function releases the decompressor.
+func MaybeGunzip(reader io.Reader) (io.Reader, func(), error) {
+	buffered := bufio.NewReader(reader)
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, func() {}, fmt.Errorf("failed to open gzip stream: %w", err)
+		}
+		return gz, func() { gz.Close() }, nil
+	}
+
+	return buffered, func() {}, nil
+}
+
+// WriteTarDirectory writes the regular files and directories below dir to a tar archive,
+// with entry names relative to dir
+func WriteTarDirectory(w io.Writer, dir string) error {
+	archive := tar.NewWriter(w)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relative, err := filepath.Rel(dir, path)
+		if err != nil || relative == "." {
+			return err
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relative)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(archive, file)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive %s: %w", dir, err)
+	}
+
+	return archive.Close()
+}
+
+// ExtractTar extracts the regular files and directories of a tar archive into dir,
+// rejecting entries that would escape it
+func ExtractTar(reader io.Reader, dir string) error {
+	root := filepath.Clean(dir)
+	tr := tar.NewReader(reader)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		target := filepath.Join(root, filepath.FromSlash(header.Name))
+		if target != root && !strings.HasPrefix(target, root+string(os.PathSeparator)) {
+			return fmt.Errorf("archive entry %q escapes the target directory", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to create %s: %w", target, err)
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+			}
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode).Perm())
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", target, err)
+			}
+			if _, err := io.Copy(file, tr); err != nil {
+				file.Close()
+				return fmt.Errorf("failed to write %s: %w", target, err)
+			}
+			if err := file.Close(); err != nil {
+				return fmt.Errorf("failed to write %s: %w", target, err)
+			}
+		}
+	}
+}
+
+// StreamTarDirectory streams dir as a tar archive. Closing the returned reader waits for
+// the archive to be written and then calls cleanup, which typically removes dir.
+func StreamTarDirectory(dir string, cleanup func()) io.ReadCloser {
+	pr, pw := io.Pipe()
+	reader := &tarDirectoryReader{
+		reader:  pr,
+		done:    make(chan error, 1),
+		cleanup: cleanup,
+	}
+
+	go func() {
+		err := WriteTarDirectory(pw, dir)
+		pw.CloseWithError(err)
+		reader.done <- err
+	}()
+
+	return reader
+}
+
+type tarDirectoryReader struct {
+	reader  *io.PipeReader
+	done    chan error
+	cleanup func()
+}
+
+func (tr *tarDirectoryReader) Read(p []byte) (int, error) {
+	return tr.reader.Read(p)
+}
+
+func (tr *tarDirectoryReader) Close() error {
+	tr.reader.Close()
+	defer tr.cleanup()
+
+	if err := <-tr.done; err != nil && err != io.ErrClosedPipe {
+		return err
+	}
+
+	return nil
+}
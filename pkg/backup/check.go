@@ -0,0 +1,82 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Check is the outcome of a single pre-flight check
+type Check struct {
+	Name    string
+	Detail  string
+	Err     error
+	Warning bool // a problem that does not prevent the backup, such as an unknown size
+}
+
+// Failed reports whether the check found a problem that would make the backup fail
+func (c Check) Failed() bool {
+	return c.Err != nil && !c.Warning
+}
+
+// Prober is implemented by dumpers that can verify a backup would succeed without running
+// it: the client tool is installed, the credentials work, the user has the privileges the
+// dump needs, and how much data there is
+type Prober interface {
+	Probe(ctx context.Context) []Check
+}
+
+// ToolCheck checks that a client tool can be run and reports its version
+func ToolCheck(ctx context.Context, path, binDirFlag string, args ...string) Check {
+	version, err := ToolVersion(ctx, path, binDirFlag, args...)
+	return Check{Name: "Client tool " + path, Detail: version, Err: err}
+}
+
+// SizeCheck reports an estimated database size. Failing to estimate it is only a warning.
+func SizeCheck(size int64, err error) Check {
+	if err != nil {
+		return Check{Name: "Size estimate", Err: err, Warning: true}
+	}
+	return Check{Name: "Size estimate", Detail: fmt.Sprintf("%.2f MB", float64(size)/1024/1024)}
+}
+
+// CheckStorage writes a small probe object under prefix, reads it back when the storage
+// can download, and deletes it again
+func CheckStorage(ctx context.Context, uploader StorageUploader, prefix string) []Check {
+	token := make([]byte, 8)
+	rand.Read(token)
+	key := JoinKey(prefix, ".dbbackup-check-"+hex.EncodeToString(token))
+	content := []byte("dbbackup check " + time.Now().UTC().Format(time.RFC3339) + "\n")
+
+	if _, err := uploader.Upload(ctx, key, bytes.NewReader(content)); err != nil {
+		return []Check{{Name: "Storage write", Detail: key, Err: err}}
+	}
+	checks := []Check{{Name: "Storage write", Detail: key}}
+
+	if downloader, ok := uploader.(StorageDownloader); ok {
+		var buf bytes.Buffer
+		check := Check{Name: "Storage read", Detail: key}
+		if err := downloader.Download(ctx, key, &buf); err != nil {
+			check.Err = err
+		} else if !bytes.Equal(buf.Bytes(), content) {
+			check.Err = fmt.Errorf("read back %d bytes that differ from the %d bytes written", buf.Len(), len(content))
+		}
+		checks = append(checks, check)
+	}
+
+	check := Check{Name: "Storage delete", Detail: key}
+	if deleter, ok := uploader.(StorageDeleter); ok {
+		if err := deleter.Delete(ctx, key); err != nil {
+			// Backups can still be written; only the probe object is left behind
+			check.Err = fmt.Errorf("%w (remove %s manually)", err, key)
+			check.Warning = true
+		}
+	} else {
+		check.Err = fmt.Errorf("%s storage cannot delete objects (remove %s manually)", uploader.GetStorageType(), key)
+		check.Warning = true
+	}
+	return append(checks, check)
+}
@@ -0,0 +1,234 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// DefaultCopyParallelism is how many backups are copied at the same time
+const DefaultCopyParallelism = 4
+
+// CopyOptions selects what CopyBackups copies
+type CopyOptions struct {
+	// SourcePrefix and DestinationPrefix are key prefixes, treated as directories
+	SourcePrefix      string
+	DestinationPrefix string
+
+	// OnlyMissing skips backups the destination already holds along with their manifest
+	OnlyMissing bool
+	Parallelism int
+	DryRun      bool
+}
+
+// CopyResult counts the outcome of CopyBackups
+type CopyResult struct {
+	Copied  int
+	Skipped int
+	Failed  int
+	Bytes   int64
+}
+
+// copyItem is an object to copy together with its manifest, if it has one
+type copyItem struct {
+	key         string
+	manifestKey string
+}
+
+// CopyBackups streams the backups under a prefix from one storage backend to another. Each
+// object with a manifest is verified against the manifest's size and SHA-256 before the
+// manifest is copied, so a manifest never points at an incomplete copy. Objects without a
+// manifest, such as WAL or binlog segments, are copied as they are.
+func CopyBackups(ctx context.Context, from StorageDownloader, to StorageUploader, opts CopyOptions) (CopyResult, error) {
+	var result CopyResult
+
+	sourceKeys, err := from.List(ctx, listPrefix(opts.SourcePrefix))
+	if err != nil {
+		return result, err
+	}
+	items := copyItems(sourceKeys)
+
+	existing := map[string]bool{}
+	if opts.OnlyMissing {
+		lister, ok := to.(StorageDownloader)
+		if !ok {
+			return result, fmt.Errorf("%s storage cannot list objects to find the missing ones", to.GetStorageType())
+		}
+		keys, err := lister.List(ctx, listPrefix(opts.DestinationPrefix))
+		if err != nil {
+			return result, err
+		}
+		for _, key := range keys {
+			existing[key] = true
+		}
+	}
+
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultCopyParallelism
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		copied  atomic.Int64
+		pending = make(chan copyItem)
+	)
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range pending {
+				outcome, size := copyBackup(ctx, from, to, item, opts, existing)
+				copied.Add(size)
+
+				mu.Lock()
+				switch outcome {
+				case copyCopied:
+					result.Copied++
+				case copySkipped:
+					result.Skipped++
+				case copyFailed:
+					result.Failed++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, item := range items {
+		pending <- item
+	}
+	close(pending)
+	wg.Wait()
+
+	result.Bytes = copied.Load()
+	if result.Failed > 0 {
+		return result, fmt.Errorf("%d of %d objects failed to copy", result.Failed, len(items))
+	}
+	return result, ctx.Err()
+}
+
+type copyOutcome int
+
+const (
+	copyCopied copyOutcome = iota
+	copySkipped
+	copyFailed
+)
+
+// copyBackup copies one object and its manifest, logging the outcome
+func copyBackup(ctx context.Context, from StorageDownloader, to StorageUploader, item copyItem, opts CopyOptions, existing map[string]bool) (copyOutcome, int64) {
+	destKey := destinationKey(item.key, opts)
+	if opts.OnlyMissing && existing[destKey] && (item.manifestKey == "" || existing[ManifestKey(destKey)]) {
+		logger.Debugf("Skipping %s: already at the destination", item.key)
+		return copySkipped, 0
+	}
+	if opts.DryRun {
+		logger.Infof("🧪 Would copy %s → %s", item.key, locate(to, destKey))
+		return copyCopied, 0
+	}
+	if err := ctx.Err(); err != nil {
+		return copyFailed, 0
+	}
+
+	var manifest *Manifest
+	if item.manifestKey != "" {
+		var err error
+		if manifest, err = ReadManifest(ctx, from, item.key); err != nil {
+			logger.Warnf("⚠️  Failed to copy %s: %v", item.key, err)
+			return copyFailed, 0
+		}
+	}
+
+	var expected int64
+	if manifest != nil {
+		expected = manifest.Size
+	}
+	size, sum, err := copyObject(ctx, from, to, item.key, destKey, expected)
+	if err == nil && manifest != nil && (size != manifest.Size || sum != manifest.SHA256) {
+		err = fmt.Errorf("copy does not match the manifest: %d bytes with SHA-256 %s, expected %d bytes with %s", size, sum, manifest.Size, manifest.SHA256)
+		if deleter, ok := to.(StorageDeleter); ok {
+			if deleteErr := deleter.Delete(ctx, destKey); deleteErr != nil {
+				logger.Warnf("⚠️  Failed to remove the bad copy %s: %v", destKey, deleteErr)
+			}
+		}
+	}
+	if err == nil && manifest != nil {
+		// The copy keeps the checksum and metadata but describes its own location
+		manifest.Key = destKey
+		manifest.StorageType = to.GetStorageType()
+		err = writeManifest(ctx, to, *manifest)
+	}
+	if err != nil {
+		logger.Warnf("⚠️  Failed to copy %s: %v", item.key, err)
+		return copyFailed, size
+	}
+
+	logger.Infof("📦 Copied %s → %s (%s)", item.key, locate(to, destKey), FormatBytes(size))
+	return copyCopied, size
+}
+
+// copyObject streams an object of the expected size (0 when unknown) between backends and
+// returns its size and SHA-256
+func copyObject(ctx context.Context, from StorageDownloader, to StorageUploader, key, destKey string, expected int64) (int64, string, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(from.Download(ctx, key, pw))
+	}()
+
+	hasher := sha256.New()
+	counter := &countingReader{reader: io.TeeReader(pr, hasher)}
+	_, err := uploadSized(ctx, to, destKey, counter, expected)
+	// Stops the download when the upload gave up early
+	pr.CloseWithError(err)
+	if err != nil {
+		return counter.count.Load(), "", err
+	}
+	return counter.count.Load(), hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// copyItems pairs listed keys with their manifests. Manifests whose backup is gone are left out.
+func copyItems(keys []string) []copyItem {
+	listed := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		listed[key] = true
+	}
+
+	var items []copyItem
+	for _, key := range keys {
+		if strings.HasSuffix(key, ManifestSuffix) {
+			if !listed[strings.TrimSuffix(key, ManifestSuffix)] {
+				logger.Warnf("⚠️  Skipping %s: the backup it describes is missing", key)
+			}
+			continue
+		}
+		item := copyItem{key: key}
+		if listed[ManifestKey(key)] {
+			item.manifestKey = ManifestKey(key)
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].key < items[j].key })
+	return items
+}
+
+// listPrefix turns a directory-like prefix into a listing prefix, so "prod" does not match "prod2/..."
+func listPrefix(prefix string) string {
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		return prefix + "/"
+	}
+	return ""
+}
+
+// destinationKey maps a source key to its key under the destination prefix
+func destinationKey(key string, opts CopyOptions) string {
+	return JoinKey(opts.DestinationPrefix, strings.TrimPrefix(key, listPrefix(opts.SourcePrefix)))
+}
@@ -0,0 +1,65 @@
+package backup
+
+import (
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// CommandDescriber is implemented by dumpers that can show what a backup would run without
+// connecting to the database. Each line is a command or a step of the dump.
+type CommandDescriber interface {
+	DescribeCommand() ([]string, error)
+}
+
+// StorageLocator is implemented by storage backends that can name the location of a key,
+// e.g. s3://bucket/key
+type StorageLocator interface {
+	URL(key string) string
+}
+
+// UploadDescriber is implemented by storage backends with upload options worth showing,
+// e.g. the S3 part size or storage class
+type UploadDescriber interface {
+	DescribeUpload() []string
+}
+
+var shellSafePattern = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// FormatCommand renders a command for display with secrets redacted. Only the environment
+// variables the command adds to the current environment are shown, before the command line.
+func FormatCommand(cmd *exec.Cmd) string {
+	inherited := map[string]bool{}
+	for _, entry := range os.Environ() {
+		inherited[entry] = true
+	}
+
+	var parts []string
+	for _, entry := range cmd.Env {
+		if inherited[entry] {
+			continue
+		}
+		name, value, _ := strings.Cut(entry, "=")
+		if logger.IsSecretName(name) {
+			value = logger.Redacted
+		}
+		parts = append(parts, name+"="+shellQuote(logger.Redact(value)))
+	}
+
+	for _, arg := range cmd.Args {
+		parts = append(parts, shellQuote(logger.Redact(arg)))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// shellQuote single-quotes an argument unless it only contains characters that are safe in a shell
+func shellQuote(arg string) string {
+	if shellSafePattern.MatchString(arg) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,189 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Destination policies decide whether a backup written to several destinations succeeded
+const (
+	// DestinationPolicyAll fails the backup when any destination fails, and stops the others
+	DestinationPolicyAll = "all"
+	// DestinationPolicyAny succeeds when at least one destination received the backup
+	DestinationPolicyAny = "any"
+)
+
+var (
+	errUploadReturnedEarly = errors.New("upload finished before the end of the stream")
+	errDestinationStopped  = errors.New("stopped after another destination failed")
+)
+
+// fanOutChunkSize is how much of the dump stream is read before it is passed to every destination
+const fanOutChunkSize = 256 * 1024
+
+// DestinationResult is the outcome of a backup for one destination
+type DestinationResult struct {
+	StorageType string
+	Location    string // URL of the backup, or the key when the storage cannot name it
+	Size        int64
+	Err         error
+}
+
+// ValidateDestinationPolicy checks a --destination-policy value
+func ValidateDestinationPolicy(policy string) error {
+	switch policy {
+	case "", DestinationPolicyAll, DestinationPolicyAny:
+		return nil
+	}
+	return fmt.Errorf("invalid destination policy %q (expected %s or %s)", policy, DestinationPolicyAll, DestinationPolicyAny)
+}
+
+// fanOutTarget is one upload fed by fanOut
+type fanOutTarget struct {
+	writer *io.PipeWriter
+	failed bool
+	result DestinationResult
+}
+
+// fanOut uploads the stream read from reader to every destination concurrently, as name under
+// each destination's prefix. size is the expected size of the stream, or 0 when unknown.
+// Each chunk is handed to all destinations still running, so the slowest one sets the pace.
+// A destination that fails is dropped; under DestinationPolicyAll it also cancels the others.
+func fanOut(ctx context.Context, reader io.Reader, destinations []Destination, name string, size int64, policy string) []DestinationResult {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	targets := make([]*fanOutTarget, len(destinations))
+	var (
+		wg          sync.WaitGroup
+		failureOnce sync.Once
+		firstFailed = -1 // the destination whose failure stopped the others
+	)
+	for i, destination := range destinations {
+		uploader, key := destination.Uploader, destination.Key(name)
+		pr, pw := io.Pipe()
+		dest := &fanOutTarget{
+			writer: pw,
+			result: DestinationResult{StorageType: uploader.GetStorageType(), Location: locate(uploader, key)},
+		}
+		targets[i] = dest
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			uploaded, err := uploadSized(ctx, uploader, key, pr, size)
+			dest.result.Size, dest.result.Err = uploaded, err
+			if err == nil {
+				// Fails further writes if the upload returned before the end of the stream
+				err = errUploadReturnedEarly
+			} else if policy != DestinationPolicyAny {
+				failureOnce.Do(func() {
+					firstFailed = i
+					cancel()
+				})
+			}
+			pr.CloseWithError(err)
+		}()
+	}
+
+	readErr := feed(reader, targets, policy)
+	for _, dest := range targets {
+		// Failing the pipe on a read error makes each upload discard what it received, so a
+		// truncated dump is not stored as complete
+		dest.writer.CloseWithError(readErr)
+	}
+	wg.Wait()
+
+	results := make([]DestinationResult, len(targets))
+	for i, dest := range targets {
+		results[i] = dest.result
+		switch {
+		case firstFailed >= 0 && i != firstFailed:
+			// Errors of the other destinations follow from being stopped
+			results[i].Err = errDestinationStopped
+		case results[i].Err != nil:
+		case dest.failed:
+			results[i].Err = errUploadReturnedEarly
+		case readErr != nil:
+			results[i].Err = readErr
+		}
+	}
+	return results
+}
+
+// feed copies the stream to the destinations until it ends or none is left to write to
+func feed(reader io.Reader, targets []*fanOutTarget, policy string) error {
+	buf := make([]byte, fanOutChunkSize)
+	for {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			live := 0
+			for _, dest := range targets {
+				if dest.failed {
+					continue
+				}
+				if _, writeErr := dest.writer.Write(buf[:n]); writeErr != nil {
+					dest.failed = true
+					continue
+				}
+				live++
+			}
+			if live == 0 || (live < len(targets) && policy != DestinationPolicyAny) {
+				return errors.New("backup stream abandoned after a destination failed")
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// uploadSized uploads an object, passing its expected size to uploaders that use it
+func uploadSized(ctx context.Context, uploader StorageUploader, key string, reader io.Reader, size int64) (int64, error) {
+	if sized, ok := uploader.(SizedUploader); ok && size > 0 {
+		return sized.UploadSized(ctx, key, reader, size)
+	}
+	return uploader.Upload(ctx, key, reader)
+}
+
+// locate names where a key is stored on a destination
+func locate(uploader StorageUploader, key string) string {
+	if locator, ok := uploader.(StorageLocator); ok {
+		return locator.URL(key)
+	}
+	return key
+}
+
+// destinationsError summarizes failed destinations as one error, applying the policy.
+// It returns nil when the backup counts as successful.
+func destinationsError(results []DestinationResult, policy string) error {
+	var failed []string
+	stopped := 0
+	for _, result := range results {
+		switch {
+		case errors.Is(result.Err, errDestinationStopped):
+			stopped++
+		case result.Err != nil:
+			failed = append(failed, fmt.Sprintf("%s: %v", result.StorageType, result.Err))
+		}
+	}
+
+	switch {
+	case len(failed) == 0:
+		return nil
+	case len(results) == 1:
+		return results[0].Err
+	case policy == DestinationPolicyAny && len(failed) < len(results):
+		return nil
+	case stopped > 0:
+		return fmt.Errorf("%s (the other destinations were stopped)", strings.Join(failed, "; "))
+	}
+	return fmt.Errorf("%d of %d destinations failed: %s", len(failed), len(results), strings.Join(failed, "; "))
+}
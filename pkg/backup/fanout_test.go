@@ -0,0 +1,192 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// memUploader keeps every upload in memory
+type memUploader struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (u *memUploader) Upload(ctx context.Context, key string, reader io.Reader) (int64, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return int64(len(data)), err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.objects == nil {
+		u.objects = map[string][]byte{}
+	}
+	u.objects[key] = data
+	return int64(len(data)), nil
+}
+
+func (u *memUploader) GetStorageType() string { return "mem" }
+
+// partialUploader reads limit bytes and then fails with err, or returns early when err is nil
+type partialUploader struct {
+	limit int64
+	err   error
+}
+
+func (u *partialUploader) Upload(ctx context.Context, key string, reader io.Reader) (int64, error) {
+	n, _ := io.CopyN(io.Discard, reader, u.limit)
+	return n, u.err
+}
+
+func (u *partialUploader) GetStorageType() string { return "partial" }
+
+// failingReader returns data and then fails
+func failingReader(data []byte, err error) io.Reader {
+	return io.MultiReader(bytes.NewReader(data), readerFunc(func([]byte) (int, error) { return 0, err }))
+}
+
+type readerFunc func([]byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) { return f(p) }
+
+func testData(size int) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(1)).Read(data)
+	return data
+}
+
+func TestFanOutAllSucceed(t *testing.T) {
+	data := testData(5*fanOutChunkSize + 123)
+	first, second := &memUploader{}, &memUploader{}
+	destinations := []Destination{{Uploader: first, PathPrefix: "daily"}, {Uploader: second}}
+
+	results := fanOut(context.Background(), bytes.NewReader(data), destinations, "backup.gz", 0, DestinationPolicyAll)
+
+	for i, result := range results {
+		if result.Err != nil {
+			t.Errorf("destination %d failed: %v", i, result.Err)
+		}
+		if result.Size != int64(len(data)) {
+			t.Errorf("destination %d size = %d, want %d", i, result.Size, len(data))
+		}
+	}
+	if !bytes.Equal(first.objects["daily/backup.gz"], data) || !bytes.Equal(second.objects["backup.gz"], data) {
+		t.Error("destinations did not receive identical copies of the stream under their prefixes")
+	}
+	if err := destinationsError(results, DestinationPolicyAll); err != nil {
+		t.Errorf("destinationsError() = %v, want nil", err)
+	}
+}
+
+func TestFanOutFailure(t *testing.T) {
+	data := testData(8 * fanOutChunkSize)
+	uploadErr := errors.New("bucket not found")
+
+	tests := []struct {
+		policy      string
+		wantOthers  error // the error of the destinations that did not fail, nil if they succeed
+		wantErr     string
+		wantSuccess bool
+	}{
+		{policy: DestinationPolicyAll, wantOthers: errDestinationStopped, wantErr: "partial: bucket not found (the other destinations were stopped)"},
+		{policy: "", wantOthers: errDestinationStopped, wantErr: "the other destinations were stopped"},
+		{policy: DestinationPolicyAny, wantSuccess: true},
+	}
+
+	for _, tt := range tests {
+		t.Run("policy "+tt.policy, func(t *testing.T) {
+			first, last := &memUploader{}, &memUploader{}
+			destinations := []Destination{
+				{Uploader: first},
+				{Uploader: &partialUploader{limit: fanOutChunkSize, err: uploadErr}},
+				{Uploader: last},
+			}
+
+			results := fanOut(context.Background(), bytes.NewReader(data), destinations, "backup.gz", 0, tt.policy)
+
+			if !errors.Is(results[1].Err, uploadErr) {
+				t.Errorf("failed destination error = %v, want %v", results[1].Err, uploadErr)
+			}
+			for _, i := range []int{0, 2} {
+				if !errors.Is(results[i].Err, tt.wantOthers) {
+					t.Errorf("destination %d error = %v, want %v", i, results[i].Err, tt.wantOthers)
+				}
+			}
+			if tt.wantOthers == nil && (!bytes.Equal(first.objects["backup.gz"], data) || !bytes.Equal(last.objects["backup.gz"], data)) {
+				t.Error("remaining destinations did not receive the whole stream")
+			}
+			if tt.wantOthers != nil && (len(first.objects) > 0 || len(last.objects) > 0) {
+				t.Error("a stopped destination stored the backup")
+			}
+
+			err := destinationsError(results, tt.policy)
+			if tt.wantSuccess {
+				if err != nil {
+					t.Errorf("destinationsError() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("destinationsError() = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFanOutUploadReturnsEarly(t *testing.T) {
+	data := testData(4 * fanOutChunkSize)
+	complete := &memUploader{}
+	destinations := []Destination{
+		{Uploader: &partialUploader{limit: fanOutChunkSize}},
+		{Uploader: complete},
+	}
+
+	results := fanOut(context.Background(), bytes.NewReader(data), destinations, "backup.gz", 0, DestinationPolicyAny)
+
+	if !errors.Is(results[0].Err, errUploadReturnedEarly) {
+		t.Errorf("early upload error = %v, want %v", results[0].Err, errUploadReturnedEarly)
+	}
+	if results[1].Err != nil || !bytes.Equal(complete.objects["backup.gz"], data) {
+		t.Errorf("complete destination error = %v, want the whole stream stored", results[1].Err)
+	}
+
+	// A single destination returning early fails the backup
+	results = fanOut(context.Background(), bytes.NewReader(data), destinations[:1], "backup.gz", 0, DestinationPolicyAll)
+	if err := destinationsError(results, DestinationPolicyAll); !errors.Is(err, errUploadReturnedEarly) {
+		t.Errorf("destinationsError() = %v, want %v", err, errUploadReturnedEarly)
+	}
+}
+
+func TestFanOutReadError(t *testing.T) {
+	readErr := errors.New("pg_dump exited with status 1")
+	first, second := &memUploader{}, &memUploader{}
+	destinations := []Destination{{Uploader: first}, {Uploader: second}}
+
+	reader := failingReader(testData(3*fanOutChunkSize), readErr)
+	results := fanOut(context.Background(), reader, destinations, "backup.gz", 0, DestinationPolicyAny)
+
+	for i, result := range results {
+		if !errors.Is(result.Err, readErr) {
+			t.Errorf("destination %d error = %v, want the read error", i, result.Err)
+		}
+	}
+	if len(first.objects) > 0 || len(second.objects) > 0 {
+		t.Error("a truncated stream was stored as complete")
+	}
+	if err := destinationsError(results, DestinationPolicyAny); err == nil || !strings.Contains(err.Error(), readErr.Error()) {
+		t.Errorf("destinationsError() = %v, want it to report the read error", err)
+	}
+}
+
+func TestExecuteWithoutDestinations(t *testing.T) {
+	executor := &BackupExecutor{Config: BackupConfig{Compression: "none"}}
+	if err := executor.Execute(context.Background()); err == nil {
+		t.Fatal("Execute() without destinations succeeded")
+	}
+}
@@ -2,7 +2,14 @@ package backup
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"io"
+	"strings"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/logger"
 )
 
 // DatabaseDumper interface for database backup sources
@@ -12,34 +19,138 @@ type DatabaseDumper interface {
 	GetDatabaseType() string
 }
 
+// Dump engines: the database's client tools run as subprocesses, or a pure-Go implementation
+const (
+	EngineCLI    = "cli"
+	EngineNative = "native"
+)
+
 // StorageUploader interface for storage destinations
 type StorageUploader interface {
 	Upload(ctx context.Context, key string, reader io.Reader) (int64, error)
 	GetStorageType() string
 }
 
+// StorageDownloader interface for storage backends that can read backups back
+type StorageDownloader interface {
+	Download(ctx context.Context, key string, writer io.Writer) error
+	List(ctx context.Context, prefix string) ([]string, error)
+	GetStorageType() string
+}
+
+// SizedUploader is implemented by storage uploaders that tune an upload to the expected
+// object size
+type SizedUploader interface {
+	UploadSized(ctx context.Context, key string, reader io.Reader, size int64) (int64, error)
+}
+
+// AvailabilityChecker is implemented by storage backends that may not be usable in this build
+type AvailabilityChecker interface {
+	CheckAvailable() error
+}
+
+// StorageAvailable returns an error when a storage backend cannot be used
+func StorageAvailable(storage any) error {
+	if checker, ok := storage.(AvailabilityChecker); ok {
+		return checker.CheckAvailable()
+	}
+	return nil
+}
+
+// StorageDeleter interface for storage backends that can remove objects
+type StorageDeleter interface {
+	Delete(ctx context.Context, key string) error
+}
+
 // BackupConfig holds configuration for a backup operation
 type BackupConfig struct {
 	DatabaseType string
 	DatabaseName string
 	Compression  string
 	PathPrefix   string
+
+	// KeyTemplate names the backup object (default: DefaultKeyTemplate); the file extension
+	// is appended. Host and JobName are available to the template.
+	KeyTemplate string
+	Host        string
+	JobName     string
+
+	// Progress is one of the Progress* modes (default: ProgressAuto); ProgressInterval is how
+	// often progress is logged when it is not drawn as a bar
+	Progress         string
+	ProgressInterval time.Duration
+
+	// MaxUploadRate limits the upload in bytes per second; 0 is unlimited
+	MaxUploadRate int64
+
+	// DestinationPolicy is DestinationPolicyAll (default) or DestinationPolicyAny
+	DestinationPolicy string
+}
+
+// Destination is a storage backend together with the key prefix backups are written under
+type Destination struct {
+	Uploader   StorageUploader
+	PathPrefix string
+}
+
+// Key returns the key of a backup object under the destination's prefix
+func (d Destination) Key(name string) string {
+	if d.PathPrefix != "" {
+		return d.PathPrefix + "/" + name
+	}
+	return name
 }
 
 // BackupExecutor coordinates the backup process
 type BackupExecutor struct {
-	Dumper   DatabaseDumper
+	Dumper DatabaseDumper
+	// Uploader stores the backup under Config.PathPrefix
 	Uploader StorageUploader
-	Config   BackupConfig
+	// Destinations receive the same dump stream as Uploader, each under its own prefix, so
+	// one dump is stored on several backends
+	Destinations []Destination
+	Config       BackupConfig
+
+	// Results holds the outcome for each destination once Execute returns
+	Results []DestinationResult
+}
+
+// AllDestinations returns Uploader, under Config.PathPrefix, followed by Destinations
+func (be *BackupExecutor) AllDestinations() []Destination {
+	var destinations []Destination
+	if be.Uploader != nil {
+		destinations = append(destinations, Destination{Uploader: be.Uploader, PathPrefix: be.Config.PathPrefix})
+	}
+	return append(destinations, be.Destinations...)
+}
+
+// ObjectName returns the name of a backup started now, before any destination prefix
+func (be *BackupExecutor) ObjectName() (string, error) {
+	return generateBackupFilename(be.Config, be.Dumper, time.Now())
 }
 
 func (be *BackupExecutor) Execute(ctx context.Context) error {
-	// Generate filename
-	filename := generateBackupFilename(be.Config, be.Dumper)
+	destinations := be.AllDestinations()
+	if len(destinations) == 0 {
+		return errors.New("no storage destination configured")
+	}
+	name, err := be.ObjectName()
+	if err != nil {
+		return err
+	}
 
-	fullPath := filename
-	if be.Config.PathPrefix != "" {
-		fullPath = be.Config.PathPrefix + "/" + filename
+	// The estimated size drives the progress report and the uploaders' part sizes
+	sized := false
+	for _, destination := range destinations {
+		if _, ok := destination.Uploader.(SizedUploader); ok {
+			sized = true
+		}
+	}
+	var total int64
+	if be.Config.Progress != ProgressNone || sized {
+		if total = estimateSize(ctx, be.Dumper); total > 0 {
+			logger.Infof("📏 Estimated size: %s", FormatBytes(total))
+		}
 	}
 
 	// Create backup stream
@@ -48,16 +159,18 @@ func (be *BackupExecutor) Execute(ctx context.Context) error {
 		return err
 	}
 
-	var closeErr error
-	defer func() {
-		if err := reader.Close(); err != nil {
-			closeErr = err
-		}
-	}()
+	// Count and checksum the stream on its way to storage
+	hasher := sha256.New()
+	counter := &countingReader{reader: io.TeeReader(reader, hasher)}
 
-	// Upload to storage
-	size, err := be.Uploader.Upload(ctx, fullPath, reader)
-	if err != nil {
+	// Upload to every destination, reporting progress as the stream is consumed
+	stopProgress := startProgress(counter, be.Config.Progress, be.Config.ProgressInterval, total)
+	be.Results = fanOut(ctx, newRateLimitedReader(ctx, counter, be.Config.MaxUploadRate), destinations, name, total, be.Config.DestinationPolicy)
+	stopProgress()
+
+	// Close the stream to wait for the dump process before inspecting results
+	closeErr := reader.Close()
+	if err := destinationsError(be.Results, be.Config.DestinationPolicy); err != nil {
 		return err
 	}
 
@@ -66,7 +179,45 @@ func (be *BackupExecutor) Execute(ctx context.Context) error {
 		return closeErr
 	}
 
+	manifest := Manifest{
+		DatabaseType: be.Dumper.GetDatabaseType(),
+		DatabaseName: be.Config.DatabaseName,
+		Size:         counter.count.Load(),
+		SHA256:       hex.EncodeToString(hasher.Sum(nil)),
+		CreatedAt:    time.Now().UTC(),
+	}
+	if provider, ok := be.Dumper.(MetadataProvider); ok {
+		manifest.Metadata = provider.GetBackupMetadata()
+	}
+
+	// Each destination that holds the backup gets its own manifest
+	var stored []string
+	for i, destination := range destinations {
+		result := &be.Results[i]
+		if result.Err != nil {
+			logger.Warnf("⚠️  Backup to %s failed: %v", result.StorageType, result.Err)
+			continue
+		}
+		manifest.Key = destination.Key(name)
+		manifest.StorageType = destination.Uploader.GetStorageType()
+		if result.Err = writeManifest(ctx, destination.Uploader, manifest); result.Err != nil {
+			logger.Warnf("⚠️  Backup to %s failed: %v", result.StorageType, result.Err)
+			continue
+		}
+		stored = append(stored, result.StorageType)
+	}
+	if err := destinationsError(be.Results, be.Config.DestinationPolicy); err != nil {
+		return err
+	}
+
 	// Log success
-	logBackupSuccess(fullPath, size, be.Dumper.GetDatabaseType(), be.Uploader.GetStorageType())
+	logBackupSuccess(destinations[0].Key(name), counter.count.Load(), be.Dumper.GetDatabaseType(), strings.Join(stored, ", "))
+	if len(destinations) > 1 {
+		for _, result := range be.Results {
+			if result.Err == nil {
+				logger.Infof("   → %s", result.Location)
+			}
+		}
+	}
 	return nil
 }
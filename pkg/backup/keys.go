@@ -0,0 +1,89 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultKeyTemplate names backups <type>_<db>_<YYYYMMDD_HHMMSS> in UTC
+const DefaultKeyTemplate = `{{.DBType}}_{{.DBName}}_{{.Time.UTC | date "20060102_150405"}}`
+
+// KeyData holds the variables available to object key templates
+type KeyData struct {
+	DBType   string
+	DBName   string
+	Host     string // database host
+	Hostname string // machine running the backup
+	JobName  string
+	Time     time.Time
+}
+
+var keyTemplateFuncs = template.FuncMap{
+	// date formats a time with a Go layout, e.g. {{.Time.UTC | date "2006/01/02"}}
+	"date": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+}
+
+// ParseKeyTemplate parses an object key template, using DefaultKeyTemplate when text is empty
+func ParseKeyTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		text = DefaultKeyTemplate
+	}
+	tmpl, err := template.New("key").Funcs(keyTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderKey renders an object key template. Empty path segments are dropped; the key must
+// not be empty or contain "." or ".." segments.
+func RenderKey(text string, data KeyData) (string, error) {
+	tmpl, err := ParseKeyTemplate(text)
+	if err != nil {
+		return "", err
+	}
+
+	var key strings.Builder
+	if err := tmpl.Execute(&key, data); err != nil {
+		return "", fmt.Errorf("invalid key template: %w", err)
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(key.String(), "/") {
+		switch strings.TrimSpace(segment) {
+		case "":
+			continue
+		case ".", "..":
+			return "", fmt.Errorf("key template rendered %q, which contains a %q segment", key.String(), segment)
+		}
+		segments = append(segments, segment)
+	}
+	if len(segments) == 0 {
+		return "", fmt.Errorf("key template rendered an empty key")
+	}
+
+	return strings.Join(segments, "/"), nil
+}
+
+// keyData returns the template variables for a backup started at now
+func keyData(config BackupConfig, now time.Time) KeyData {
+	dbName := config.DatabaseName
+	if dbName == "" {
+		dbName = "default"
+	}
+	hostname, _ := os.Hostname()
+
+	return KeyData{
+		DBType:   config.DatabaseType,
+		DBName:   dbName,
+		Host:     config.Host,
+		Hostname: hostname,
+		JobName:  config.JobName,
+		Time:     now,
+	}
+}
@@ -0,0 +1,63 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ManifestSuffix is appended to a backup key to form the key of its manifest
+const ManifestSuffix = ".manifest.json"
+
+// Manifest describes a completed backup and is stored next to it
+type Manifest struct {
+	Key          string            `json:"key"`
+	DatabaseType string            `json:"database_type"`
+	DatabaseName string            `json:"database_name"`
+	StorageType  string            `json:"storage_type"`
+	Size         int64             `json:"size"`
+	SHA256       string            `json:"sha256"`
+	CreatedAt    time.Time         `json:"created_at"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+}
+
+// MetadataProvider is implemented by dumpers that record extra details about a backup,
+// such as replication coordinates. It is consulted after the backup stream is closed.
+type MetadataProvider interface {
+	GetBackupMetadata() map[string]string
+}
+
+// ManifestKey returns the manifest key for a backup key
+func ManifestKey(key string) string {
+	return key + ManifestSuffix
+}
+
+// ReadManifest downloads and decodes the manifest of a backup
+func ReadManifest(ctx context.Context, downloader StorageDownloader, key string) (*Manifest, error) {
+	var buf bytes.Buffer
+	if err := downloader.Download(ctx, ManifestKey(key), &buf); err != nil {
+		return nil, fmt.Errorf("failed to download manifest for %s: %w", key, err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(buf.Bytes(), &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest for %s: %w", key, err)
+	}
+
+	return &manifest, nil
+}
+
+func writeManifest(ctx context.Context, uploader StorageUploader, manifest Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	if _, err := uploader.Upload(ctx, ManifestKey(manifest.Key), bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to upload manifest: %w", err)
+	}
+
+	return nil
+}
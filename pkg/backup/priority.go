@@ -0,0 +1,197 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// I/O scheduling classes, as named by ionice(1)
+const (
+	IOClassRealtime   = "realtime"
+	IOClassBestEffort = "best-effort"
+	IOClassIdle       = "idle"
+)
+
+// ProcessPriority lowers the scheduling priority of the dump tools started by the sources
+type ProcessPriority struct {
+	// Nice is the CPU niceness, from -20 (highest priority) to 19 (lowest); 0 keeps the default
+	Nice int
+	// IOClass is one of the IOClass* classes; empty keeps the default. IOLevel, from 0
+	// (highest) to 7 (lowest), applies to the realtime and best-effort classes.
+	IOClass string
+	IOLevel int
+}
+
+var processPriority ProcessPriority
+
+// SetProcessPriority sets the priority applied by StartCommand to the processes started after it
+func SetProcessPriority(priority ProcessPriority) error {
+	if priority.Nice < -20 || priority.Nice > 19 {
+		return fmt.Errorf("invalid nice value %d (expected -20 to 19)", priority.Nice)
+	}
+	if priority.IOLevel < 0 || priority.IOLevel > 7 {
+		return fmt.Errorf("invalid I/O priority level %d (expected 0 to 7)", priority.IOLevel)
+	}
+	switch priority.IOClass {
+	case "", IOClassRealtime, IOClassBestEffort, IOClassIdle:
+	default:
+		return fmt.Errorf("invalid I/O class %q (expected %s, %s or %s)", priority.IOClass, IOClassIdle, IOClassBestEffort, IOClassRealtime)
+	}
+
+	processPriority = priority
+	return nil
+}
+
+// CurrentProcessPriority returns the priority set by SetProcessPriority
+func CurrentProcessPriority() ProcessPriority {
+	return processPriority
+}
+
+// ParseIONice parses an ionice-style priority: a class name or number (1 realtime,
+// 2 best-effort, 3 idle), optionally followed by :LEVEL, e.g. best-effort:7 or idle
+func ParseIONice(value string) (string, int, error) {
+	if value == "" {
+		return "", 0, nil
+	}
+
+	class, levelText, hasLevel := strings.Cut(strings.ToLower(value), ":")
+	switch class {
+	case "1", IOClassRealtime:
+		class = IOClassRealtime
+	case "2", IOClassBestEffort, "best_effort", "be":
+		class = IOClassBestEffort
+	case "3", IOClassIdle:
+		class = IOClassIdle
+	default:
+		return "", 0, fmt.Errorf("invalid I/O class %q (expected %s, %s or %s)", value, IOClassIdle, IOClassBestEffort, IOClassRealtime)
+	}
+
+	// ionice's default level for the best-effort class
+	level := 4
+	if hasLevel {
+		var err error
+		if level, err = strconv.Atoi(levelText); err != nil || level < 0 || level > 7 {
+			return "", 0, fmt.Errorf("invalid I/O priority level %q (expected 0 to 7)", levelText)
+		}
+	}
+	return class, level, nil
+}
+
+// Describe returns the priority in words, or "" for the default priority
+func (p ProcessPriority) Describe() string {
+	var parts []string
+	if p.Nice != 0 {
+		parts = append(parts, fmt.Sprintf("nice %d", p.Nice))
+	}
+	switch p.IOClass {
+	case "":
+	case IOClassIdle:
+		parts = append(parts, "I/O class idle")
+	default:
+		parts = append(parts, fmt.Sprintf("I/O class %s level %d", p.IOClass, p.IOLevel))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// StartCommand starts a dump tool with the process priority. The tool is launched through
+// nice and ionice where they are installed, so it and every thread it creates inherit the
+// priority from the start. Otherwise the priority is applied to the process after it
+// started, which misses threads the tool creates in the meantime. A priority that cannot be
+// applied, e.g. a negative nice value without privileges, is logged as a warning.
+func StartCommand(cmd *exec.Cmd) error {
+	name := cmd.Args[0]
+	remaining := processPriority
+	if remaining != (ProcessPriority{}) && cmd.Err == nil {
+		remaining = wrapCommand(cmd, remaining)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	if remaining != (ProcessPriority{}) {
+		if err := setPriority(cmd.Process.Pid, remaining); err != nil {
+			logger.Warnf("⚠️  Could not lower the priority of %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// wrapCommand prefixes a command with nice and ionice for the parts of the priority they
+// are installed for, and returns the parts left to apply after the command started
+func wrapCommand(cmd *exec.Cmd, priority ProcessPriority) ProcessPriority {
+	var prefix []string
+	if priority.IOClass != "" && runtime.GOOS == "linux" {
+		if ionice, err := exec.LookPath("ionice"); err == nil {
+			args := []string{"-c", strconv.Itoa(ioniceClasses[priority.IOClass])}
+			if priority.IOClass != IOClassIdle {
+				args = append(args, "-n", strconv.Itoa(priority.IOLevel))
+			}
+			if err := checkIONice(ionice, args); err != nil {
+				logger.Warnf("⚠️  Could not set the I/O priority of %s: %v", cmd.Args[0], err)
+			}
+			// -t runs the tool even when the I/O priority cannot be set, e.g. the realtime
+			// class without CAP_SYS_NICE
+			prefix = append(append(prefix, ionice, "-t"), args...)
+			priority.IOClass, priority.IOLevel = "", 0
+		}
+	}
+	if priority.Nice != 0 {
+		if nice, err := exec.LookPath("nice"); err == nil {
+			prefix = append(prefix, nice, "-n", strconv.Itoa(priority.Nice))
+			priority.Nice = 0
+		}
+	}
+	if len(prefix) == 0 {
+		return priority
+	}
+
+	// The wrappers exec the tool by its resolved path, so it runs as the same process
+	cmd.Args = append(append(prefix, cmd.Path), cmd.Args[1:]...)
+	cmd.Path = prefix[0]
+	return priority
+}
+
+// checkIONice reports whether ionice can set an I/O priority, which it silently ignores
+// when run with -t
+func checkIONice(ionice string, args []string) error {
+	probe, err := exec.LookPath("true")
+	if err != nil {
+		return nil
+	}
+	output, err := exec.Command(ionice, append(args, probe)...).CombinedOutput()
+	if err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return errors.New(message)
+		}
+		return err
+	}
+	return nil
+}
+
+// ioniceClasses maps I/O classes to the class numbers of ionice -c
+var ioniceClasses = map[string]int{
+	IOClassRealtime:   1,
+	IOClassBestEffort: 2,
+	IOClassIdle:       3,
+}
+
+// CombinedOutput runs a dump tool like exec.Cmd.CombinedOutput, with the process priority applied
+func CombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	if err := StartCommand(cmd); err != nil {
+		return nil, err
+	}
+	err := cmd.Wait()
+	return output.Bytes(), err
+}
@@ -0,0 +1,30 @@
+package backup
+
+import "syscall"
+
+// ioprio_set(2) constants
+const (
+	ioprioWhoProcess = 1
+	ioprioClassShift = 13
+)
+
+func setPriority(pid int, priority ProcessPriority) error {
+	if priority.Nice != 0 {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, pid, priority.Nice); err != nil {
+			return err
+		}
+	}
+
+	if priority.IOClass != "" {
+		level := priority.IOLevel
+		if priority.IOClass == IOClassIdle {
+			level = 0
+		}
+		value := ioniceClasses[priority.IOClass]<<ioprioClassShift | level
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(pid), uintptr(value)); errno != 0 {
+			return errno
+		}
+	}
+
+	return nil
+}
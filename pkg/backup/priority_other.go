@@ -0,0 +1,9 @@
+//go:build !unix
+
+package backup
+
+import "fmt"
+
+func setPriority(pid int, priority ProcessPriority) error {
+	return fmt.Errorf("process priorities are not supported on this platform")
+}
@@ -0,0 +1,57 @@
+package backup
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestStartCommandIONiceFails(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("dump tools are only started through ionice on Linux")
+	}
+
+	// Behaves like ionice when the kernel refuses the I/O class: it fails unless -t is given
+	dir := t.TempDir()
+	stub := `#!/bin/sh
+if [ "$1" != "-t" ]; then
+	echo "ionice: ioprio_set failed: Operation not permitted" >&2
+	exit 1
+fi
+shift
+while [ "$1" = "-c" ] || [ "$1" = "-n" ]; do shift 2; done
+exec "$@"
+`
+	if err := os.WriteFile(filepath.Join(dir, "ionice"), []byte(stub), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	previous := processPriority
+	t.Cleanup(func() { processPriority = previous })
+	if err := SetProcessPriority(ProcessPriority{IOClass: IOClassRealtime, IOLevel: 4}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("echo", "dumped")
+	output, err := CombinedOutput(cmd)
+	if err != nil {
+		t.Fatalf("the dump tool did not run: %v: %s", err, output)
+	}
+	if strings.TrimSpace(string(output)) != "dumped" {
+		t.Errorf("output = %q, want the dump tool's output", output)
+	}
+	if !strings.Contains(logs.String(), "Operation not permitted") {
+		t.Errorf("logs = %q, want a warning that the I/O priority was not set", logs.String())
+	}
+}
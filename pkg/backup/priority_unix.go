@@ -0,0 +1,21 @@
+//go:build unix && !linux
+
+package backup
+
+import (
+	"fmt"
+	"syscall"
+)
+
+func setPriority(pid int, priority ProcessPriority) error {
+	if priority.Nice != 0 {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, pid, priority.Nice); err != nil {
+			return err
+		}
+	}
+
+	if priority.IOClass != "" {
+		return fmt.Errorf("I/O priorities are only supported on Linux")
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// Progress modes
+const (
+	// ProgressAuto draws a bar when stderr is a terminal and logs progress lines otherwise
+	ProgressAuto = "auto"
+	ProgressBar  = "bar"
+	ProgressLog  = "log"
+	ProgressNone = "none"
+)
+
+// DefaultProgressInterval is how often progress is logged when it is not drawn as a bar
+const DefaultProgressInterval = 30 * time.Second
+
+// barInterval is how often the progress bar is redrawn
+const barInterval = 500 * time.Millisecond
+
+// estimateTimeout bounds the size query run before a backup
+const estimateTimeout = 15 * time.Second
+
+// SizeEstimator is implemented by dumpers that can query the size of the data they dump.
+// Dumps are compressed or written as text, so the size only estimates the dump's length.
+type SizeEstimator interface {
+	EstimateSize(ctx context.Context) (int64, error)
+}
+
+// countingReader counts the bytes read through it. The count may be read while the stream
+// is being consumed.
+type countingReader struct {
+	reader io.Reader
+	count  atomic.Int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.reader.Read(p)
+	cr.count.Add(int64(n))
+	return n, err
+}
+
+// progress reports the bytes counted by a countingReader until stopped
+type progress struct {
+	counter  *countingReader
+	total    int64 // estimated total, 0 when unknown
+	start    time.Time
+	bar      bool
+	interval time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// startProgress reports the progress of a backup stream. The returned function stops it.
+func startProgress(counter *countingReader, mode string, interval time.Duration, total int64) func() {
+	mode = resolveProgressMode(mode)
+	if mode == ProgressNone {
+		return func() {}
+	}
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+
+	p := &progress{
+		counter:  counter,
+		total:    total,
+		start:    time.Now(),
+		bar:      mode == ProgressBar,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+	if p.bar {
+		p.interval = barInterval
+	}
+
+	p.wg.Add(1)
+	go p.run()
+
+	return func() {
+		close(p.stop)
+		p.wg.Wait()
+	}
+}
+
+func (p *progress) run() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.report()
+		case <-p.stop:
+			if p.bar {
+				// Leave the final state on screen and move past it
+				p.report()
+				fmt.Fprintln(os.Stderr)
+			}
+			return
+		}
+	}
+}
+
+func (p *progress) report() {
+	count := p.counter.count.Load()
+	elapsed := time.Since(p.start)
+	rate := float64(count) / elapsed.Seconds()
+
+	status := FormatBytes(count)
+	if p.total > 0 {
+		status += " of ~" + FormatBytes(p.total)
+		if count < p.total {
+			status += fmt.Sprintf(" (%d%%)", count*100/p.total)
+		}
+	}
+	status += fmt.Sprintf(", %s/s, %s elapsed", FormatBytes(int64(rate)), formatDuration(elapsed))
+	if p.total > 0 && count < p.total && rate > 0 {
+		remaining := time.Duration(float64(p.total-count) / rate * float64(time.Second))
+		status += ", about " + formatDuration(remaining) + " left"
+	}
+
+	if p.bar {
+		fmt.Fprintf(os.Stderr, "\r%s %s\033[K", p.drawBar(count), status)
+		return
+	}
+	logger.Infof("⏳ Transferred %s", status)
+}
+
+// drawBar returns a 20-cell bar, or a spinner-like marker when the total is unknown
+func (p *progress) drawBar(count int64) string {
+	const width = 20
+	if p.total <= 0 {
+		position := int(time.Since(p.start)/barInterval) % width
+		return "[" + strings.Repeat(" ", position) + "=" + strings.Repeat(" ", width-1-position) + "]"
+	}
+
+	filled := int(count * width / p.total)
+	if filled > width {
+		filled = width
+	}
+	return "[" + strings.Repeat("=", filled) + strings.Repeat(" ", width-filled) + "]"
+}
+
+// resolveProgressMode picks the bar or log lines for ProgressAuto. The bar is only drawn on a
+// terminal with text logs, so it never ends up in JSON logs or files.
+func resolveProgressMode(mode string) string {
+	if mode != "" && mode != ProgressAuto {
+		return mode
+	}
+	if logger.Format() == logger.FormatText && isTerminal(os.Stderr) && os.Getenv("TERM") != "dumb" {
+		return ProgressBar
+	}
+	return ProgressLog
+}
+
+// ValidateProgressMode checks a --progress value
+func ValidateProgressMode(mode string) error {
+	switch mode {
+	case "", ProgressAuto, ProgressBar, ProgressLog, ProgressNone:
+		return nil
+	}
+	return fmt.Errorf("invalid progress mode %q (expected %s, %s, %s or %s)", mode, ProgressAuto, ProgressBar, ProgressLog, ProgressNone)
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// estimateSize asks the dumper for the size of the data to dump, when it can tell
+func estimateSize(ctx context.Context, dumper DatabaseDumper) int64 {
+	estimator, ok := dumper.(SizeEstimator)
+	if !ok {
+		return 0
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, estimateTimeout)
+	defer cancel()
+
+	size, err := estimator.EstimateSize(ctx)
+	if err != nil {
+		logger.Debugf("Could not estimate the backup size: %v", err)
+		return 0
+	}
+	return size
+}
+
+// FormatBytes formats a byte count with a binary unit, e.g. 1.5 GB
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value := float64(n) / unit
+	for _, suffix := range []string{"KB", "MB", "GB", "TB"} {
+		if value < unit || suffix == "TB" {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+	return ""
+}
+
+// formatDuration formats a duration as HH:MM:SS
+func formatDuration(d time.Duration) string {
+	seconds := int64(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
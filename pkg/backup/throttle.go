@@ -0,0 +1,138 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minRateBurst keeps the bucket large enough for the reads of storage clients
+const minRateBurst = 64 * 1024
+
+// rateLimitedReader limits the rate at which a stream is read with a token bucket that
+// holds up to one second of transfer
+type rateLimitedReader struct {
+	ctx    context.Context
+	reader io.Reader
+	rate   float64 // bytes per second
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimitedReader limits reader to rate bytes per second. A rate of 0 returns reader as is.
+func newRateLimitedReader(ctx context.Context, reader io.Reader, rate int64) io.Reader {
+	if rate <= 0 {
+		return reader
+	}
+
+	burst := int(rate)
+	if burst < minRateBurst {
+		burst = minRateBurst
+	}
+	return &rateLimitedReader{
+		ctx:    ctx,
+		reader: reader,
+		rate:   float64(rate),
+		burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (rl *rateLimitedReader) Read(p []byte) (int, error) {
+	if len(p) > rl.burst {
+		p = p[:rl.burst]
+	}
+
+	n, err := rl.reader.Read(p)
+	if n > 0 {
+		if waitErr := rl.wait(n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// wait takes n tokens from the bucket, sleeping until the bucket has refilled enough to
+// pay for them
+func (rl *rateLimitedReader) wait(n int) error {
+	now := time.Now()
+	rl.tokens += now.Sub(rl.last).Seconds() * rl.rate
+	if rl.tokens > float64(rl.burst) {
+		rl.tokens = float64(rl.burst)
+	}
+	rl.last = now
+
+	rl.tokens -= float64(n)
+	if rl.tokens >= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(time.Duration(-rl.tokens / rl.rate * float64(time.Second)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-rl.ctx.Done():
+		return rl.ctx.Err()
+	}
+}
+
+var sizeUnits = []struct {
+	suffix     string
+	multiplier float64
+}{
+	// Longest suffixes first so "MB" is not read as "B"
+	{"gbit", 1e9 / 8}, {"mbit", 1e6 / 8}, {"kbit", 1e3 / 8}, {"bit", 1.0 / 8},
+	{"gib", 1 << 30}, {"mib", 1 << 20}, {"kib", 1 << 10},
+	{"gb", 1 << 30}, {"mb", 1 << 20}, {"kb", 1 << 10},
+	{"g", 1 << 30}, {"m", 1 << 20}, {"k", 1 << 10},
+	{"b", 1},
+}
+
+// ParseRate parses a transfer rate such as 10MB, 512K/s, 100Mbit or 100Mbps into bytes per
+// second. Byte units are binary, like FormatBytes; bit units are decimal. A "bps" suffix
+// means bits unless the B is capitalized, as in 10MBps. An empty value or 0 means unlimited.
+func ParseRate(value string) (int64, error) {
+	text := strings.TrimSuffix(strings.TrimSpace(value), "/s")
+	if unit := len(text) - len("bps"); unit >= 0 && strings.EqualFold(text[unit:], "bps") {
+		if text[unit] == 'B' {
+			text = text[:unit] + "B"
+		} else {
+			text = text[:unit] + "bit"
+		}
+	}
+
+	rate, err := ParseSize(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate %q (expected e.g. 10MB, 512K/s or 100Mbit)", value)
+	}
+	return rate, nil
+}
+
+// ParseSize parses a size such as 64MB, 1.5G or 512K into bytes. An empty value is 0.
+func ParseSize(value string) (int64, error) {
+	text := strings.ToLower(strings.TrimSpace(value))
+	if text == "" {
+		return 0, nil
+	}
+
+	multiplier := 1.0
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(text, unit.suffix) {
+			text = strings.TrimSpace(strings.TrimSuffix(text, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid size %q (expected e.g. 64MB, 1.5G or 512K)", value)
+	}
+	return int64(number * multiplier), nil
+}
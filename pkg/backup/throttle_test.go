@@ -0,0 +1,72 @@
+package backup
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "", want: 0},
+		{value: "512", want: 512},
+		{value: "100b", want: 100},
+		{value: "512K", want: 512 << 10},
+		{value: "64MB", want: 64 << 20},
+		{value: "64 MiB", want: 64 << 20},
+		{value: "1.5G", want: 3 << 29},
+		{value: "2gib", want: 2 << 30},
+		{value: "8kbit", want: 1000},
+		{value: "-1M", wantErr: true},
+		{value: "lots", wantErr: true},
+		{value: "10XB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSize(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSize(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "", want: 0},
+		{value: "0", want: 0},
+		{value: "10MB", want: 10 << 20},
+		{value: "512K/s", want: 512 << 10},
+		{value: "10MB/s", want: 10 << 20},
+		{value: "100Mbit", want: 12_500_000},
+		{value: "100Mbit/s", want: 12_500_000},
+		// Network speeds are given in bits
+		{value: "100Mbps", want: 12_500_000},
+		{value: "100mbps", want: 12_500_000},
+		{value: "1Gbps", want: 125_000_000},
+		{value: "800kbps", want: 100_000},
+		{value: "8000bps", want: 1000},
+		{value: "10MBps", want: 10 << 20},
+		{value: "100Bps", want: 100},
+		{value: "fast", wantErr: true},
+		{value: "Mbps", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRate(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRate(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRate(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
@@ -2,17 +2,76 @@ package backup
 
 import (
 	"fmt"
-	"log"
+	"os"
 	"strings"
 	"time"
+
+	"github.com/dbbackup-io/cli/pkg/logger"
 )
 
-func generateBackupFilename(config BackupConfig, dumper DatabaseDumper) string {
-	timestamp := time.Now().Format("20060102_150405")
+var targetTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseTargetTime parses a recovery target time, interpreting values without a zone as local time
+func ParseTargetTime(value string) (time.Time, error) {
+	for _, layout := range targetTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid target time %q (expected format: YYYY-MM-DD HH:MM[:SS])", value)
+}
+
+// JoinKey joins storage key segments with "/", skipping empty segments
+func JoinKey(parts ...string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if part = strings.Trim(part, "/"); part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, "/")
+}
+
+// WriteSecretFile writes credentials to a temporary file readable only by the current user.
+// The returned cleanup function removes the file.
+func WriteSecretFile(pattern, content string) (string, func(), error) {
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", func() {}, fmt.Errorf("failed to create credentials file: %w", err)
+	}
+	cleanup := func() { os.Remove(file.Name()) }
+
+	if err := file.Chmod(0600); err != nil {
+		file.Close()
+		cleanup()
+		return "", func() {}, fmt.Errorf("failed to secure credentials file: %w", err)
+	}
+
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		cleanup()
+		return "", func() {}, fmt.Errorf("failed to write credentials file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		cleanup()
+		return "", func() {}, fmt.Errorf("failed to write credentials file: %w", err)
+	}
+
+	return file.Name(), cleanup, nil
+}
 
-	dbName := config.DatabaseName
-	if dbName == "" {
-		dbName = "default"
+// generateBackupFilename renders the key template and appends the dump's file extension
+func generateBackupFilename(config BackupConfig, dumper DatabaseDumper, now time.Time) (string, error) {
+	name, err := RenderKey(config.KeyTemplate, keyData(config, now))
+	if err != nil {
+		return "", err
 	}
 
 	extension := dumper.GetFileExtension()
@@ -20,18 +79,13 @@ func generateBackupFilename(config BackupConfig, dumper DatabaseDumper) string {
 		extension += ".gz"
 	}
 
-	return fmt.Sprintf("%s_%s_%s%s",
-		config.DatabaseType,
-		dbName,
-		timestamp,
-		extension,
-	)
+	return name + extension, nil
 }
 
 func logBackupSuccess(path string, size int64, dbType, storageType string) {
-	log.Printf("✅ Backup completed successfully: %s", path)
-	log.Printf("   Database: %s → Storage: %s", dbType, storageType)
+	logger.Infof("✅ Backup completed successfully: %s", path)
+	logger.Infof("   Database: %s → Storage: %s", dbType, storageType)
 	if size > 0 {
-		log.Printf("   Size: %.2f MB", float64(size)/1024/1024)
+		logger.Infof("   Size: %.2f MB", float64(size)/1024/1024)
 	}
 }
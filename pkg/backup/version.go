@@ -0,0 +1,109 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var versionPattern = regexp.MustCompile(`(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+
+// Version is a dotted version number of a client tool or database server
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseVersion extracts the first version number from text such as tool --version output
+func ParseVersion(text string) (Version, bool) {
+	match := versionPattern.FindStringSubmatch(text)
+	if match == nil {
+		return Version{}, false
+	}
+
+	var v Version
+	v.Major, _ = strconv.Atoi(match[1])
+	v.Minor, _ = strconv.Atoi(match[2])
+	v.Patch, _ = strconv.Atoi(match[3])
+	return v, true
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Less reports whether v is an older version than other
+func (v Version) Less(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+	return v.Patch < other.Patch
+}
+
+// AtLeast reports whether v is major.minor or newer
+func (v Version) AtLeast(major, minor int) bool {
+	return !v.Less(Version{Major: major, Minor: minor})
+}
+
+// ToolPath returns the path of a client tool in binDir, or just its name to look it up in PATH
+func ToolPath(binDir, name string) string {
+	if binDir == "" {
+		return name
+	}
+	return filepath.Join(binDir, name)
+}
+
+// ToolVersion runs a client tool with a version flag (default --version) and returns the
+// first line of its output, e.g. "pg_dump (PostgreSQL) 16.2". binDirFlag names the flag
+// that selects another installation, if any, and is mentioned when the tool cannot be found.
+func ToolVersion(ctx context.Context, path, binDirFlag string, args ...string) (string, error) {
+	if len(args) == 0 {
+		args = []string{"--version"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist) {
+			if binDirFlag == "" {
+				return "", fmt.Errorf("%s not found: install it", path)
+			}
+			return "", fmt.Errorf("%s not found: install it or pass the directory containing it with --%s", path, binDirFlag)
+		}
+		return "", fmt.Errorf("failed to run %s %s: %w %s", path, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return strings.Join(strings.Fields(line), " "), nil
+}
+
+// VersionMetadata returns the client tool and server versions as backup manifest metadata,
+// leaving out unknown ones
+func VersionMetadata(clientVersion, serverVersion string) map[string]string {
+	metadata := map[string]string{}
+	if clientVersion != "" {
+		metadata["client_version"] = clientVersion
+	}
+	if serverVersion != "" {
+		metadata["server_version"] = serverVersion
+	}
+	return metadata
+}
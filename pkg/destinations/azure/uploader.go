@@ -19,7 +19,36 @@ func (u *Uploader) Upload(ctx context.Context, key string, reader io.Reader) (in
 	return 0, fmt.Errorf("azure Blob Storage upload not implemented yet")
 }
 
+// CheckAvailable reports that the backend cannot store or read backups yet, so commands
+// fail before any work is done
+func (u *Uploader) CheckAvailable() error {
+	return fmt.Errorf("azure Blob Storage is not implemented yet")
+}
+
 // GetStorageType returns the storage type
 func (u *Uploader) GetStorageType() string {
 	return "azure"
 }
+
+// URL returns the blob endpoint URL of a key
+func (u *Uploader) URL(key string) string {
+	return fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s", u.AccountName, u.Container, key)
+}
+
+// Download downloads a blob from Azure Blob Storage to a writer
+func (u *Uploader) Download(ctx context.Context, key string, writer io.Writer) error {
+	// TODO: Implement Azure Blob Storage download
+	return fmt.Errorf("azure Blob Storage download not implemented yet")
+}
+
+// List lists blob names under the given prefix
+func (u *Uploader) List(ctx context.Context, prefix string) ([]string, error) {
+	// TODO: Implement Azure Blob Storage listing
+	return nil, fmt.Errorf("azure Blob Storage listing not implemented yet")
+}
+
+// Delete removes a blob from Azure Blob Storage
+func (u *Uploader) Delete(ctx context.Context, key string) error {
+	// TODO: Implement Azure Blob Storage deletion
+	return fmt.Errorf("azure Blob Storage deletion not implemented yet")
+}
@@ -19,7 +19,36 @@ func (u *Uploader) Upload(ctx context.Context, key string, reader io.Reader) (in
 	return 0, fmt.Errorf("google Cloud Storage upload not implemented yet")
 }
 
+// CheckAvailable reports that the backend cannot store or read backups yet, so commands
+// fail before any work is done
+func (u *Uploader) CheckAvailable() error {
+	return fmt.Errorf("google Cloud Storage is not implemented yet")
+}
+
 // GetStorageType returns the storage type
 func (u *Uploader) GetStorageType() string {
 	return "gcs"
 }
+
+// URL returns the gs:// location of a key
+func (u *Uploader) URL(key string) string {
+	return "gs://" + u.Bucket + "/" + key
+}
+
+// Download downloads an object from Google Cloud Storage to a writer
+func (u *Uploader) Download(ctx context.Context, key string, writer io.Writer) error {
+	// TODO: Implement Google Cloud Storage download
+	return fmt.Errorf("google Cloud Storage download not implemented yet")
+}
+
+// List lists object keys under the given prefix
+func (u *Uploader) List(ctx context.Context, prefix string) ([]string, error) {
+	// TODO: Implement Google Cloud Storage listing
+	return nil, fmt.Errorf("google Cloud Storage listing not implemented yet")
+}
+
+// Delete removes an object from Google Cloud Storage
+func (u *Uploader) Delete(ctx context.Context, key string) error {
+	// TODO: Implement Google Cloud Storage deletion
+	return fmt.Errorf("google Cloud Storage deletion not implemented yet")
+}
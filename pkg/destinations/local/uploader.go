@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 type Uploader struct {
@@ -27,19 +28,29 @@ func (u *Uploader) Upload(ctx context.Context, key string, reader io.Reader) (in
 		return 0, fmt.Errorf("failed to create parent directory %s: %w", parentDir, err)
 	}
 
-	// Create the file
-	file, err := os.Create(filePath)
+	// Write to a temporary name and rename once complete, so an aborted dump never
+	// looks like a backup
+	partialPath := filePath + ".partial"
+	file, err := os.Create(partialPath)
 	if err != nil {
-		return 0, fmt.Errorf("failed to create file %s: %w", filePath, err)
+		return 0, fmt.Errorf("failed to create file %s: %w", partialPath, err)
 	}
-	defer file.Close()
 
 	// Copy data from reader to file
 	size, err := io.Copy(file, reader)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
+		os.Remove(partialPath)
 		return 0, fmt.Errorf("failed to write backup data to %s: %w", filePath, err)
 	}
 
+	if err := os.Rename(partialPath, filePath); err != nil {
+		os.Remove(partialPath)
+		return 0, fmt.Errorf("failed to move backup into place at %s: %w", filePath, err)
+	}
+
 	return size, nil
 }
 
@@ -48,6 +59,11 @@ func (u *Uploader) GetStorageType() string {
 	return "local"
 }
 
+// URL returns the file path of a key
+func (u *Uploader) URL(key string) string {
+	return u.GetFilePath(key)
+}
+
 // Download downloads a file from local storage to a writer
 func (u *Uploader) Download(ctx context.Context, key string, writer io.Writer) error {
 	filePath := filepath.Join(u.Directory, key)
@@ -104,3 +120,44 @@ func (u *Uploader) ListBackups() ([]string, error) {
 
 	return backups, nil
 }
+
+// List lists backup keys under the given prefix, relative to the directory. Uploads still in
+// progress are left out.
+func (u *Uploader) List(ctx context.Context, prefix string) ([]string, error) {
+	root := filepath.Join(u.Directory, filepath.FromSlash(prefix))
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var keys []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() && !strings.HasSuffix(path, ".partial") {
+			relPath, err := filepath.Rel(u.Directory, path)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, filepath.ToSlash(relPath))
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups in %s: %w", root, err)
+	}
+
+	return keys, nil
+}
+
+// Delete removes a backup file from the directory
+func (u *Uploader) Delete(ctx context.Context, key string) error {
+	filePath := filepath.Join(u.Directory, key)
+	if err := os.Remove(filePath); err != nil {
+		return fmt.Errorf("failed to delete backup file %s: %w", filePath, err)
+	}
+
+	return nil
+}
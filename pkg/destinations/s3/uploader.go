@@ -2,23 +2,182 @@ package s3
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"net/url"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/dbbackup-io/cli/pkg/logger"
 )
 
+// sizeHintMargin leaves room for dumps that grow beyond the size hint: parts are sized so
+// an object of twice the hint still fits in s3manager.MaxUploadParts parts
+const sizeHintMargin = 2
+
 type Uploader struct {
 	Region    string
 	Bucket    string
 	AccessKey string
 	SecretKey string
+
+	// Multipart upload tuning. PartSize 0 sizes parts from the expected object size, or uses the SDK default
+	// of 5 MB, which limits a streamed object to about 48 GB. Concurrency 0 uses the SDK
+	// default of 5. The uploader buffers Concurrency+1 parts in memory; MaxBufferMemory, when
+	// set, lowers the concurrency to stay within it.
+	PartSize        int64
+	Concurrency     int
+	MaxBufferMemory int64
+	// SizeHint is the expected object size, used to pick a part size. It takes precedence
+	// over the size passed to UploadSized.
+	SizeHint int64
+
+	// Object options
+	StorageClass string
+	SSE          string // AES256 (SSE-S3) or aws:kms (SSE-KMS)
+	SSEKMSKeyID  string
+	Tags         map[string]string
+	ACL          string
 }
 
-func (u *Uploader) Upload(ctx context.Context, key string, reader io.Reader) (int64, error) {
-	// Create AWS session
+// Validate checks the upload options against the values S3 accepts
+func (u *Uploader) Validate() error {
+	if u.PartSize != 0 && u.PartSize < s3manager.MinUploadPartSize {
+		return fmt.Errorf("part size must be at least %s", formatMB(s3manager.MinUploadPartSize))
+	}
+	if u.Concurrency < 0 {
+		return fmt.Errorf("concurrency must be positive")
+	}
+	if u.StorageClass != "" && !contains(awss3.StorageClass_Values(), u.StorageClass) {
+		return fmt.Errorf("invalid storage class %q (expected one of %s)", u.StorageClass, strings.Join(awss3.StorageClass_Values(), ", "))
+	}
+	if u.SSE != "" && !contains(awss3.ServerSideEncryption_Values(), u.SSE) {
+		return fmt.Errorf("invalid server-side encryption %q (expected %s)", u.SSE, strings.Join(awss3.ServerSideEncryption_Values(), ", "))
+	}
+	if u.SSEKMSKeyID != "" && u.SSE != awss3.ServerSideEncryptionAwsKms && u.SSE != awss3.ServerSideEncryptionAwsKmsDsse {
+		return fmt.Errorf("a KMS key ID requires --sse %s", awss3.ServerSideEncryptionAwsKms)
+	}
+	if u.ACL != "" && !contains(awss3.ObjectCannedACL_Values(), u.ACL) {
+		return fmt.Errorf("invalid ACL %q (expected one of %s)", u.ACL, strings.Join(awss3.ObjectCannedACL_Values(), ", "))
+	}
+	return nil
+}
+
+// partSize returns the configured part size, or one large enough for the expected size
+func (u *Uploader) partSize(expected int64) int64 {
+	if u.PartSize != 0 {
+		return u.PartSize
+	}
+	if u.SizeHint > 0 {
+		expected = u.SizeHint
+	}
+
+	size := s3manager.DefaultUploadPartSize
+	if expected > 0 {
+		const mb = 1024 * 1024
+		needed := expected * sizeHintMargin / s3manager.MaxUploadParts
+		// Round up to whole megabytes
+		needed = (needed + mb - 1) / mb * mb
+		if needed > size {
+			size = needed
+		}
+	}
+	return size
+}
+
+// concurrency returns the number of parts uploaded in parallel, within MaxBufferMemory
+func (u *Uploader) concurrency(partSize int64) (int, error) {
+	concurrency := u.Concurrency
+	if concurrency == 0 {
+		concurrency = s3manager.DefaultUploadConcurrency
+	}
+
+	if u.MaxBufferMemory > 0 {
+		fit := int(u.MaxBufferMemory/partSize) - 1
+		if fit < 1 {
+			return 0, fmt.Errorf("a buffer of %s cannot hold two parts of %s", formatMB(u.MaxBufferMemory), formatMB(partSize))
+		}
+		if fit < concurrency {
+			concurrency = fit
+		}
+	}
+	return concurrency, nil
+}
+
+// uploadInput returns the upload request with the object options applied
+func (u *Uploader) uploadInput(key string, reader io.Reader) *s3manager.UploadInput {
+	input := &s3manager.UploadInput{
+		Bucket: aws.String(u.Bucket),
+		Key:    aws.String(key),
+		Body:   reader,
+	}
+	if u.StorageClass != "" {
+		input.StorageClass = aws.String(u.StorageClass)
+	}
+	if u.SSE != "" {
+		input.ServerSideEncryption = aws.String(u.SSE)
+	}
+	if u.SSEKMSKeyID != "" {
+		input.SSEKMSKeyId = aws.String(u.SSEKMSKeyID)
+	}
+	if len(u.Tags) > 0 {
+		tags := url.Values{}
+		for name, value := range u.Tags {
+			tags.Set(name, value)
+		}
+		input.Tagging = aws.String(tags.Encode())
+	}
+	if u.ACL != "" {
+		input.ACL = aws.String(u.ACL)
+	}
+	return input
+}
+
+// DescribeUpload lists the upload options for a dry run. The part size is the one used
+// without a size hint, since a dry run does not query the database size.
+func (u *Uploader) DescribeUpload() []string {
+	partSize := u.partSize(0)
+	lines := []string{fmt.Sprintf("Parts:    %s", formatMB(partSize))}
+	if concurrency, err := u.concurrency(partSize); err == nil {
+		lines[0] += fmt.Sprintf(", %d in parallel", concurrency)
+	}
+	if u.StorageClass != "" {
+		lines = append(lines, "Class:    "+u.StorageClass)
+	}
+	if u.SSE != "" {
+		sse := u.SSE
+		if u.SSEKMSKeyID != "" {
+			sse += " (key " + u.SSEKMSKeyID + ")"
+		}
+		lines = append(lines, "SSE:      "+sse)
+	}
+	if len(u.Tags) > 0 {
+		lines = append(lines, "Tags:     "+*u.uploadInput("", nil).Tagging)
+	}
+	if u.ACL != "" {
+		lines = append(lines, "ACL:      "+u.ACL)
+	}
+	return lines
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+func formatMB(size int64) string {
+	return fmt.Sprintf("%.0f MB", float64(size)/1024/1024)
+}
+
+func (u *Uploader) newSession() (*session.Session, error) {
 	config := &aws.Config{
 		Region: aws.String(u.Region),
 	}
@@ -28,20 +187,42 @@ func (u *Uploader) Upload(ctx context.Context, key string, reader io.Reader) (in
 		config.Credentials = credentials.NewStaticCredentials(u.AccessKey, u.SecretKey, "")
 	}
 
-	sess, err := session.NewSession(config)
+	return session.NewSession(config)
+}
+
+func (u *Uploader) Upload(ctx context.Context, key string, reader io.Reader) (int64, error) {
+	return u.UploadSized(ctx, key, reader, 0)
+}
+
+// UploadSized uploads an object of about the given size, choosing a part size large enough
+// for it. The size only tunes this upload, so concurrent uploads can pass different sizes.
+func (u *Uploader) UploadSized(ctx context.Context, key string, reader io.Reader, size int64) (int64, error) {
+	// Create AWS session
+	sess, err := u.newSession()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := u.Validate(); err != nil {
+		return 0, err
+	}
+	partSize := u.partSize(size)
+	concurrency, err := u.concurrency(partSize)
 	if err != nil {
 		return 0, err
 	}
 
 	// Create uploader
-	uploader := s3manager.NewUploader(sess)
+	uploader := s3manager.NewUploader(sess, func(uploader *s3manager.Uploader) {
+		uploader.PartSize = partSize
+		uploader.Concurrency = concurrency
+	})
+	if partSize != s3manager.DefaultUploadPartSize {
+		logger.Debugf("S3 multipart upload of %s: %s parts, concurrency %d", key, formatMB(partSize), concurrency)
+	}
 
 	// Upload the file
-	result, err := uploader.UploadWithContext(ctx, &s3manager.UploadInput{
-		Bucket: aws.String(u.Bucket),
-		Key:    aws.String(key),
-		Body:   reader,
-	})
+	result, err := uploader.UploadWithContext(ctx, u.uploadInput(key, reader))
 
 	if err != nil {
 		return 0, err
@@ -57,3 +238,73 @@ func (u *Uploader) Upload(ctx context.Context, key string, reader io.Reader) (in
 func (u *Uploader) GetStorageType() string {
 	return "s3"
 }
+
+// URL returns the s3:// location of a key
+func (u *Uploader) URL(key string) string {
+	return "s3://" + u.Bucket + "/" + key
+}
+
+// Download streams an object from S3 to a writer
+func (u *Uploader) Download(ctx context.Context, key string, writer io.Writer) error {
+	sess, err := u.newSession()
+	if err != nil {
+		return err
+	}
+
+	output, err := awss3.New(sess).GetObjectWithContext(ctx, &awss3.GetObjectInput{
+		Bucket: aws.String(u.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get s3://%s/%s: %w", u.Bucket, key, err)
+	}
+	defer output.Body.Close()
+
+	if _, err := io.Copy(writer, output.Body); err != nil {
+		return fmt.Errorf("failed to read s3://%s/%s: %w", u.Bucket, key, err)
+	}
+
+	return nil
+}
+
+// List lists object keys under the given prefix
+func (u *Uploader) List(ctx context.Context, prefix string) ([]string, error) {
+	sess, err := u.newSession()
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	err = awss3.New(sess).ListObjectsV2PagesWithContext(ctx, &awss3.ListObjectsV2Input{
+		Bucket: aws.String(u.Bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *awss3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			keys = append(keys, aws.StringValue(object.Key))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list s3://%s/%s: %w", u.Bucket, prefix, err)
+	}
+
+	return keys, nil
+}
+
+// Delete removes an object from S3
+func (u *Uploader) Delete(ctx context.Context, key string) error {
+	sess, err := u.newSession()
+	if err != nil {
+		return err
+	}
+
+	_, err = awss3.New(sess).DeleteObjectWithContext(ctx, &awss3.DeleteObjectInput{
+		Bucket: aws.String(u.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete s3://%s/%s: %w", u.Bucket, key, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package sftp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/sftp"
+)
+
+// Uploader stores backups in a directory on an SFTP server. It runs the system ssh client
+// with the sftp subsystem, so ~/.ssh/config, the SSH agent and known_hosts apply as they do
+// for sftp and scp, and accounts limited to SFTP (ForceCommand internal-sftp, chroots) work.
+type Uploader struct {
+	Host         string
+	Port         int    // 0 uses the ssh default
+	User         string // empty uses the ssh default
+	Directory    string // absolute, or relative to the remote user's login directory
+	IdentityFile string
+}
+
+// Upload streams a backup to the server. The data is written to a temporary name and
+// renamed once complete, so an interrupted upload never looks like a backup.
+func (u *Uploader) Upload(ctx context.Context, key string, reader io.Reader) (int64, error) {
+	remotePath := u.remotePath(key)
+	partial := remotePath + ".partial"
+
+	var size int64
+	err := u.session(ctx, func(client *sftp.Client) error {
+		if err := client.MkdirAll(path.Dir(remotePath)); err != nil {
+			return err
+		}
+
+		file, err := client.Create(partial)
+		if err != nil {
+			return err
+		}
+		size, err = io.Copy(file, reader)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = rename(client, partial, remotePath)
+		}
+		if err != nil {
+			client.Remove(partial)
+		}
+		return err
+	})
+	if err != nil {
+		return size, fmt.Errorf("failed to upload %s: %w", u.URL(key), err)
+	}
+
+	return size, nil
+}
+
+// rename moves a file over an existing one. Servers without the posix-rename extension
+// refuse to rename onto an existing file, so it is removed first.
+func rename(client *sftp.Client, from, to string) error {
+	if _, ok := client.HasExtension("posix-rename@openssh.com"); ok {
+		return client.PosixRename(from, to)
+	}
+	if err := client.Remove(to); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return client.Rename(from, to)
+}
+
+// GetStorageType returns the storage type
+func (u *Uploader) GetStorageType() string {
+	return "sftp"
+}
+
+// URL returns the sftp:// location of a key. Paths relative to the login directory start
+// with /~/.
+func (u *Uploader) URL(key string) string {
+	host := u.Host
+	if u.Port != 0 {
+		host += ":" + strconv.Itoa(u.Port)
+	}
+	if u.User != "" {
+		host = u.User + "@" + host
+	}
+	remotePath := u.remotePath(key)
+	if !strings.HasPrefix(remotePath, "/") {
+		remotePath = "/~/" + remotePath
+	}
+	return "sftp://" + host + remotePath
+}
+
+// Download streams a backup from the server to a writer
+func (u *Uploader) Download(ctx context.Context, key string, writer io.Writer) error {
+	err := u.session(ctx, func(client *sftp.Client) error {
+		file, err := client.Open(u.remotePath(key))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = file.WriteTo(writer)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", u.URL(key), err)
+	}
+	return nil
+}
+
+// List lists backup keys under the given prefix, relative to the directory. Uploads still in
+// progress are left out.
+func (u *Uploader) List(ctx context.Context, prefix string) ([]string, error) {
+	root := u.remotePath(prefix)
+
+	var keys []string
+	err := u.session(ctx, func(client *sftp.Client) error {
+		if _, err := client.Stat(root); errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		walker := client.Walk(root)
+		for walker.Step() {
+			if err := walker.Err(); err != nil {
+				return err
+			}
+			if walker.Stat().IsDir() || strings.HasSuffix(walker.Path(), ".partial") {
+				continue
+			}
+			name := walker.Path()
+			if root != "." {
+				name = strings.TrimPrefix(name, root+"/")
+			}
+			keys = append(keys, path.Join(strings.Trim(prefix, "/"), name))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", u.URL(prefix), err)
+	}
+	return keys, nil
+}
+
+// Delete removes a backup from the server
+func (u *Uploader) Delete(ctx context.Context, key string) error {
+	err := u.session(ctx, func(client *sftp.Client) error {
+		return client.Remove(u.remotePath(key))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %w", u.URL(key), err)
+	}
+	return nil
+}
+
+// session runs fn with an SFTP client speaking over an ssh connection. Any error includes
+// what ssh printed, which explains failed connections and authentication.
+func (u *Uploader) session(ctx context.Context, fn func(client *sftp.Client) error) error {
+	cmd := u.command(ctx)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create ssh stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create ssh stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start ssh: %w", err)
+	}
+
+	client, err := sftp.NewClientPipe(stdout, stdin)
+	if err == nil {
+		err = fn(client)
+		client.Close()
+	} else {
+		stdin.Close()
+	}
+	if waitErr := cmd.Wait(); err == nil && waitErr != nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	return sshError(err, stderr.String())
+}
+
+// command starts the sftp subsystem on the server. BatchMode keeps ssh from prompting for a
+// password, which would hang a scheduled backup.
+func (u *Uploader) command(ctx context.Context) *exec.Cmd {
+	args := []string{"-o", "BatchMode=yes"}
+	if u.Port != 0 {
+		args = append(args, "-p", strconv.Itoa(u.Port))
+	}
+	if u.IdentityFile != "" {
+		args = append(args, "-i", u.IdentityFile)
+	}
+	if u.User != "" {
+		args = append(args, "-l", u.User)
+	}
+	args = append(args, "-s", "--", u.Host, "sftp")
+	return exec.CommandContext(ctx, "ssh", args...)
+}
+
+func (u *Uploader) remotePath(key string) string {
+	directory := u.Directory
+	if directory == "" {
+		directory = "."
+	}
+	return path.Join(directory, key)
+}
+
+// sshError adds what ssh printed to an error
+func sshError(err error, output string) error {
+	if err == nil {
+		return nil
+	}
+	if output = strings.TrimSpace(output); output != "" {
+		return fmt.Errorf("%w: %s", err, output)
+	}
+	return err
+}
@@ -0,0 +1,159 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// textHandler writes records the way the CLI always has: a timestamp, a level tag for
+// anything but info, the message, then the fields as key=value pairs
+type textHandler struct {
+	mu     *sync.Mutex
+	writer io.Writer
+	level  slog.Leveler
+	attrs  []slog.Attr
+	group  string
+}
+
+func newTextHandler(writer io.Writer, level slog.Leveler) *textHandler {
+	return &textHandler{mu: &sync.Mutex{}, writer: writer, level: level}
+}
+
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *textHandler) Handle(_ context.Context, record slog.Record) error {
+	var line strings.Builder
+	line.WriteString(record.Time.Format("2006/01/02 15:04:05 "))
+
+	switch {
+	case record.Level >= slog.LevelError:
+		line.WriteString("[ERROR] ")
+	case record.Level >= slog.LevelWarn:
+		line.WriteString("[WARN] ")
+	case record.Level < slog.LevelInfo:
+		line.WriteString("[DEBUG] ")
+	}
+	line.WriteString(record.Message)
+
+	for _, attr := range h.attrs {
+		writeAttr(&line, "", attr)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		writeAttr(&line, h.group, attr)
+		return true
+	})
+	line.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.writer, line.String())
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, attr := range attrs {
+		if h.group != "" {
+			attr.Key = h.group + "." + attr.Key
+		}
+		clone.attrs = append(clone.attrs, attr)
+	}
+	return &clone
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	if clone.group != "" {
+		name = clone.group + "." + name
+	}
+	clone.group = name
+	return &clone
+}
+
+func writeAttr(line *strings.Builder, group string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	key := attr.Key
+	if group != "" {
+		key = group + "." + key
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		for _, member := range attr.Value.Group() {
+			writeAttr(line, key, member)
+		}
+		return
+	}
+
+	value := attr.Value.String()
+	if attr.Value.Kind() == slog.KindTime {
+		value = attr.Value.Time().Format(time.RFC3339)
+	}
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = fmt.Sprintf("%q", value)
+	}
+	fmt.Fprintf(line, " %s=%s", key, value)
+}
+
+// redactHandler removes secrets from messages and fields before they reach the wrapped handler
+type redactHandler struct {
+	next slog.Handler
+}
+
+func newRedactHandler(next slog.Handler) *redactHandler {
+	return &redactHandler{next: next}
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, Redact(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = redactAttr(attr)
+	}
+	return &redactHandler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name)}
+}
+
+// redactAttr hides the values of secret-looking fields and secrets within string values
+func redactAttr(attr slog.Attr) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	switch {
+	case IsSecretName(attr.Key):
+		return slog.String(attr.Key, Redacted)
+	case attr.Value.Kind() == slog.KindString:
+		return slog.String(attr.Key, Redact(attr.Value.String()))
+	case attr.Value.Kind() == slog.KindGroup:
+		members := attr.Value.Group()
+		redacted := make([]any, len(members))
+		for i, member := range members {
+			redacted[i] = redactAttr(member)
+		}
+		return slog.Group(attr.Key, redacted...)
+	}
+	return attr
+}
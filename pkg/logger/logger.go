@@ -1,106 +1,185 @@
 package logger
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"log/slog"
+	"os"
 	"strings"
 )
 
-// LogLevel represents the logging level
-type LogLevel int
-
+// Log formats
 const (
-	ErrorLevel LogLevel = iota
-	WarnLevel
-	InfoLevel
-	DebugLevel
+	FormatText = "text"
+	FormatJSON = "json"
 )
 
-var currentLevel = InfoLevel
+// Options configures the process-wide logger
+type Options struct {
+	Level  string // error, warn, info or debug
+	Format string // FormatText (default) or FormatJSON
+	File   string // when set, logs are also appended to this file
+}
+
+var (
+	level   slog.LevelVar
+	format  = FormatText
+	logFile *os.File
+)
+
+func init() {
+	slog.SetDefault(slog.New(newRedactHandler(newTextHandler(os.Stderr, &level))))
+}
+
+// Setup installs the logger described by opts as the slog default. The standard library
+// log package writes through it as well.
+func Setup(opts Options) error {
+	if err := SetLevel(opts.Level); err != nil {
+		return err
+	}
+
+	var writer io.Writer = os.Stderr
+	if opts.File != "" {
+		file, err := os.OpenFile(opts.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		Close()
+		logFile = file
+		writer = io.MultiWriter(os.Stderr, file)
+	}
+
+	var handler slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case "", FormatText:
+		handler = newTextHandler(writer, &level)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(writer, &slog.HandlerOptions{Level: &level})
+	default:
+		return fmt.Errorf("invalid log format: %s (valid: text, json)", opts.Format)
+	}
+
+	format = strings.ToLower(opts.Format)
+	if format == "" {
+		format = FormatText
+	}
+	slog.SetDefault(slog.New(newRedactHandler(handler)))
+	return nil
+}
+
+// Format returns the log format in use
+func Format() string {
+	return format
+}
+
+// Close closes the log file, if any
+func Close() {
+	if logFile != nil {
+		logFile.Close()
+		logFile = nil
+	}
+}
+
+// With adds fields to every following log record, e.g. With("run_id", id, "db", "postgres")
+func With(args ...any) {
+	slog.SetDefault(slog.Default().With(args...))
+}
+
+// NewRunID returns a short random identifier that ties together the log records of one run
+func NewRunID() string {
+	id := make([]byte, 6)
+	if _, err := rand.Read(id); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(id)
+}
 
 // SetLevel sets the logging level from string
-func SetLevel(level string) error {
-	switch strings.ToLower(level) {
+func SetLevel(value string) error {
+	switch strings.ToLower(value) {
 	case "error":
-		currentLevel = ErrorLevel
+		level.Set(slog.LevelError)
 	case "warn", "warning":
-		currentLevel = WarnLevel
-	case "info":
-		currentLevel = InfoLevel
+		level.Set(slog.LevelWarn)
+	case "info", "":
+		level.Set(slog.LevelInfo)
 	case "debug":
-		currentLevel = DebugLevel
+		level.Set(slog.LevelDebug)
 	default:
-		return fmt.Errorf("invalid log level: %s (valid: error, warn, info, debug)", level)
+		return fmt.Errorf("invalid log level: %s (valid: error, warn, info, debug)", value)
 	}
 	return nil
 }
 
+// GetLevel returns the current log level as string
+func GetLevel() string {
+	switch {
+	case level.Level() <= slog.LevelDebug:
+		return "debug"
+	case level.Level() <= slog.LevelInfo:
+		return "info"
+	case level.Level() <= slog.LevelWarn:
+		return "warn"
+	default:
+		return "error"
+	}
+}
+
+// DebugEnabled reports whether debug records are logged
+func DebugEnabled() bool {
+	return level.Level() <= slog.LevelDebug
+}
+
 // Info logs an info message (shown by default)
 func Info(args ...interface{}) {
-	if currentLevel >= InfoLevel {
-		fmt.Println(args...)
-	}
+	slog.Info(sprint(args...))
 }
 
 // Infof logs a formatted info message
 func Infof(format string, args ...interface{}) {
-	if currentLevel >= InfoLevel {
-		fmt.Printf(format+"\n", args...)
-	}
+	slog.Info(fmt.Sprintf(format, args...))
 }
 
 // Debug logs a debug message (only shown with debug level)
 func Debug(args ...interface{}) {
-	if currentLevel >= DebugLevel {
-		fmt.Print("[DEBUG] ")
-		fmt.Println(args...)
-	}
+	slog.Debug(sprint(args...))
 }
 
 // Debugf logs a formatted debug message
 func Debugf(format string, args ...interface{}) {
-	if currentLevel >= DebugLevel {
-		fmt.Printf("[DEBUG] "+format+"\n", args...)
-	}
+	slog.Debug(fmt.Sprintf(format, args...))
 }
 
 // Warn logs a warning message
 func Warn(args ...interface{}) {
-	if currentLevel >= WarnLevel {
-		fmt.Print("[WARN] ")
-		fmt.Println(args...)
-	}
+	slog.Warn(sprint(args...))
 }
 
 // Warnf logs a formatted warning message
 func Warnf(format string, args ...interface{}) {
-	if currentLevel >= WarnLevel {
-		fmt.Printf("[WARN] "+format+"\n", args...)
-	}
+	slog.Warn(fmt.Sprintf(format, args...))
 }
 
 // Error logs an error message (always shown)
 func Error(args ...interface{}) {
-	fmt.Print("[ERROR] ")
-	fmt.Println(args...)
+	slog.Error(sprint(args...))
 }
 
 // Errorf logs a formatted error message (always shown)
 func Errorf(format string, args ...interface{}) {
-	fmt.Printf("[ERROR] "+format+"\n", args...)
+	slog.Error(fmt.Sprintf(format, args...))
 }
 
-// GetLevel returns the current log level as string
-func GetLevel() string {
-	switch currentLevel {
-	case ErrorLevel:
-		return "error"
-	case WarnLevel:
-		return "warn"
-	case InfoLevel:
-		return "info"
-	case DebugLevel:
-		return "debug"
-	default:
-		return "info"
-	}
+// Fatalf logs a formatted error message and exits with status 1
+func Fatalf(format string, args ...interface{}) {
+	slog.Error(fmt.Sprintf(format, args...))
+	Close()
+	os.Exit(1)
+}
+
+// sprint formats arguments like fmt.Println, without the trailing newline
+func sprint(args ...interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
 }
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces secrets in log records and dry-run output, like url.URL.Redacted does
+// for passwords
+const Redacted = "xxxxx"
+
+// minSecretLength keeps very short values from being registered: masking every occurrence
+// of them would garble unrelated text
+const minSecretLength = 4
+
+var (
+	secretNamePattern   = regexp.MustCompile(`(?i)PASSWORD|PASSWD|AUTH|SECRET|TOKEN|API_?KEY|ACCOUNT_?KEY|ACCESS_?KEY|CREDENTIAL`)
+	secretOptionPattern = regexp.MustCompile(`(?i)(password\s*=\s*)('(?:[^'\\]|\\.)*'|[^\s,;&]+)`)
+	urlPasswordPattern  = regexp.MustCompile(`([A-Za-z][A-Za-z0-9+.-]*://[^:/?#@\s]*:)([^@/\s]+)(@)`)
+
+	secretsMu sync.RWMutex
+	secrets   []string
+)
+
+// AddSecret registers a value, such as a resolved password or key, to be masked wherever it
+// appears in log output
+func AddSecret(value string) {
+	value = strings.TrimSpace(value)
+	if len(value) < minSecretLength {
+		return
+	}
+
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, secret := range secrets {
+		if secret == value {
+			return
+		}
+	}
+	secrets = append(secrets, value)
+	// Mask longer secrets first so one containing another is hidden completely
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+}
+
+// IsSecretName reports whether a field or environment variable name looks like it holds a secret
+func IsSecretName(name string) bool {
+	return secretNamePattern.MatchString(name)
+}
+
+// Redact hides registered secrets, passwords in connection URLs and password=... options
+// within text
+func Redact(text string) string {
+	secretsMu.RLock()
+	for _, secret := range secrets {
+		text = strings.ReplaceAll(text, secret, Redacted)
+	}
+	secretsMu.RUnlock()
+
+	text = urlPasswordPattern.ReplaceAllString(text, "${1}"+Redacted+"${3}")
+	return secretOptionPattern.ReplaceAllString(text, "${1}"+Redacted)
+}
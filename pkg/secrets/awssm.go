@@ -0,0 +1,76 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// AWSSecretsManagerResolver reads secrets from AWS Secrets Manager (awssm:arn[#key]).
+// With a #key the secret string is parsed as JSON and the key's value is returned.
+type AWSSecretsManagerResolver struct {
+	Region   string // defaults to the region in the ARN, then the AWS environment
+	Endpoint string // defaults to the AWS endpoint for the region
+}
+
+func (r *AWSSecretsManagerResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	secretID, key := splitKey(ref)
+
+	config := &aws.Config{}
+	if region := firstNonEmpty(r.Region, regionFromARN(secretID)); region != "" {
+		config.Region = aws.String(region)
+	}
+	if r.Endpoint != "" {
+		config.Endpoint = aws.String(r.Endpoint)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *config,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	output, err := secretsmanager.New(sess).GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretID),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	value := aws.StringValue(output.SecretString)
+	if output.SecretString == nil {
+		value = string(output.SecretBinary)
+	}
+
+	if key == "" {
+		return value, nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object: %w", secretID, err)
+	}
+
+	field, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("key %q not found in secret %s", key, secretID)
+	}
+
+	return stringValue(field), nil
+}
+
+// regionFromARN extracts the region from arn:aws:secretsmanager:<region>:<account>:secret:<name>
+func regionFromARN(arn string) string {
+	parts := strings.Split(arn, ":")
+	if len(parts) > 3 && parts[0] == "arn" {
+		return parts[3]
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newSecretsManagerServer answers GetSecretValue from a map of secret IDs to responses
+func newSecretsManagerServer(t *testing.T, secrets map[string]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if target := r.Header.Get("X-Amz-Target"); target != "secretsmanager.GetSecretValue" {
+			t.Errorf("unexpected operation %q", target)
+		}
+
+		var input struct{ SecretId string }
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		response, ok := secrets[input.SecretId]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"ResourceNotFoundException","Message":"Secrets Manager can't find the specified secret."}`))
+			return
+		}
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAWSSecretsManagerResolver(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CONFIG_FILE", "/nonexistent")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/nonexistent")
+
+	const arn = "arn:aws:secretsmanager:eu-west-1:123456789012:secret:db"
+	server := newSecretsManagerServer(t, map[string]string{
+		arn:      `{"Name":"db","SecretString":"{\"username\":\"app\",\"password\":\"sm-pass\",\"port\":5432}"}`,
+		"plain":  `{"Name":"plain","SecretString":"just-a-string"}`,
+		"binary": `{"Name":"binary","SecretBinary":"YmluYXJ5LXBhc3M="}`,
+	})
+	resolver := &AWSSecretsManagerResolver{Region: "eu-west-1", Endpoint: server.URL}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{ref: arn + "#password", want: "sm-pass"},
+		{ref: arn + "#port", want: "5432"},
+		{ref: "plain", want: "just-a-string"},
+		{ref: "binary", want: "binary-pass"},
+		{ref: arn + "#missing", wantErr: `key "missing" not found`},
+		{ref: "plain#password", wantErr: "not a JSON object"},
+		{ref: "unknown", wantErr: "ResourceNotFoundException"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := resolver.Resolve(context.Background(), tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Resolve(%q) error = %v, want one containing %q", tt.ref, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve(%q) error = %v", tt.ref, err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegionFromARN(t *testing.T) {
+	tests := map[string]string{
+		"arn:aws:secretsmanager:eu-west-1:123456789012:secret:db": "eu-west-1",
+		"db": "",
+		"":   "",
+	}
+	for arn, want := range tests {
+		if got := regionFromARN(arn); got != want {
+			t.Errorf("regionFromARN(%q) = %q, want %q", arn, got, want)
+		}
+	}
+}
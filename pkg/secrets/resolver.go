@@ -0,0 +1,101 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Resolver resolves the reference part of a secret reference (everything after "scheme:")
+type Resolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// ResolverFunc adapts a function to the Resolver interface
+type ResolverFunc func(ctx context.Context, ref string) (string, error)
+
+func (f ResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+var resolvers = map[string]Resolver{
+	"env":   ResolverFunc(resolveEnv),
+	"file":  ResolverFunc(resolveFile),
+	"cmd":   ResolverFunc(resolveCommand),
+	"vault": &VaultResolver{},
+	"awssm": &AWSSecretsManagerResolver{},
+}
+
+// Register adds or replaces the resolver for a scheme
+func Register(scheme string, resolver Resolver) {
+	resolvers[scheme] = resolver
+}
+
+// IsReference reports whether a value uses a registered secret reference scheme
+func IsReference(value string) bool {
+	scheme, _, found := strings.Cut(value, ":")
+	if !found {
+		return false
+	}
+	_, ok := resolvers[scheme]
+	return ok
+}
+
+// Resolve returns the secret a value refers to. Values that do not start with a registered
+// scheme (env:, file:, cmd:, vault:, awssm:) are returned unchanged.
+func Resolve(ctx context.Context, value string) (string, error) {
+	scheme, ref, found := strings.Cut(value, ":")
+	if !found {
+		return value, nil
+	}
+
+	resolver, ok := resolvers[scheme]
+	if !ok {
+		return value, nil
+	}
+
+	secret, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s secret: %w", scheme, err)
+	}
+
+	return secret, nil
+}
+
+// resolveEnv reads a secret from an environment variable (env:NAME)
+func resolveEnv(ctx context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// resolveFile reads a secret from a file (file:/path), ignoring a trailing newline
+func resolveFile(ctx context.Context, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// resolveCommand runs a shell command and uses its output as the secret (cmd:...)
+func resolveCommand(ctx context.Context, command string) (string, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stderr = os.Stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("command failed: %w", err)
+	}
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
+
+// splitKey splits "path#key" into its path and optional key
+func splitKey(ref string) (string, string) {
+	path, key, _ := strings.Cut(ref, "#")
+	return path, key
+}
@@ -0,0 +1,108 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultResolver reads secrets from HashiCorp Vault over its HTTP API (vault:secret/path#key).
+// Both KV version 1 and version 2 responses are supported; for KV v2 the path must include
+// the "data/" segment (e.g. vault:secret/data/myapp#password).
+type VaultResolver struct {
+	Address    string // defaults to VAULT_ADDR
+	Token      string // defaults to VAULT_TOKEN
+	Namespace  string // defaults to VAULT_NAMESPACE
+	HTTPClient *http.Client
+}
+
+func (v *VaultResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	path, key := splitKey(ref)
+	if key == "" {
+		return "", fmt.Errorf("vault reference %q must include a #key", ref)
+	}
+
+	address := firstNonEmpty(v.Address, os.Getenv("VAULT_ADDR"))
+	if address == "" {
+		return "", fmt.Errorf("vault address not set (VAULT_ADDR)")
+	}
+
+	token := firstNonEmpty(v.Token, os.Getenv("VAULT_TOKEN"))
+	if token == "" {
+		return "", fmt.Errorf("vault token not set (VAULT_TOKEN)")
+	}
+
+	url := strings.TrimRight(address, "/") + "/v1/" + strings.TrimLeft(path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := firstNonEmpty(v.Namespace, os.Getenv("VAULT_NAMESPACE")); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	client := v.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read vault response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %s for %s", resp.Status, path)
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("failed to parse vault response: %w", err)
+	}
+
+	data := secret.Data
+	// KV v2 nests the secret under data.data next to data.metadata
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = nested
+		}
+	}
+
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key %q not found in vault secret %s", key, path)
+	}
+
+	return stringValue(value), nil
+}
+
+func stringValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
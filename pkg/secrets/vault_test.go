@@ -0,0 +1,101 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newVaultServer serves KV v1 and v2 responses and checks the token and namespace headers
+func newVaultServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		if r.Header.Get("X-Vault-Namespace") != "team" {
+			http.Error(w, `{"errors":["wrong namespace"]}`, http.StatusBadRequest)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/data/db":
+			w.Write([]byte(`{"data":{"data":{"password":"kv2-pass","port":5432},"metadata":{"version":3}}}`))
+		case "/v1/kv/db":
+			w.Write([]byte(`{"data":{"password":"kv1-pass"}}`))
+		case "/v1/kv/nested":
+			// A KV v1 secret that happens to have a "data" key is not unwrapped
+			w.Write([]byte(`{"data":{"data":{"password":"inner"},"password":"outer"}}`))
+		case "/v1/kv/garbage":
+			w.Write([]byte(`not json`))
+		default:
+			http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVaultResolver(t *testing.T) {
+	server := newVaultServer(t)
+	resolver := &VaultResolver{Address: server.URL + "/", Token: "test-token", Namespace: "team"}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{ref: "secret/data/db#password", want: "kv2-pass"},
+		{ref: "/secret/data/db#port", want: "5432"},
+		{ref: "kv/db#password", want: "kv1-pass"},
+		{ref: "kv/nested#password", want: "outer"},
+		{ref: "kv/db", wantErr: "must include a #key"},
+		{ref: "kv/db#user", wantErr: `key "user" not found`},
+		{ref: "kv/missing#password", wantErr: "404"},
+		{ref: "kv/garbage#password", wantErr: "failed to parse vault response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := resolver.Resolve(context.Background(), tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Resolve(%q) error = %v, want one containing %q", tt.ref, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve(%q) error = %v", tt.ref, err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVaultResolverEnvironment(t *testing.T) {
+	server := newVaultServer(t)
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "test-token")
+	t.Setenv("VAULT_NAMESPACE", "team")
+
+	got, err := Resolve(context.Background(), "vault:secret/data/db#password")
+	if err != nil || got != "kv2-pass" {
+		t.Fatalf("Resolve = %q, %v, want kv2-pass", got, err)
+	}
+
+	t.Setenv("VAULT_TOKEN", "wrong")
+	if _, err := Resolve(context.Background(), "vault:secret/data/db#password"); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Resolve with a bad token error = %v, want a 403", err)
+	}
+
+	t.Setenv("VAULT_TOKEN", "")
+	if _, err := Resolve(context.Background(), "vault:secret/data/db#password"); err == nil || !strings.Contains(err.Error(), "VAULT_TOKEN") {
+		t.Errorf("Resolve without a token error = %v, want one naming VAULT_TOKEN", err)
+	}
+}
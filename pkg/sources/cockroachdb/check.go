@@ -0,0 +1,91 @@
+package cockroachdb
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+)
+
+// Probe checks that the cockroach CLI is installed, that the credentials work, that the user
+// may run the backup method and how large the database is
+func (d *Dumper) Probe(ctx context.Context) []backup.Check {
+	var checks []backup.Check
+	method := d.method()
+	switch {
+	case method != MethodBackup && method != MethodDump:
+		return append(checks, backup.Check{Name: "Options", Err: fmt.Errorf("unsupported CockroachDB backup method %q (expected %s or %s)", d.Method, MethodBackup, MethodDump)})
+	case method == MethodDump && d.Database == "":
+		return append(checks, backup.Check{Name: "Options", Err: fmt.Errorf("a database name is required for cockroach dump")})
+	}
+
+	tool := backup.ToolCheck(ctx, "cockroach", "", "version")
+	checks = append(checks, tool)
+	if tool.Err != nil {
+		return checks
+	}
+
+	user, err := d.queryValue(ctx, "SELECT current_user()")
+	if err != nil {
+		return append(checks, backup.Check{Name: "Authentication", Err: err})
+	}
+	checks = append(checks, backup.Check{Name: "Authentication", Detail: "connected as " + user})
+
+	if method == MethodBackup {
+		privileges := backup.Check{Name: "Privileges", Detail: user + " is an admin"}
+		if admin, err := d.queryValue(ctx, "SELECT crdb_internal.is_admin()"); err != nil {
+			privileges.Err = err
+		} else if admin != "true" {
+			privileges.Detail = ""
+			privileges.Err = fmt.Errorf("%s is not an admin: BACKUP needs the admin role or, since v22.2, the BACKUP privilege", user)
+			privileges.Warning = true
+		}
+		checks = append(checks, privileges)
+
+		externDir := backup.Check{Name: "External I/O directory", Detail: d.ExternDir}
+		if d.ExternDir == "" {
+			externDir.Err = fmt.Errorf("--extern-dir is required for BACKUP-based dumps")
+		} else if info, err := os.Stat(d.ExternDir); err != nil {
+			externDir.Err = err
+		} else if !info.IsDir() {
+			externDir.Err = fmt.Errorf("%s is not a directory", d.ExternDir)
+		}
+		checks = append(checks, externDir)
+	}
+
+	return append(checks, backup.SizeCheck(d.EstimateSize(ctx)))
+}
+
+// EstimateSize returns the size of the database's ranges, before replication
+func (d *Dumper) EstimateSize(ctx context.Context) (int64, error) {
+	if d.Database == "" {
+		return 0, fmt.Errorf("only available for a single database")
+	}
+
+	output, err := d.queryValue(ctx, fmt.Sprintf("SELECT COALESCE(sum(range_size), 0)::INT8 FROM [SHOW RANGES FROM DATABASE %s WITH DETAILS]", quoteIdentifier(d.Database)))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(output, 10, 64)
+}
+
+// queryValue runs a query returning a single value and reads it from the CSV output
+func (d *Dumper) queryValue(ctx context.Context, query string) (string, error) {
+	output, err := d.runSQL(ctx, d.Database, query, nil)
+	if err != nil {
+		return "", err
+	}
+
+	records, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	if err != nil {
+		return "", fmt.Errorf("failed to parse cockroach sql output: %w", err)
+	}
+	if len(records) < 2 || len(records[1]) == 0 {
+		return "", fmt.Errorf("cockroach sql returned no rows for %s", query)
+	}
+	return records[1][0], nil
+}
@@ -0,0 +1,332 @@
+package cockroachdb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// Backup methods
+const (
+	// MethodBackup runs BACKUP ... INTO 'nodelocal://...' and archives the files the node wrote
+	MethodBackup = "backup"
+	// MethodDump streams SQL from `cockroach dump` (removed in CockroachDB v20.2)
+	MethodDump = "dump"
+)
+
+// Connection holds the settings shared by the dumper and restorer
+type Connection struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	TLS      backup.TLSConfig
+}
+
+type Dumper struct {
+	Connection
+	Database string
+	Method   string
+
+	// ExternDir is the local path of the node's external I/O directory (<store>/extern),
+	// where BACKUP writes nodelocal://<NodeID>/ files. The CLI must run on that node.
+	ExternDir string
+	NodeID    int
+}
+
+func (d *Dumper) CreateBackupStream(ctx context.Context) (io.ReadCloser, error) {
+	switch d.method() {
+	case MethodBackup:
+		return d.createNodelocalBackup(ctx)
+	case MethodDump:
+		return d.createDump(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported CockroachDB backup method %q (expected %s or %s)", d.Method, MethodBackup, MethodDump)
+	}
+}
+
+func (d *Dumper) createNodelocalBackup(ctx context.Context) (io.ReadCloser, error) {
+	if d.ExternDir == "" {
+		return nil, fmt.Errorf("--extern-dir is required for BACKUP-based dumps")
+	}
+
+	subdir := fmt.Sprintf("dbbackup-%s-%d", d.GetDatabaseName(), time.Now().UnixNano())
+	statement := d.backupStatement(subdir)
+
+	logger.Infof("📦 Running %s", statement)
+	if _, err := d.runSQL(ctx, "", statement, nil); err != nil {
+		return nil, err
+	}
+
+	backupDir := filepath.Join(d.ExternDir, subdir)
+	if _, err := os.Stat(backupDir); err != nil {
+		return nil, fmt.Errorf("backup files not found in %s (is --extern-dir the extern directory of node %d on this machine?): %w", backupDir, d.nodeID(), err)
+	}
+
+	return backup.StreamTarDirectory(backupDir, func() { os.RemoveAll(backupDir) }), nil
+}
+
+// backupStatement returns the BACKUP statement writing into subdir of the node's extern directory
+func (d *Dumper) backupStatement(subdir string) string {
+	location := nodelocalURI(d.NodeID, subdir)
+	if d.Database != "" {
+		return fmt.Sprintf("BACKUP DATABASE %s INTO %s", quoteIdentifier(d.Database), quoteString(location))
+	}
+	return fmt.Sprintf("BACKUP INTO %s", quoteString(location))
+}
+
+func (d *Dumper) createDump(ctx context.Context) (io.ReadCloser, error) {
+	if d.Database == "" {
+		return nil, fmt.Errorf("a database name is required for cockroach dump")
+	}
+
+	cmd, err := d.dumpCommand(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create pipes for both stdout and stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		stdout.Close()
+		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+
+	// Start the command
+	if err := backup.StartCommand(cmd); err != nil {
+		stdout.Close()
+		stderr.Close()
+		return nil, fmt.Errorf("failed to start cockroach dump: %w", err)
+	}
+
+	// Return a wrapper that will wait for the command to finish when closed
+	return &cmdReader{
+		reader: stdout,
+		stderr: stderr,
+		cmd:    cmd,
+	}, nil
+}
+
+// runSQL executes statements with `cockroach sql`, reading additional input from stdin if given
+func (c *Connection) runSQL(ctx context.Context, database, statement string, stdin io.Reader) (string, error) {
+	cmd, err := c.sqlCommand(ctx, database, statement)
+	if err != nil {
+		return "", err
+	}
+	cmd.Stdin = stdin
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("cockroach sql failed: %w\nOutput: %s", err, string(output))
+	}
+
+	return string(output), nil
+}
+
+// sqlCommand builds a `cockroach sql` command for statements
+func (c *Connection) sqlCommand(ctx context.Context, database, statement string) (*exec.Cmd, error) {
+	env, err := c.env(database)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"sql", "--format=csv"}
+	if statement != "" {
+		args = append(args, "-e", statement)
+	}
+
+	cmd := exec.CommandContext(ctx, "cockroach", args...)
+	cmd.Env = env
+	return cmd, nil
+}
+
+// dumpCommand builds the `cockroach dump` command
+func (d *Dumper) dumpCommand(ctx context.Context) (*exec.Cmd, error) {
+	env, err := d.env("")
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, "cockroach", "dump", d.Database)
+	cmd.Env = env
+	return cmd, nil
+}
+
+// DescribeCommand returns the BACKUP statement or the cockroach dump command, with the
+// password in COCKROACH_URL redacted
+func (d *Dumper) DescribeCommand() ([]string, error) {
+	switch d.method() {
+	case MethodBackup:
+		if d.ExternDir == "" {
+			return nil, fmt.Errorf("--extern-dir is required for BACKUP-based dumps")
+		}
+		subdir := fmt.Sprintf("dbbackup-%s-<timestamp>", d.GetDatabaseName())
+		cmd, err := d.sqlCommand(context.Background(), "", d.backupStatement(subdir))
+		if err != nil {
+			return nil, err
+		}
+		return []string{backup.FormatCommand(cmd), "then archives " + filepath.Join(d.ExternDir, subdir)}, nil
+	case MethodDump:
+		if d.Database == "" {
+			return nil, fmt.Errorf("a database name is required for cockroach dump")
+		}
+		cmd, err := d.dumpCommand(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return []string{backup.FormatCommand(cmd)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported CockroachDB backup method %q (expected %s or %s)", d.Method, MethodBackup, MethodDump)
+	}
+}
+
+// env returns the environment for the cockroach CLI. The connection URL, which carries the
+// password, is passed via COCKROACH_URL so it is not visible in ps output.
+func (c *Connection) env(database string) ([]string, error) {
+	query := url.Values{}
+
+	mode, err := c.TLS.NormalizedMode()
+	if err != nil {
+		return nil, err
+	}
+	if mode != "" {
+		query.Set("sslmode", mode)
+	}
+	if c.TLS.CAFile != "" {
+		query.Set("sslrootcert", c.TLS.CAFile)
+	}
+	if c.TLS.CertFile != "" {
+		query.Set("sslcert", c.TLS.CertFile)
+	}
+	if c.TLS.KeyFile != "" {
+		query.Set("sslkey", c.TLS.KeyFile)
+	}
+
+	uri := url.URL{
+		Scheme:   "postgresql",
+		Host:     net.JoinHostPort(c.Host, strconv.Itoa(c.Port)),
+		Path:     "/" + database,
+		RawQuery: query.Encode(),
+	}
+	if c.Username != "" {
+		uri.User = url.User(c.Username)
+		if c.Password != "" {
+			uri.User = url.UserPassword(c.Username, c.Password)
+		}
+	}
+
+	return append(os.Environ(), "COCKROACH_URL="+uri.String()), nil
+}
+
+func (d *Dumper) method() string {
+	if d.Method == "" {
+		return MethodBackup
+	}
+	return d.Method
+}
+
+func (d *Dumper) nodeID() int {
+	if d.NodeID == 0 {
+		return 1
+	}
+	return d.NodeID
+}
+
+// nodelocalURI returns the nodelocal:// location of subdir on a node
+func nodelocalURI(nodeID int, subdir string) string {
+	if nodeID == 0 {
+		nodeID = 1
+	}
+	return fmt.Sprintf("nodelocal://%d/%s", nodeID, subdir)
+}
+
+// quoteString returns s as a SQL string literal
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// quoteIdentifier returns s as a SQL identifier
+func quoteIdentifier(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+type cmdReader struct {
+	reader io.ReadCloser
+	stderr io.ReadCloser
+	cmd    *exec.Cmd
+}
+
+func (cr *cmdReader) Read(p []byte) (int, error) {
+	return cr.reader.Read(p)
+}
+
+func (cr *cmdReader) Close() error {
+	cr.reader.Close()
+
+	// Read remaining stderr to capture any error messages
+	remainingStderr, _ := io.ReadAll(cr.stderr)
+	cr.stderr.Close()
+
+	if err := cr.cmd.Wait(); err != nil {
+		if len(remainingStderr) > 0 {
+			return fmt.Errorf("cockroach dump failed: %w\nOutput: %s", err, string(remainingStderr))
+		}
+		return fmt.Errorf("cockroach dump failed: %w", err)
+	}
+
+	return nil
+}
+
+// GetFileExtension returns the file extension for CockroachDB backups
+func (d *Dumper) GetFileExtension() string {
+	if d.method() == MethodDump {
+		return ".sql"
+	}
+	return ".tar"
+}
+
+// GetDatabaseType returns the database type
+func (d *Dumper) GetDatabaseType() string {
+	return "cockroachdb"
+}
+
+// GetBackupMetadata records whether the backup holds one database or the whole cluster
+func (d *Dumper) GetBackupMetadata() map[string]string {
+	scope := "database"
+	if d.Database == "" {
+		scope = "cluster"
+	}
+	return map[string]string{
+		"cockroach_method": d.method(),
+		"backup_scope":     scope,
+	}
+}
+
+// GetDatabaseName returns the database name
+func (d *Dumper) GetDatabaseName() string {
+	if d.Database == "" {
+		return "cluster"
+	}
+	return d.Database
+}
+
+// GetDatabaseHost returns the database host for object key templates
+func (d *Dumper) GetDatabaseHost() string {
+	return d.Host
+}
@@ -0,0 +1,74 @@
+package cockroachdb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// Restorer loads CockroachDB backups
+type Restorer struct {
+	Connection
+	Database string
+
+	// ExternDir and NodeID locate the node's nodelocal:// directory, as for the dumper
+	ExternDir string
+	NodeID    int
+}
+
+// LoadDump applies a (optionally gzip-compressed) SQL dump with `cockroach sql`
+func (r *Restorer) LoadDump(ctx context.Context, reader io.Reader) error {
+	input, closeInput, err := backup.MaybeGunzip(reader)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	_, err = r.runSQL(ctx, r.Database, "", input)
+	return err
+}
+
+// RestoreBackup unpacks a BACKUP archive into the node's extern directory and runs RESTORE.
+// sourceDatabase is the database the backup was taken from, or empty for a cluster backup.
+func (r *Restorer) RestoreBackup(ctx context.Context, reader io.Reader, sourceDatabase string) error {
+	if r.ExternDir == "" {
+		return fmt.Errorf("--extern-dir is required to restore a BACKUP archive")
+	}
+
+	input, closeInput, err := backup.MaybeGunzip(reader)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	subdir := fmt.Sprintf("dbbackup-restore-%d", time.Now().UnixNano())
+	backupDir := filepath.Join(r.ExternDir, subdir)
+	defer os.RemoveAll(backupDir)
+
+	if err := backup.ExtractTar(input, backupDir); err != nil {
+		return err
+	}
+
+	location := quoteString(nodelocalURI(r.NodeID, subdir))
+
+	var statement string
+	switch {
+	case sourceDatabase == "":
+		statement = fmt.Sprintf("RESTORE FROM LATEST IN %s", location)
+	case r.Database == "" || r.Database == sourceDatabase:
+		statement = fmt.Sprintf("RESTORE DATABASE %s FROM LATEST IN %s", quoteIdentifier(sourceDatabase), location)
+	default:
+		statement = fmt.Sprintf("RESTORE DATABASE %s FROM LATEST IN %s WITH new_db_name = %s",
+			quoteIdentifier(sourceDatabase), location, quoteString(r.Database))
+	}
+
+	logger.Infof("📦 Running %s", statement)
+	_, err = r.runSQL(ctx, "", statement, nil)
+	return err
+}
@@ -0,0 +1,187 @@
+package dburl
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// URL is a parsed database connection string
+type URL struct {
+	Engine   string // postgres, mysql, mongodb, redis, sqlserver or elasticsearch
+	Scheme   string
+	Hosts    []string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Database string
+	Options  map[string]string
+	SRV      bool // mongodb+srv://
+	TLS      bool // rediss:// or https://
+}
+
+var engines = map[string]string{
+	"postgres":    "postgres",
+	"postgresql":  "postgres",
+	"mysql":       "mysql",
+	"mariadb":     "mysql",
+	"mongodb":     "mongodb",
+	"mongodb+srv": "mongodb",
+	"redis":       "redis",
+	"rediss":      "redis",
+	"sqlserver":   "sqlserver",
+	"http":        "elasticsearch",
+	"https":       "elasticsearch",
+}
+
+// Parse parses a postgres://, mysql://, mariadb://, mongodb://, mongodb+srv://, redis(s)://,
+// sqlserver:// or Elasticsearch/OpenSearch http(s):// URL.
+// Credentials must be percent-encoded if they contain reserved characters, although a
+// password containing '@' is tolerated since the last '@' separates credentials from hosts.
+func Parse(raw string) (*URL, error) {
+	scheme, rest, found := strings.Cut(raw, "://")
+	if !found {
+		return nil, fmt.Errorf("invalid database URL: missing scheme")
+	}
+
+	engine, ok := engines[strings.ToLower(scheme)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported database URL scheme %q", scheme)
+	}
+
+	// Split off userinfo ourselves so multi-host lists and unescaped '@' or '/' in
+	// passwords survive: credentials end at the last '@' before the query string
+	head, query, _ := strings.Cut(rest, "?")
+	userinfo, hostList := "", head
+	if at := strings.LastIndex(head, "@"); at >= 0 {
+		userinfo, hostList = head[:at], head[at+1:]
+	}
+
+	pathAndQuery := ""
+	if slash := strings.Index(hostList, "/"); slash >= 0 {
+		hostList, pathAndQuery = hostList[:slash], hostList[slash:]
+	}
+	if query != "" {
+		pathAndQuery += "?" + query
+	}
+
+	u := &URL{
+		Engine:  engine,
+		Scheme:  strings.ToLower(scheme),
+		Options: map[string]string{},
+		SRV:     strings.EqualFold(scheme, "mongodb+srv"),
+		TLS:     strings.EqualFold(scheme, "rediss") || strings.EqualFold(scheme, "https"),
+	}
+
+	if userinfo != "" {
+		username, password, _ := strings.Cut(userinfo, ":")
+		var err error
+		if u.Username, err = url.PathUnescape(username); err != nil {
+			return nil, fmt.Errorf("invalid username in database URL: %w", err)
+		}
+		if u.Password, err = url.PathUnescape(password); err != nil {
+			return nil, fmt.Errorf("invalid password in database URL: %w", err)
+		}
+	}
+
+	if hostList != "" {
+		u.Hosts = strings.Split(hostList, ",")
+	}
+
+	if len(u.Hosts) > 0 {
+		host, port, err := splitHostPort(u.Hosts[0])
+		if err != nil {
+			return nil, err
+		}
+		u.Host, u.Port = host, port
+	}
+
+	parsed, err := url.Parse(u.Scheme + "://placeholder" + pathAndQuery)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database URL: %w", err)
+	}
+	u.Database = strings.TrimPrefix(parsed.Path, "/")
+	for key, values := range parsed.Query() {
+		if len(values) > 0 {
+			u.Options[key] = values[len(values)-1]
+		}
+	}
+
+	return u, nil
+}
+
+// String re-serializes the URL with credentials properly escaped
+func (u *URL) String() string {
+	var b strings.Builder
+	b.WriteString(u.Scheme)
+	b.WriteString("://")
+
+	if u.Username != "" {
+		userinfo := url.User(u.Username)
+		if u.Password != "" {
+			userinfo = url.UserPassword(u.Username, u.Password)
+		}
+		b.WriteString(userinfo.String() + "@")
+	}
+
+	b.WriteString(strings.Join(u.Hosts, ","))
+
+	if u.Database != "" {
+		b.WriteString("/" + url.PathEscape(u.Database))
+	} else if len(u.Options) > 0 {
+		b.WriteString("/")
+	}
+
+	if len(u.Options) > 0 {
+		query := url.Values{}
+		for key, value := range u.Options {
+			query.Set(key, value)
+		}
+		b.WriteString("?" + query.Encode())
+	}
+
+	return b.String()
+}
+
+// Redacted returns the URL with the password masked
+func (u *URL) Redacted() string {
+	if u.Password == "" {
+		return u.String()
+	}
+	masked := *u
+	masked.Password = "xxxxx"
+	return masked.String()
+}
+
+func splitHostPort(hostPort string) (string, int, error) {
+	// Bracketed IPv6 literal
+	if strings.HasPrefix(hostPort, "[") {
+		end := strings.Index(hostPort, "]")
+		if end < 0 {
+			return "", 0, fmt.Errorf("invalid host %q in database URL", hostPort)
+		}
+		host, rest := hostPort[1:end], hostPort[end+1:]
+		if rest == "" {
+			return host, 0, nil
+		}
+		port, err := strconv.Atoi(strings.TrimPrefix(rest, ":"))
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid port in database URL host %q", hostPort)
+		}
+		return host, port, nil
+	}
+
+	host, portString, found := strings.Cut(hostPort, ":")
+	if !found || portString == "" {
+		return host, 0, nil
+	}
+
+	port, err := strconv.Atoi(portString)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port in database URL host %q", hostPort)
+	}
+
+	return host, port, nil
+}
@@ -0,0 +1,116 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+)
+
+// Probe checks that the cluster can be reached with the credentials, that the selected
+// indices exist and can be read, and how much storage they use
+func (d *Dumper) Probe(ctx context.Context) []backup.Check {
+	var checks []backup.Check
+	method := d.method()
+	if method != MethodScroll && method != MethodSnapshot {
+		return append(checks, backup.Check{Name: "Options", Err: fmt.Errorf("unsupported Elasticsearch backup method %q (expected %s or %s)", d.Method, MethodScroll, MethodSnapshot)})
+	}
+
+	distribution, version, err := d.serverVersion(ctx)
+	if err != nil {
+		return append(checks, backup.Check{Name: "Authentication", Err: fmt.Errorf("failed to connect to %s: %w", d.baseURL(), err)})
+	}
+	checks = append(checks, backup.Check{Name: "Authentication", Detail: fmt.Sprintf("%s %s at %s", distribution, version, d.baseURL())})
+
+	indices, err := d.resolveIndices(ctx)
+	if err == nil && len(indices) == 0 {
+		err = fmt.Errorf("no indices match %s", strings.Join(d.includePatterns(), ","))
+	}
+	if err != nil {
+		return append(checks, backup.Check{Name: "Indices", Err: err})
+	}
+	checks = append(checks, backup.Check{Name: "Indices", Detail: fmt.Sprintf("%d index(es)", len(indices))})
+
+	checks = append(checks, d.checkPrivileges(ctx, method))
+
+	if method == MethodSnapshot {
+		check := backup.Check{Name: "Snapshot repository directory"}
+		if d.RepoDir == "" {
+			check.Err = fmt.Errorf("--repo-dir is required for snapshot backups")
+		} else if info, err := os.Stat(localRepoDir(d.RepoDir, d.LocalRepoDir)); err != nil {
+			check.Err = fmt.Errorf("snapshot files are read from %s: %w", localRepoDir(d.RepoDir, d.LocalRepoDir), err)
+		} else if !info.IsDir() {
+			check.Err = fmt.Errorf("%s is not a directory", localRepoDir(d.RepoDir, d.LocalRepoDir))
+		} else {
+			check.Detail = localRepoDir(d.RepoDir, d.LocalRepoDir)
+		}
+		checks = append(checks, check)
+	}
+
+	return append(checks, backup.SizeCheck(d.storeSize(ctx, indices)))
+}
+
+// checkPrivileges asks the security API whether the user holds the privileges the backup
+// method needs. Clusters without security, and OpenSearch, cannot answer: that is a warning.
+func (d *Dumper) checkPrivileges(ctx context.Context, method string) backup.Check {
+	body := map[string]interface{}{
+		"index": []map[string]interface{}{{
+			"names":      d.includePatterns(),
+			"privileges": []string{"read", "view_index_metadata"},
+		}},
+	}
+	if method == MethodSnapshot {
+		// Registering a repository per backup needs the manage cluster privilege
+		body = map[string]interface{}{"cluster": []string{"manage"}}
+	}
+
+	var result struct {
+		HasAllRequested bool `json:"has_all_requested"`
+	}
+	if err := d.request(ctx, http.MethodPost, "/_security/user/_has_privileges", body, &result); err != nil {
+		return backup.Check{Name: "Privileges", Err: fmt.Errorf("could not verify privileges: %w", err), Warning: true}
+	}
+
+	if !result.HasAllRequested {
+		if method == MethodSnapshot {
+			return backup.Check{Name: "Privileges", Err: fmt.Errorf("the manage cluster privilege is required to register snapshot repositories")}
+		}
+		return backup.Check{Name: "Privileges", Err: fmt.Errorf("read and view_index_metadata are required on %s", strings.Join(d.includePatterns(), ","))}
+	}
+	return backup.Check{Name: "Privileges", Detail: "all required privileges held"}
+}
+
+// EstimateSize returns the primary store size of the selected indices
+func (d *Dumper) EstimateSize(ctx context.Context) (int64, error) {
+	indices, err := d.resolveIndices(ctx)
+	if err != nil || len(indices) == 0 {
+		return 0, err
+	}
+	return d.storeSize(ctx, indices)
+}
+
+// storeSize sums the primary store size of the indices, leaving out replicas
+func (d *Dumper) storeSize(ctx context.Context, indices []string) (int64, error) {
+	var rows []struct {
+		StoreSize string `json:"pri.store.size"`
+	}
+	expression := url.PathEscape(strings.Join(indices, ","))
+	if err := d.request(ctx, http.MethodGet, "/_cat/indices/"+expression+"?format=json&h=pri.store.size&bytes=b", nil, &rows); err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, row := range rows {
+		size, err := strconv.ParseInt(row.StoreSize, 10, 64)
+		if err != nil {
+			continue
+		}
+		total += size
+	}
+	return total, nil
+}
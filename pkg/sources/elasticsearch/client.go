@@ -0,0 +1,186 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+)
+
+// Connection holds the REST endpoint and credentials of an Elasticsearch or OpenSearch cluster
+type Connection struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	APIKey   string // Elasticsearch API key, sent instead of basic auth
+	TLS      backup.TLSConfig
+
+	HTTPClient *http.Client
+}
+
+// apiError is returned for non-2xx responses
+type apiError struct {
+	Method string
+	Path   string
+	Status int
+	Body   string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s %s returned %d: %s", e.Method, e.Path, e.Status, e.Body)
+}
+
+func isNotFound(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound
+}
+
+// baseURL returns the cluster URL. HTTPS is used whenever TLS is required.
+func (c *Connection) baseURL() string {
+	scheme := "http"
+	if c.TLS.Required() {
+		scheme = "https"
+	}
+	return scheme + "://" + net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+func (c *Connection) client() (*http.Client, error) {
+	if c.HTTPClient != nil {
+		return c.HTTPClient, nil
+	}
+	if !c.TLS.Required() {
+		return &http.Client{Timeout: 10 * time.Minute}, nil
+	}
+
+	tlsConfig, err := c.TLS.ClientConfig(c.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	c.HTTPClient = &http.Client{Transport: transport, Timeout: 10 * time.Minute}
+	return c.HTTPClient, nil
+}
+
+// do sends a request and returns the response body, failing on non-2xx status codes
+func (c *Connection) do(ctx context.Context, method, path string, body io.Reader, contentType string) ([]byte, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL()+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	switch {
+	case c.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+c.APIKey)
+	case c.Username != "":
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response of %s %s: %w", method, path, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &apiError{Method: method, Path: path, Status: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+	}
+
+	return data, nil
+}
+
+// request sends an optional JSON body and decodes the JSON response into result, if given
+func (c *Connection) request(ctx context.Context, method, path string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	data, err := c.do(ctx, method, path, reader, "application/json")
+	if err != nil {
+		return err
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("failed to parse response of %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
+
+// serverVersion returns the distribution and version number of the cluster
+func (c *Connection) serverVersion(ctx context.Context) (string, string, error) {
+	var info struct {
+		Version struct {
+			Number       string `json:"number"`
+			Distribution string `json:"distribution"`
+		} `json:"version"`
+	}
+	if err := c.request(ctx, http.MethodGet, "/", nil, &info); err != nil {
+		return "", "", err
+	}
+
+	distribution := info.Version.Distribution
+	if distribution == "" {
+		distribution = "elasticsearch"
+	}
+	return distribution, info.Version.Number, nil
+}
+
+// registerRepository registers a shared filesystem snapshot repository
+func (c *Connection) registerRepository(ctx context.Context, name, location string, readonly bool) error {
+	settings := map[string]interface{}{"location": location}
+	if readonly {
+		settings["readonly"] = true
+	}
+
+	body := map[string]interface{}{"type": "fs", "settings": settings}
+	if err := c.request(ctx, http.MethodPut, "/_snapshot/"+url.PathEscape(name), body, nil); err != nil {
+		return fmt.Errorf("failed to register snapshot repository at %s (is it listed in path.repo?): %w", location, err)
+	}
+
+	return nil
+}
+
+// unregisterRepository removes a snapshot repository registration, leaving its files in place
+func (c *Connection) unregisterRepository(ctx context.Context, name string) error {
+	return c.request(ctx, http.MethodDelete, "/_snapshot/"+url.PathEscape(name), nil, nil)
+}
+
+// indexPath returns the escaped URL path of an index
+func indexPath(index string) string {
+	return "/" + url.PathEscape(index)
+}
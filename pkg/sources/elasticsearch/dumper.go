@@ -0,0 +1,413 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// Backup methods
+const (
+	MethodScroll   = "scroll"
+	MethodSnapshot = "snapshot"
+)
+
+// DefaultBatchSize is the number of documents fetched per scroll page or sent per bulk request
+const DefaultBatchSize = 1000
+
+// SnapshotName is the name of the snapshot taken into each backup's repository
+const SnapshotName = "dbbackup"
+
+// scrollTimeout keeps the search context alive between scroll pages
+const scrollTimeout = "5m"
+
+// record is one line of an NDJSON export. An index starts with a line holding its settings,
+// mappings and aliases, followed by one line per document.
+type record struct {
+	Index    string          `json:"_index"`
+	ID       string          `json:"_id,omitempty"`
+	Routing  string          `json:"_routing,omitempty"`
+	Source   json.RawMessage `json:"_source,omitempty"`
+	Settings json.RawMessage `json:"_settings,omitempty"`
+	Mappings json.RawMessage `json:"_mappings,omitempty"`
+	Aliases  json.RawMessage `json:"_aliases,omitempty"`
+}
+
+// Dumper exports Elasticsearch or OpenSearch indices
+type Dumper struct {
+	Connection
+
+	// Indices are index names or wildcard patterns to include (default: all open indices)
+	Indices        []string
+	ExcludeIndices []string
+
+	Method    string
+	BatchSize int
+
+	// RepoDir is a directory listed in the cluster's path.repo setting, as seen by the nodes,
+	// and LocalRepoDir the same directory as mounted on this machine (default: RepoDir).
+	// Each snapshot backup registers a fresh filesystem repository below it.
+	RepoDir      string
+	LocalRepoDir string
+
+	indices      []string
+	documents    int64
+	distribution string
+	version      string
+}
+
+func (d *Dumper) CreateBackupStream(ctx context.Context) (io.ReadCloser, error) {
+	method := d.method()
+	if method != MethodScroll && method != MethodSnapshot {
+		return nil, fmt.Errorf("unsupported Elasticsearch backup method %q (expected %s or %s)", d.Method, MethodScroll, MethodSnapshot)
+	}
+
+	distribution, version, err := d.serverVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", d.baseURL(), err)
+	}
+	d.distribution, d.version = distribution, version
+	logger.Infof("🔌 Connected to %s %s at %s", distribution, version, d.baseURL())
+
+	indices, err := d.resolveIndices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("no indices match %s", strings.Join(d.includePatterns(), ","))
+	}
+	d.indices = indices
+	logger.Infof("📋 Backing up %d index(es)", len(indices))
+
+	if method == MethodSnapshot {
+		return d.createSnapshot(ctx)
+	}
+	return d.createScrollExport(ctx), nil
+}
+
+// resolveIndices expands the include patterns to open indices and drops excluded ones
+func (d *Dumper) resolveIndices(ctx context.Context) ([]string, error) {
+	expression := url.PathEscape(strings.Join(d.includePatterns(), ","))
+
+	var rows []struct {
+		Index string `json:"index"`
+	}
+	if err := d.request(ctx, http.MethodGet, "/_cat/indices/"+expression+"?format=json&h=index&expand_wildcards=open", nil, &rows); err != nil {
+		return nil, fmt.Errorf("failed to list indices: %w", err)
+	}
+
+	var indices []string
+	for _, row := range rows {
+		if !matchesAny(row.Index, d.ExcludeIndices) {
+			indices = append(indices, row.Index)
+		}
+	}
+	sort.Strings(indices)
+
+	return indices, nil
+}
+
+func (d *Dumper) includePatterns() []string {
+	if len(d.Indices) == 0 {
+		return []string{"*"}
+	}
+	return d.Indices
+}
+
+// createScrollExport streams every document of the selected indices as NDJSON
+func (d *Dumper) createScrollExport(ctx context.Context) io.ReadCloser {
+	pr, pw := io.Pipe()
+	reader := &exportReader{reader: pr, done: make(chan error, 1)}
+
+	go func() {
+		writer := bufio.NewWriterSize(pw, 256*1024)
+		err := d.exportIndices(ctx, writer)
+		if err == nil {
+			err = writer.Flush()
+		}
+		pw.CloseWithError(err)
+		reader.done <- err
+	}()
+
+	return reader
+}
+
+func (d *Dumper) exportIndices(ctx context.Context, writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+
+	for _, index := range d.indices {
+		var definitions map[string]struct {
+			Aliases  json.RawMessage `json:"aliases"`
+			Mappings json.RawMessage `json:"mappings"`
+			Settings json.RawMessage `json:"settings"`
+		}
+		if err := d.request(ctx, http.MethodGet, indexPath(index), nil, &definitions); err != nil {
+			return fmt.Errorf("failed to read definition of index %s: %w", index, err)
+		}
+		definition := definitions[index]
+
+		if err := encoder.Encode(record{
+			Index:    index,
+			Settings: definition.Settings,
+			Mappings: definition.Mappings,
+			Aliases:  definition.Aliases,
+		}); err != nil {
+			return err
+		}
+
+		count, err := d.scrollIndex(ctx, index, encoder)
+		if err != nil {
+			return fmt.Errorf("failed to export index %s: %w", index, err)
+		}
+		d.documents += count
+		logger.Infof("📄 Exported %d document(s) from %s", count, index)
+	}
+
+	return nil
+}
+
+type searchResponse struct {
+	ScrollID string `json:"_scroll_id"`
+	Hits     struct {
+		Hits []struct {
+			Index   string          `json:"_index"`
+			ID      string          `json:"_id"`
+			Routing string          `json:"_routing"`
+			Source  json.RawMessage `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// scrollIndex pages through an index in _doc order, the cheapest order for a full scan
+func (d *Dumper) scrollIndex(ctx context.Context, index string, encoder *json.Encoder) (int64, error) {
+	query := map[string]interface{}{
+		"size": d.batchSize(),
+		"sort": []string{"_doc"},
+	}
+
+	var page searchResponse
+	if err := d.request(ctx, http.MethodPost, indexPath(index)+"/_search?scroll="+scrollTimeout, query, &page); err != nil {
+		return 0, err
+	}
+
+	scrollID := page.ScrollID
+	defer func() {
+		if scrollID != "" {
+			d.request(context.Background(), http.MethodDelete, "/_search/scroll", map[string]interface{}{"scroll_id": []string{scrollID}}, nil)
+		}
+	}()
+
+	var count int64
+	for len(page.Hits.Hits) > 0 {
+		for _, hit := range page.Hits.Hits {
+			if err := encoder.Encode(record{Index: hit.Index, ID: hit.ID, Routing: hit.Routing, Source: hit.Source}); err != nil {
+				return count, err
+			}
+			count++
+		}
+
+		next := searchResponse{}
+		if err := d.request(ctx, http.MethodPost, "/_search/scroll", map[string]interface{}{"scroll": scrollTimeout, "scroll_id": scrollID}, &next); err != nil {
+			return count, err
+		}
+		page = next
+		if page.ScrollID != "" {
+			scrollID = page.ScrollID
+		}
+	}
+
+	return count, nil
+}
+
+// createSnapshot snapshots the selected indices into a new filesystem repository and
+// streams the repository directory as a tar archive
+func (d *Dumper) createSnapshot(ctx context.Context) (io.ReadCloser, error) {
+	if d.RepoDir == "" {
+		return nil, fmt.Errorf("--repo-dir is required for snapshot backups")
+	}
+
+	repository := fmt.Sprintf("dbbackup-%d", time.Now().UnixNano())
+	if err := d.registerRepository(ctx, repository, path.Join(d.RepoDir, repository), false); err != nil {
+		return nil, err
+	}
+	// The snapshot files stay in place when the registration is removed
+	defer d.unregisterRepository(context.Background(), repository)
+
+	localDir := filepath.Join(localRepoDir(d.RepoDir, d.LocalRepoDir), repository)
+
+	body := map[string]interface{}{
+		"indices":              strings.Join(d.indices, ","),
+		"include_global_state": false,
+	}
+	var result struct {
+		Snapshot struct {
+			State    string            `json:"state"`
+			Failures []json.RawMessage `json:"failures"`
+		} `json:"snapshot"`
+	}
+
+	logger.Infof("📸 Creating snapshot in repository %s", repository)
+	snapshotPath := "/_snapshot/" + url.PathEscape(repository) + "/" + SnapshotName + "?wait_for_completion=true"
+	if err := d.request(ctx, http.MethodPut, snapshotPath, body, &result); err != nil {
+		os.RemoveAll(localDir)
+		return nil, fmt.Errorf("snapshot failed: %w", err)
+	}
+	if result.Snapshot.State != "SUCCESS" {
+		os.RemoveAll(localDir)
+		return nil, fmt.Errorf("snapshot finished in state %s with %d shard failure(s)", result.Snapshot.State, len(result.Snapshot.Failures))
+	}
+
+	if _, err := os.Stat(localDir); err != nil {
+		return nil, fmt.Errorf("snapshot files not found in %s (is --local-repo-dir the --repo-dir directory as mounted here?): %w", localDir, err)
+	}
+
+	return backup.StreamTarDirectory(localDir, func() { os.RemoveAll(localDir) }), nil
+}
+
+// DescribeCommand lists the REST requests the backup makes. Credentials are sent in headers
+// and are not shown.
+func (d *Dumper) DescribeCommand() ([]string, error) {
+	auth := "no authentication"
+	switch {
+	case d.APIKey != "":
+		auth = "API key authentication"
+	case d.Username != "":
+		auth = "basic authentication as " + d.Username
+	}
+
+	steps := []string{
+		fmt.Sprintf("GET %s/ (%s)", d.baseURL(), auth),
+		fmt.Sprintf("GET %s/_cat/indices/%s?format=json&h=index&expand_wildcards=open", d.baseURL(), url.PathEscape(strings.Join(d.includePatterns(), ","))),
+	}
+	if len(d.ExcludeIndices) > 0 {
+		steps = append(steps, "excludes indices matching "+strings.Join(d.ExcludeIndices, ","))
+	}
+
+	switch d.method() {
+	case MethodScroll:
+		return append(steps,
+			fmt.Sprintf("GET %s/<index> for each index definition", d.baseURL()),
+			fmt.Sprintf("POST %s/<index>/_search?scroll=%s with size %d, then POST %s/_search/scroll until done", d.baseURL(), scrollTimeout, d.batchSize(), d.baseURL()),
+			"writes the documents as NDJSON",
+		), nil
+	case MethodSnapshot:
+		if d.RepoDir == "" {
+			return nil, fmt.Errorf("--repo-dir is required for snapshot backups")
+		}
+		return append(steps,
+			fmt.Sprintf("PUT %s/_snapshot/dbbackup-<timestamp> registering %s", d.baseURL(), path.Join(d.RepoDir, "dbbackup-<timestamp>")),
+			fmt.Sprintf("PUT %s/_snapshot/dbbackup-<timestamp>/%s?wait_for_completion=true", d.baseURL(), SnapshotName),
+			"then archives "+filepath.Join(localRepoDir(d.RepoDir, d.LocalRepoDir), "dbbackup-<timestamp>"),
+		), nil
+	default:
+		return nil, fmt.Errorf("unsupported Elasticsearch backup method %q (expected %s or %s)", d.Method, MethodScroll, MethodSnapshot)
+	}
+}
+
+// localRepoDir returns the repository directory as mounted on this machine
+func localRepoDir(repoDir, local string) string {
+	if local != "" {
+		return local
+	}
+	return filepath.FromSlash(repoDir)
+}
+
+func (d *Dumper) method() string {
+	if d.Method == "" {
+		return MethodScroll
+	}
+	return d.Method
+}
+
+func (d *Dumper) batchSize() int {
+	if d.BatchSize <= 0 {
+		return DefaultBatchSize
+	}
+	return d.BatchSize
+}
+
+// matchesAny reports whether name matches one of the wildcard patterns
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// exportReader streams the output of an export goroutine. Closing it waits for the goroutine.
+type exportReader struct {
+	reader *io.PipeReader
+	done   chan error
+}
+
+func (er *exportReader) Read(p []byte) (int, error) {
+	return er.reader.Read(p)
+}
+
+func (er *exportReader) Close() error {
+	er.reader.Close()
+
+	if err := <-er.done; err != nil && err != io.ErrClosedPipe {
+		return fmt.Errorf("export failed: %w", err)
+	}
+
+	return nil
+}
+
+// GetFileExtension returns the file extension for Elasticsearch backups
+func (d *Dumper) GetFileExtension() string {
+	if d.method() == MethodSnapshot {
+		return ".tar"
+	}
+	return ".ndjson"
+}
+
+// GetDatabaseType returns the database type
+func (d *Dumper) GetDatabaseType() string {
+	return "elasticsearch"
+}
+
+// GetDatabaseName returns the index name for single-index backups, or "indices"
+func (d *Dumper) GetDatabaseName() string {
+	if len(d.Indices) == 1 && !strings.ContainsAny(d.Indices[0], "*?,") {
+		return d.Indices[0]
+	}
+	return "indices"
+}
+
+// GetDatabaseHost returns the database host for object key templates
+func (d *Dumper) GetDatabaseHost() string {
+	return d.Host
+}
+
+// GetBackupMetadata records the cluster version and the indices in the backup
+func (d *Dumper) GetBackupMetadata() map[string]string {
+	metadata := map[string]string{
+		"es_method":       d.method(),
+		"es_distribution": d.distribution,
+		"es_version":      d.version,
+		"indices":         strings.Join(d.indices, ","),
+	}
+	if d.method() == MethodScroll {
+		metadata["documents"] = strconv.FormatInt(d.documents, 10)
+	} else {
+		metadata["snapshot"] = SnapshotName
+	}
+	return metadata
+}
@@ -0,0 +1,377 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeCluster is an in-memory stub of the REST endpoints the dumper and restorer use
+type fakeCluster struct {
+	t *testing.T
+
+	mu      sync.Mutex
+	indices map[string][]string // index name to document sources, in _doc order
+	created map[string]json.RawMessage
+	bulks   [][]string // action lines of each _bulk request
+	scrolls map[string]bool
+	// bulkError makes _bulk report a failed item for the document with this ID
+	bulkError string
+}
+
+func newFakeCluster(t *testing.T) (*fakeCluster, Connection) {
+	t.Helper()
+
+	cluster := &fakeCluster{
+		t:       t,
+		indices: map[string][]string{},
+		created: map[string]json.RawMessage{},
+		scrolls: map[string]bool{},
+	}
+	server := httptest.NewServer(cluster)
+	t.Cleanup(server.Close)
+
+	host, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	portNumber, _ := strconv.Atoi(port)
+	return cluster, Connection{Host: host, Port: portNumber, HTTPClient: server.Client()}
+}
+
+func (c *fakeCluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	route := r.Method + " " + r.URL.Path
+	switch {
+	case route == "GET /":
+		fmt.Fprint(w, `{"version":{"number":"8.13.0"}}`)
+
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/_cat/indices/"):
+		patterns := strings.Split(strings.TrimPrefix(r.URL.Path, "/_cat/indices/"), ",")
+		rows := []map[string]string{}
+		for name := range c.indices {
+			for _, pattern := range patterns {
+				if matched, _ := path.Match(pattern, name); matched {
+					rows = append(rows, map[string]string{"index": name})
+					break
+				}
+			}
+		}
+		json.NewEncoder(w).Encode(rows)
+
+	case route == "POST /_search/scroll":
+		var request struct {
+			ScrollID string `json:"scroll_id"`
+		}
+		json.Unmarshal(body, &request)
+		index, offset, _ := strings.Cut(request.ScrollID, "@")
+		from, _ := strconv.Atoi(offset)
+		c.writePage(w, index, from, 2)
+
+	case route == "DELETE /_search/scroll":
+		var request struct {
+			ScrollID []string `json:"scroll_id"`
+		}
+		json.Unmarshal(body, &request)
+		for _, id := range request.ScrollID {
+			index, _, _ := strings.Cut(id, "@")
+			c.scrolls[index] = true
+		}
+		fmt.Fprint(w, `{"succeeded":true}`)
+
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/_search"):
+		var query struct {
+			Size int `json:"size"`
+		}
+		json.Unmarshal(body, &query)
+		if query.Size != 2 {
+			c.t.Errorf("scroll page size = %d, want the batch size 2", query.Size)
+		}
+		c.writePage(w, strings.Trim(strings.TrimSuffix(r.URL.Path, "/_search"), "/"), 0, query.Size)
+
+	case route == "POST /_bulk":
+		if ct := r.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+			c.t.Errorf("bulk Content-Type = %q", ct)
+		}
+		c.bulk(w, body)
+
+	case r.Method == http.MethodGet:
+		name := strings.Trim(r.URL.Path, "/")
+		if _, ok := c.indices[name]; !ok {
+			http.Error(w, `{"error":"index_not_found_exception"}`, http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{%q:{"aliases":{},"mappings":{"properties":{"n":{"type":"long"}}},"settings":{"index":{"number_of_shards":"1","uuid":"abc","creation_date":"1"}}}}`, name)
+
+	case r.Method == http.MethodPut:
+		name := strings.Trim(r.URL.Path, "/")
+		c.created[name] = body
+		c.indices[name] = nil
+		fmt.Fprint(w, `{"acknowledged":true}`)
+
+	default:
+		c.t.Errorf("unexpected request %s", route)
+		http.Error(w, "unexpected", http.StatusBadRequest)
+	}
+}
+
+// writePage returns up to size documents of an index from offset, with a scroll ID
+// encoding the next offset
+func (c *fakeCluster) writePage(w http.ResponseWriter, index string, from, size int) {
+	docs := c.indices[index]
+	to := min(from+size, len(docs))
+	hits := []map[string]interface{}{}
+	for i := from; i < to; i++ {
+		hits = append(hits, map[string]interface{}{
+			"_index":  index,
+			"_id":     strconv.Itoa(i),
+			"_source": json.RawMessage(docs[i]),
+		})
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"_scroll_id": fmt.Sprintf("%s@%d", index, to),
+		"hits":       map[string]interface{}{"hits": hits},
+	})
+}
+
+func (c *fakeCluster) bulk(w http.ResponseWriter, body []byte) {
+	var actions []string
+	var items []string
+	failed := false
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		var action struct {
+			Index struct {
+				Index string `json:"_index"`
+				ID    string `json:"_id"`
+			} `json:"index"`
+		}
+		json.Unmarshal(scanner.Bytes(), &action)
+		actions = append(actions, scanner.Text())
+		if !scanner.Scan() {
+			c.t.Errorf("bulk action %s has no source line", actions[len(actions)-1])
+			break
+		}
+		source := scanner.Text()
+
+		if action.Index.ID == c.bulkError {
+			failed = true
+			items = append(items, fmt.Sprintf(`{"index":{"_index":%q,"_id":%q,"status":400,"error":{"type":"mapper_parsing_exception"}}}`, action.Index.Index, action.Index.ID))
+			continue
+		}
+		c.indices[action.Index.Index] = append(c.indices[action.Index.Index], source)
+		items = append(items, fmt.Sprintf(`{"index":{"_index":%q,"_id":%q,"status":201}}`, action.Index.Index, action.Index.ID))
+	}
+	c.bulks = append(c.bulks, actions)
+	fmt.Fprintf(w, `{"errors":%t,"items":[%s]}`, failed, strings.Join(items, ","))
+}
+
+func TestResolveIndices(t *testing.T) {
+	cluster, conn := newFakeCluster(t)
+	for _, name := range []string{"logs-2024", "logs-2025", "metrics", "logs-internal"} {
+		cluster.indices[name] = nil
+	}
+
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    []string
+	}{
+		{name: "all", want: []string{"logs-2024", "logs-2025", "logs-internal", "metrics"}},
+		{name: "include pattern", include: []string{"logs-*"}, want: []string{"logs-2024", "logs-2025", "logs-internal"}},
+		{name: "include and exclude", include: []string{"logs-*"}, exclude: []string{"*-internal"}, want: []string{"logs-2024", "logs-2025"}},
+		{name: "several includes", include: []string{"metrics", "logs-2025"}, want: []string{"logs-2025", "metrics"}},
+		{name: "no match", include: []string{"nothing*"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dumper := &Dumper{Connection: conn, Indices: tt.include, ExcludeIndices: tt.exclude}
+			got, err := dumper.resolveIndices(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("resolveIndices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScrollExport(t *testing.T) {
+	cluster, conn := newFakeCluster(t)
+	cluster.indices["a"] = []string{`{"n":1}`, `{"n":2}`, `{"n":3}`}
+	cluster.indices["b"] = []string{}
+	cluster.indices["skip"] = []string{`{"n":9}`}
+
+	dumper := &Dumper{Connection: conn, ExcludeIndices: []string{"skip"}, BatchSize: 2}
+	stream, err := dumper.CreateBackupStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("export has %d lines, want a definition per index and 3 documents:\n%s", len(lines), data)
+	}
+
+	var records []record
+	for _, line := range lines {
+		var rec record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("line %q is not JSON: %v", line, err)
+		}
+		records = append(records, rec)
+	}
+
+	// Each index starts with its definition, followed by its documents in _doc order
+	if records[0].Index != "a" || records[0].Source != nil || records[0].Mappings == nil || records[0].Settings == nil {
+		t.Errorf("first line = %s, want the definition of index a", lines[0])
+	}
+	for i, want := range []string{`{"n":1}`, `{"n":2}`, `{"n":3}`} {
+		rec := records[i+1]
+		if rec.Index != "a" || rec.ID != strconv.Itoa(i) || string(rec.Source) != want || rec.Settings != nil {
+			t.Errorf("line %d = %s, want document %d of a with source %s", i+2, lines[i+1], i, want)
+		}
+	}
+	if records[4].Index != "b" || records[4].Source != nil {
+		t.Errorf("last line = %s, want the definition of the empty index b", lines[4])
+	}
+
+	metadata := dumper.GetBackupMetadata()
+	if metadata["documents"] != "3" || metadata["indices"] != "a,b" {
+		t.Errorf("metadata = %v, want 3 documents from a,b", metadata)
+	}
+	var cleared []string
+	for index := range cluster.scrolls {
+		cleared = append(cleared, index)
+	}
+	sort.Strings(cleared)
+	if strings.Join(cleared, ",") != "a,b" {
+		t.Errorf("scroll contexts cleared for %v, want a and b", cleared)
+	}
+}
+
+func TestLoadNDJSON(t *testing.T) {
+	cluster, conn := newFakeCluster(t)
+
+	export := strings.Join([]string{
+		`{"_index":"a","_settings":{"index":{"number_of_shards":"1","uuid":"abc","creation_date":"1"}},"_mappings":{"properties":{"n":{"type":"long"}}},"_aliases":{"current":{}}}`,
+		`{"_index":"a","_id":"1","_source":{"n":1}}`,
+		`{"_index":"a","_id":"2","_routing":"r","_source":{"n":2}}`,
+		`{"_index":"a","_id":"3","_source":{"n":3}}`,
+		``,
+		`{"_index":"b","_mappings":{}}`,
+		`{"_index":"b","_id":"4","_source":{"n":4}}`,
+		`{"_index":"other","_mappings":{}}`,
+		`{"_index":"other","_id":"5","_source":{"n":5}}`,
+	}, "\n") + "\n"
+
+	restorer := &Restorer{Connection: conn, Indices: []string{"a", "b"}, IndexPrefix: "restored-", BatchSize: 2}
+	if err := restorer.LoadNDJSON(context.Background(), strings.NewReader(export)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Batches hold at most BatchSize documents and never span an index definition
+	var batchSizes []int
+	for _, actions := range cluster.bulks {
+		batchSizes = append(batchSizes, len(actions))
+	}
+	if fmt.Sprint(batchSizes) != "[2 1 1]" {
+		t.Errorf("bulk batch sizes = %v, want [2 1 1]", batchSizes)
+	}
+	if !strings.Contains(cluster.bulks[0][1], `"routing":"r"`) {
+		t.Errorf("bulk action %s does not keep the routing", cluster.bulks[0][1])
+	}
+
+	if got := len(cluster.indices["restored-a"]); got != 3 {
+		t.Errorf("restored-a has %d documents, want 3", got)
+	}
+	if got := len(cluster.indices["restored-b"]); got != 1 {
+		t.Errorf("restored-b has %d documents, want 1", got)
+	}
+	if _, ok := cluster.created["restored-other"]; ok {
+		t.Error("index other was restored although it does not match --index")
+	}
+
+	var created map[string]interface{}
+	if err := json.Unmarshal(cluster.created["restored-a"], &created); err != nil {
+		t.Fatal(err)
+	}
+	settings := created["settings"].(map[string]interface{})["index"].(map[string]interface{})
+	if _, ok := settings["uuid"]; ok {
+		t.Errorf("created index with cluster-managed settings %v", settings)
+	}
+	if _, ok := created["aliases"]; ok {
+		t.Error("aliases were carried over to a prefixed index")
+	}
+}
+
+func TestLoadNDJSONErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  string
+		bulkError string
+		export    string
+		wantErr   string
+	}{
+		{
+			name:    "not a record",
+			export:  "{\"_index\":\"a\",\"_mappings\":{}}\nnot json\n",
+			wantErr: "line 2 is not an export record",
+		},
+		{
+			name:    "record without an index",
+			export:  "{\"_id\":\"1\",\"_source\":{}}\n",
+			wantErr: "line 1 is not an export record",
+		},
+		{
+			name:     "existing index",
+			existing: "a",
+			export:   "{\"_index\":\"a\",\"_mappings\":{}}\n",
+			wantErr:  "index a already exists",
+		},
+		{
+			name:      "failed document",
+			bulkError: "2",
+			export:    "{\"_index\":\"a\",\"_mappings\":{}}\n{\"_index\":\"a\",\"_id\":\"1\",\"_source\":{}}\n{\"_index\":\"a\",\"_id\":\"2\",\"_source\":{}}\n",
+			wantErr:   "failed to index document 2 in a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster, conn := newFakeCluster(t)
+			cluster.bulkError = tt.bulkError
+			if tt.existing != "" {
+				cluster.indices[tt.existing] = nil
+			}
+
+			restorer := &Restorer{Connection: conn}
+			err := restorer.LoadNDJSON(context.Background(), strings.NewReader(tt.export))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("LoadNDJSON() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// unrestorableSettings are index settings the cluster assigns itself and rejects on index creation
+var unrestorableSettings = []string{"uuid", "creation_date", "provided_name", "version", "resize", "history"}
+
+// Restorer loads Elasticsearch or OpenSearch backups
+type Restorer struct {
+	Connection
+
+	// Indices restricts the restore to these index names or wildcard patterns (default: all)
+	Indices []string
+
+	// IndexPrefix is prepended to the names of restored indices, e.g. "restored-"
+	IndexPrefix string
+
+	BatchSize int
+
+	// RepoDir and LocalRepoDir locate a path.repo directory, as for the dumper
+	RepoDir      string
+	LocalRepoDir string
+}
+
+// LoadNDJSON recreates the indices of a scroll export and bulk-loads their documents
+func (r *Restorer) LoadNDJSON(ctx context.Context, reader io.Reader) error {
+	input, closeInput, err := backup.MaybeGunzip(reader)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	loader := &bulkLoader{restorer: r, ctx: ctx, skipped: map[string]bool{}}
+	lines := bufio.NewReaderSize(input, 1024*1024)
+
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := lines.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var rec record
+			if jsonErr := json.Unmarshal(line, &rec); jsonErr != nil || rec.Index == "" {
+				return fmt.Errorf("line %d is not an export record", lineNumber)
+			}
+			if loadErr := loader.add(&rec); loadErr != nil {
+				return loadErr
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read backup: %w", err)
+		}
+	}
+
+	if err := loader.flush(); err != nil {
+		return err
+	}
+
+	logger.Infof("📄 Loaded %d document(s) into %d index(es)", loader.documents, loader.indices)
+	if len(loader.skipped) > 0 {
+		logger.Infof("⏭️  Skipped %d index(es) not matching --index", len(loader.skipped))
+	}
+	return nil
+}
+
+// bulkLoader batches documents into _bulk requests
+type bulkLoader struct {
+	restorer *Restorer
+	ctx      context.Context
+
+	batch   bytes.Buffer
+	pending int
+	skipped map[string]bool
+
+	indices   int
+	documents int64
+}
+
+func (l *bulkLoader) add(rec *record) error {
+	r := l.restorer
+
+	if len(r.Indices) > 0 && !matchesAny(rec.Index, r.Indices) {
+		l.skipped[rec.Index] = true
+		return nil
+	}
+	target := r.IndexPrefix + rec.Index
+
+	// Index definition line
+	if rec.Source == nil {
+		if err := l.flush(); err != nil {
+			return err
+		}
+		if err := r.createIndex(l.ctx, target, rec); err != nil {
+			return err
+		}
+		l.indices++
+		return nil
+	}
+
+	action := map[string]map[string]string{"index": {"_index": target, "_id": rec.ID}}
+	if rec.Routing != "" {
+		action["index"]["routing"] = rec.Routing
+	}
+	actionLine, err := json.Marshal(action)
+	if err != nil {
+		return err
+	}
+
+	l.batch.Write(actionLine)
+	l.batch.WriteByte('\n')
+	l.batch.Write(rec.Source)
+	l.batch.WriteByte('\n')
+	l.pending++
+
+	if l.pending >= r.batchSize() {
+		return l.flush()
+	}
+	return nil
+}
+
+func (l *bulkLoader) flush() error {
+	if l.pending == 0 {
+		return nil
+	}
+
+	data, err := l.restorer.do(l.ctx, http.MethodPost, "/_bulk", bytes.NewReader(l.batch.Bytes()), "application/x-ndjson")
+	if err != nil {
+		return fmt.Errorf("bulk request failed: %w", err)
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Index  string          `json:"_index"`
+			ID     string          `json:"_id"`
+			Status int             `json:"status"`
+			Error  json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("failed to parse bulk response: %w", err)
+	}
+	if result.Errors {
+		for _, item := range result.Items {
+			for _, outcome := range item {
+				if outcome.Error != nil {
+					return fmt.Errorf("failed to index document %s in %s: %s", outcome.ID, outcome.Index, outcome.Error)
+				}
+			}
+		}
+		return fmt.Errorf("bulk request reported errors")
+	}
+
+	l.documents += int64(l.pending)
+	l.batch.Reset()
+	l.pending = 0
+	return nil
+}
+
+// createIndex creates an index with the settings, mappings and aliases of the export.
+// Existing indices are never loaded into, so documents are not mixed.
+func (r *Restorer) createIndex(ctx context.Context, name string, rec *record) error {
+	err := r.request(ctx, http.MethodGet, indexPath(name), nil, nil)
+	if err == nil {
+		return fmt.Errorf("index %s already exists (delete it or use --index-prefix)", name)
+	}
+	if !isNotFound(err) {
+		return err
+	}
+
+	body := map[string]interface{}{}
+	if settings := restorableSettings(rec.Settings); settings != nil {
+		body["settings"] = settings
+	}
+	if len(rec.Mappings) > 0 {
+		body["mappings"] = rec.Mappings
+	}
+	// Aliases are only carried over when indices keep their names, to avoid clashes
+	if len(rec.Aliases) > 0 && r.IndexPrefix == "" {
+		body["aliases"] = rec.Aliases
+	}
+
+	logger.Infof("🗂️  Creating index %s", name)
+	if err := r.request(ctx, http.MethodPut, indexPath(name), body, nil); err != nil {
+		return fmt.Errorf("failed to create index %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// restorableSettings drops the settings the cluster manages itself from exported index settings
+func restorableSettings(raw json.RawMessage) map[string]interface{} {
+	var settings map[string]interface{}
+	if len(raw) == 0 || json.Unmarshal(raw, &settings) != nil {
+		return nil
+	}
+
+	if index, ok := settings["index"].(map[string]interface{}); ok {
+		for _, name := range unrestorableSettings {
+			delete(index, name)
+		}
+	}
+
+	return settings
+}
+
+// RestoreSnapshot unpacks a snapshot repository archive below RepoDir, registers it
+// read-only and restores its indices
+func (r *Restorer) RestoreSnapshot(ctx context.Context, reader io.Reader) error {
+	if r.RepoDir == "" {
+		return fmt.Errorf("--repo-dir is required to restore a snapshot backup")
+	}
+
+	input, closeInput, err := backup.MaybeGunzip(reader)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	repository := fmt.Sprintf("dbbackup-restore-%d", time.Now().UnixNano())
+	localDir := filepath.Join(localRepoDir(r.RepoDir, r.LocalRepoDir), repository)
+	defer os.RemoveAll(localDir)
+
+	if err := backup.ExtractTar(input, localDir); err != nil {
+		return err
+	}
+
+	if err := r.registerRepository(ctx, repository, path.Join(r.RepoDir, repository), true); err != nil {
+		return err
+	}
+	defer r.unregisterRepository(context.Background(), repository)
+
+	body := map[string]interface{}{
+		"include_global_state": false,
+	}
+	if len(r.Indices) > 0 {
+		body["indices"] = strings.Join(r.Indices, ",")
+	}
+	if r.IndexPrefix != "" {
+		body["rename_pattern"] = "(.+)"
+		body["rename_replacement"] = r.IndexPrefix + "$1"
+		body["include_aliases"] = false
+	}
+
+	var result struct {
+		Snapshot struct {
+			Indices []string `json:"indices"`
+			Shards  struct {
+				Failed int `json:"failed"`
+			} `json:"shards"`
+		} `json:"snapshot"`
+	}
+
+	logger.Infof("📸 Restoring snapshot %s", SnapshotName)
+	restorePath := "/_snapshot/" + url.PathEscape(repository) + "/" + SnapshotName + "/_restore?wait_for_completion=true"
+	if err := r.request(ctx, http.MethodPost, restorePath, body, &result); err != nil {
+		return fmt.Errorf("snapshot restore failed: %w", err)
+	}
+	if result.Snapshot.Shards.Failed > 0 {
+		return fmt.Errorf("snapshot restore failed for %d shard(s)", result.Snapshot.Shards.Failed)
+	}
+
+	logger.Infof("📄 Restored %d index(es)", len(result.Snapshot.Indices))
+	return nil
+}
+
+func (r *Restorer) batchSize() int {
+	if r.BatchSize <= 0 {
+		return DefaultBatchSize
+	}
+	return r.BatchSize
+}
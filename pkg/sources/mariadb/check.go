@@ -0,0 +1,163 @@
+package mariadb
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+)
+
+// Probe checks that the backup tool is installed, that the credentials work, that the user
+// holds the privileges the backup method needs and how large the data is. Queries run with
+// the mariadb client.
+func (d *Dumper) Probe(ctx context.Context) []backup.Check {
+	var checks []backup.Check
+	if err := d.validate(); err != nil {
+		return append(checks, backup.Check{Name: "Options", Err: err})
+	}
+
+	var program string
+	switch d.method() {
+	case MethodDump:
+		program = "mariadb-dump"
+	case MethodMariabackup:
+		program = "mariabackup"
+	default:
+		return append(checks, backup.Check{Name: "Options", Err: fmt.Errorf("unsupported MariaDB backup method %q (expected %s or %s)", d.Method, MethodDump, MethodMariabackup)})
+	}
+
+	checks = append(checks, backup.ToolCheck(ctx, program, ""))
+	client := backup.ToolCheck(ctx, "mariadb", "")
+	checks = append(checks, client)
+	if client.Err != nil {
+		return checks
+	}
+
+	output, err := d.query(ctx, "SELECT CURRENT_USER(), VERSION()")
+	if err != nil {
+		return append(checks, backup.Check{Name: "Authentication", Err: err})
+	}
+	user, version, _ := strings.Cut(output, "\t")
+	checks = append(checks, backup.Check{Name: "Authentication", Detail: fmt.Sprintf("connected as %s to %s", user, version)})
+
+	checks = append(checks, d.checkPrivileges(ctx))
+
+	return append(checks, backup.SizeCheck(d.EstimateSize(ctx)))
+}
+
+// EstimateSize returns the data and index size of the dumped databases
+func (d *Dumper) EstimateSize(ctx context.Context) (int64, error) {
+	query := "SELECT COALESCE(SUM(data_length + index_length), 0) FROM information_schema.TABLES WHERE table_schema = '" + strings.ReplaceAll(d.Database, "'", "''") + "'"
+	if d.AllDatabases {
+		query = "SELECT COALESCE(SUM(data_length + index_length), 0) FROM information_schema.TABLES WHERE table_schema NOT IN ('information_schema', 'performance_schema', 'sys')"
+	}
+	output, err := d.query(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(output, 10, 64)
+}
+
+// requiredPrivileges lists the privileges of the backup method. Alternatives are separated
+// by "|": MariaDB 10.5 renamed REPLICATION CLIENT to BINLOG MONITOR.
+func (d *Dumper) requiredPrivileges() []string {
+	if d.method() == MethodMariabackup {
+		return []string{"RELOAD", "PROCESS", "LOCK TABLES", "BINLOG MONITOR|REPLICATION CLIENT"}
+	}
+	return []string{"SELECT", "SHOW VIEW", "TRIGGER", "EVENT"}
+}
+
+// checkPrivileges compares SHOW GRANTS with the required privileges. Privileges granted
+// through roles are not expanded, so missing ones are only a warning when roles are granted.
+func (d *Dumper) checkPrivileges(ctx context.Context) backup.Check {
+	check := backup.Check{Name: "Privileges"}
+
+	output, err := d.query(ctx, "SHOW GRANTS")
+	if err != nil {
+		check.Err = err
+		return check
+	}
+
+	granted := map[string]bool{}
+	hasRoles := false
+	for _, grant := range strings.Split(output, "\n") {
+		privileges, target, ok := parseGrant(grant)
+		if !ok {
+			hasRoles = hasRoles || strings.HasPrefix(grant, "GRANT ")
+			continue
+		}
+		// mariabackup privileges are global; database-level grants only count for dumps
+		if target != "*.*" && (d.AllDatabases || d.method() == MethodMariabackup || target != "`"+d.Database+"`.*") {
+			continue
+		}
+		for _, privilege := range privileges {
+			granted[privilege] = true
+		}
+	}
+
+	var missing []string
+	for _, required := range d.requiredPrivileges() {
+		found := granted["ALL PRIVILEGES"]
+		for _, alternative := range strings.Split(required, "|") {
+			found = found || granted[alternative]
+		}
+		if !found {
+			missing = append(missing, strings.ReplaceAll(required, "|", " or "))
+		}
+	}
+
+	if len(missing) == 0 {
+		check.Detail = strings.ReplaceAll(strings.Join(d.requiredPrivileges(), ", "), "|", " or ")
+		return check
+	}
+
+	check.Err = fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	if hasRoles {
+		check.Err = fmt.Errorf("%w (roles are granted and may provide them)", check.Err)
+		check.Warning = true
+	}
+	return check
+}
+
+// parseGrant splits "GRANT SELECT, SHOW VIEW ON `shop`.* TO `backup`@`%`" into its privileges
+// and target. Role grants, which have no ON clause, are not parsed.
+func parseGrant(grant string) ([]string, string, bool) {
+	rest, found := strings.CutPrefix(grant, "GRANT ")
+	if !found {
+		return nil, "", false
+	}
+	list, rest, found := strings.Cut(rest, " ON ")
+	if !found {
+		return nil, "", false
+	}
+	target, _, _ := strings.Cut(rest, " TO ")
+	target = strings.ReplaceAll(target, `\_`, "_")
+
+	var privileges []string
+	for _, privilege := range strings.Split(list, ",") {
+		// Column-level grants such as SELECT (id) do not cover whole tables
+		if privilege = strings.TrimSpace(privilege); !strings.Contains(privilege, "(") {
+			privileges = append(privileges, strings.ToUpper(privilege))
+		}
+	}
+	return privileges, strings.TrimSpace(target), true
+}
+
+// query runs a statement with the mariadb client and returns its tab-separated rows
+func (d *Dumper) query(ctx context.Context, statement string) (string, error) {
+	args, cleanup, err := clientArgs(d.Host, d.Port, d.Username, d.Password, d.TLS)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	args = append(args, "--batch", "--skip-column-names", "-e", statement)
+	output, err := exec.CommandContext(ctx, "mariadb", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("mariadb failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
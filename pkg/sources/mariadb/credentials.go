@@ -0,0 +1,81 @@
+package mariadb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+)
+
+// clientArgs returns connection arguments for the MariaDB client tools (mariadb, mariadb-dump
+// and mariabackup). The password is written to a temporary option file passed via
+// --defaults-extra-file; the returned cleanup function removes it and must always be called.
+func clientArgs(host string, port int, username, password string, tls backup.TLSConfig) ([]string, func(), error) {
+	sslArgs, err := tlsArgs(tls)
+	if err != nil {
+		return nil, func() {}, err
+	}
+
+	var args []string
+	cleanup := func() {}
+
+	// --defaults-extra-file must be the first argument
+	if password != "" {
+		content := fmt.Sprintf("[client]\npassword=\"%s\"\n", escapeOptionValue(password))
+		path, remove, err := backup.WriteSecretFile("dbbackup-mariadb-*.cnf", content)
+		if err != nil {
+			return nil, cleanup, err
+		}
+		cleanup = remove
+		args = append(args, "--defaults-extra-file="+path)
+	}
+
+	args = append(args,
+		fmt.Sprintf("--host=%s", host),
+		fmt.Sprintf("--port=%d", port),
+	)
+
+	if username != "" {
+		args = append(args, fmt.Sprintf("--user=%s", username))
+	}
+
+	args = append(args, sslArgs...)
+
+	return args, cleanup, nil
+}
+
+// tlsArgs maps TLS options to MariaDB client options. Unlike MySQL there is no --ssl-mode;
+// MariaDB does not distinguish CA-only from full verification.
+func tlsArgs(tls backup.TLSConfig) ([]string, error) {
+	mode, err := tls.NormalizedMode()
+	if err != nil {
+		return nil, err
+	}
+
+	var args []string
+	switch mode {
+	case backup.SSLModeDisable:
+		args = append(args, "--skip-ssl")
+	case backup.SSLModeRequire:
+		args = append(args, "--ssl", "--skip-ssl-verify-server-cert")
+	case backup.SSLModeVerifyCA, backup.SSLModeVerifyFull:
+		args = append(args, "--ssl", "--ssl-verify-server-cert")
+	}
+
+	if tls.CAFile != "" {
+		args = append(args, "--ssl-ca="+tls.CAFile)
+	}
+	if tls.CertFile != "" {
+		args = append(args, "--ssl-cert="+tls.CertFile)
+	}
+	if tls.KeyFile != "" {
+		args = append(args, "--ssl-key="+tls.KeyFile)
+	}
+
+	return args, nil
+}
+
+// escapeOptionValue escapes a value for a double-quoted option file string
+func escapeOptionValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
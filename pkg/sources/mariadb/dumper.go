@@ -0,0 +1,224 @@
+package mariadb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// Backup methods
+const (
+	// MethodDump writes a logical SQL dump with mariadb-dump
+	MethodDump = "dump"
+	// MethodMariabackup streams a physical hot backup with mariabackup (run on the database host)
+	MethodMariabackup = "mariabackup"
+)
+
+type Dumper struct {
+	Host     string
+	Port     int
+	Database string
+	Username string
+	Password string
+	TLS      backup.TLSConfig
+
+	Method       string
+	AllDatabases bool
+}
+
+func (d *Dumper) CreateBackupStream(ctx context.Context) (io.ReadCloser, error) {
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+
+	cmd, cleanup, err := d.dumpCommand(ctx)
+	if err != nil {
+		return nil, err
+	}
+	program := cmd.Args[0]
+
+	// mariabackup logs every copied file, so stderr is drained while the backup runs
+	stderr := &tailBuffer{limit: 64 * 1024}
+	cmd.Stderr = stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	// Start the command
+	if err := backup.StartCommand(cmd); err != nil {
+		stdout.Close()
+		cleanup()
+		return nil, fmt.Errorf("failed to start %s: %w", program, err)
+	}
+
+	// Return a wrapper that will wait for the command to finish when closed
+	return &cmdReader{
+		reader:  stdout,
+		stderr:  stderr,
+		cmd:     cmd,
+		program: program,
+		cleanup: cleanup,
+	}, nil
+}
+
+// dumpCommand builds the mariadb-dump or mariabackup command. The cleanup function removes
+// the option file holding the password and temporary files, and must always be called once
+// the command has finished.
+func (d *Dumper) dumpCommand(ctx context.Context) (*exec.Cmd, func(), error) {
+	args, cleanup, err := clientArgs(d.Host, d.Port, d.Username, d.Password, d.TLS)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var program string
+	switch d.method() {
+	case MethodDump:
+		program = "mariadb-dump"
+		args = append(args, "--single-transaction", "--routines", "--triggers", "--events")
+		if d.AllDatabases {
+			args = append(args, "--all-databases")
+		} else {
+			args = append(args, d.Database)
+		}
+	case MethodMariabackup:
+		program = "mariabackup"
+		targetDir, err := os.MkdirTemp("", "dbbackup-mariabackup-*")
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		removeCredentials := cleanup
+		cleanup = func() {
+			removeCredentials()
+			os.RemoveAll(targetDir)
+		}
+		// With --stream the backup is written to stdout; the target dir only holds temporary files
+		args = append(args, "--backup", "--stream=xbstream", "--target-dir="+targetDir)
+		if !d.AllDatabases {
+			args = append(args, "--databases="+d.Database)
+		}
+	default:
+		cleanup()
+		return nil, nil, fmt.Errorf("unsupported MariaDB backup method %q (expected %s or %s)", d.Method, MethodDump, MethodMariabackup)
+	}
+
+	return exec.CommandContext(ctx, program, args...), cleanup, nil
+}
+
+// DescribeCommand returns the backup command line. The password stays in the temporary
+// option file.
+func (d *Dumper) DescribeCommand() ([]string, error) {
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+
+	cmd, cleanup, err := d.dumpCommand(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	cleanup()
+	return []string{backup.FormatCommand(cmd)}, nil
+}
+
+// validate checks the dump scope
+func (d *Dumper) validate() error {
+	if d.AllDatabases && d.Database != "" {
+		return fmt.Errorf("--all-databases cannot be combined with a database name")
+	}
+	if !d.AllDatabases && d.Database == "" {
+		return fmt.Errorf("a database name or --all-databases is required")
+	}
+	return nil
+}
+
+func (d *Dumper) method() string {
+	if d.Method == "" {
+		return MethodDump
+	}
+	return d.Method
+}
+
+type cmdReader struct {
+	reader  io.ReadCloser
+	stderr  *tailBuffer
+	cmd     *exec.Cmd
+	program string
+	cleanup func()
+}
+
+func (cr *cmdReader) Read(p []byte) (int, error) {
+	return cr.reader.Read(p)
+}
+
+func (cr *cmdReader) Close() error {
+	cr.reader.Close()
+	defer cr.cleanup()
+
+	err := cr.cmd.Wait()
+	output := cr.stderr.String()
+
+	if output != "" {
+		logger.Debugf("%s stderr: %s", cr.program, output)
+	}
+
+	if err != nil {
+		if output != "" {
+			return fmt.Errorf("%s failed: %w\nOutput: %s", cr.program, err, output)
+		}
+		return fmt.Errorf("%s failed: %w", cr.program, err)
+	}
+
+	return nil
+}
+
+// tailBuffer keeps the last limit bytes written to it
+type tailBuffer struct {
+	limit int
+	data  []byte
+}
+
+func (tb *tailBuffer) Write(p []byte) (int, error) {
+	tb.data = append(tb.data, p...)
+	if len(tb.data) > tb.limit {
+		tb.data = append(tb.data[:0], tb.data[len(tb.data)-tb.limit:]...)
+	}
+	return len(p), nil
+}
+
+func (tb *tailBuffer) String() string {
+	return string(tb.data)
+}
+
+// GetFileExtension returns the file extension for MariaDB backups
+func (d *Dumper) GetFileExtension() string {
+	if d.method() == MethodMariabackup {
+		return ".xbstream"
+	}
+	return ".sql"
+}
+
+// GetDatabaseType returns the database type
+func (d *Dumper) GetDatabaseType() string {
+	return "mariadb"
+}
+
+// GetDatabaseName returns the database name
+func (d *Dumper) GetDatabaseName() string {
+	if d.AllDatabases {
+		return "all"
+	}
+	return d.Database
+}
+
+// GetDatabaseHost returns the database host for object key templates
+func (d *Dumper) GetDatabaseHost() string {
+	return d.Host
+}
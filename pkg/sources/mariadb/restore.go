@@ -0,0 +1,95 @@
+package mariadb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+)
+
+// Restorer loads MariaDB backups
+type Restorer struct {
+	Host     string
+	Port     int
+	Database string
+	Username string
+	Password string
+	TLS      backup.TLSConfig
+
+	// DataDir is the empty data directory a physical backup is copied back into
+	DataDir string
+}
+
+// LoadDump pipes a (optionally gzip-compressed) SQL dump into the mariadb client
+func (r *Restorer) LoadDump(ctx context.Context, reader io.Reader) error {
+	input, closeInput, err := backup.MaybeGunzip(reader)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	args, cleanup, err := clientArgs(r.Host, r.Port, r.Username, r.Password, r.TLS)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if r.Database != "" {
+		args = append(args, r.Database)
+	}
+
+	cmd := exec.CommandContext(ctx, "mariadb", args...)
+	cmd.Stdin = input
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("mariadb failed: %w\nOutput: %s", err, string(output))
+	}
+
+	return nil
+}
+
+// RestorePhysical unpacks a mariabackup xbstream, prepares it and copies it back into DataDir.
+// The server must be stopped; the data directory must be empty or not exist yet.
+func (r *Restorer) RestorePhysical(ctx context.Context, reader io.Reader) error {
+	if r.DataDir == "" {
+		return fmt.Errorf("--data-dir is required to restore a mariabackup backup")
+	}
+	if entries, err := os.ReadDir(r.DataDir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("data directory %s is not empty", r.DataDir)
+	}
+
+	input, closeInput, err := backup.MaybeGunzip(reader)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	workDir, err := os.MkdirTemp("", "dbbackup-mariabackup-restore-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	extract := exec.CommandContext(ctx, "mbstream", "-x", "-C", workDir)
+	extract.Stdin = input
+	if output, err := extract.CombinedOutput(); err != nil {
+		return fmt.Errorf("mbstream failed: %w\nOutput: %s", err, string(output))
+	}
+
+	steps := [][]string{
+		{"--prepare", "--target-dir=" + workDir},
+		{"--copy-back", "--target-dir=" + workDir, "--datadir=" + r.DataDir},
+	}
+	for _, args := range steps {
+		cmd := exec.CommandContext(ctx, "mariabackup", args...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("mariabackup %s failed: %w\nOutput: %s", args[0], err, string(output))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package mongodb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+)
+
+// probeCollection is a collection name that is not expected to exist. Dumping it makes
+// mongodump authenticate and query the database without transferring any documents.
+const probeCollection = "dbbackup_check_probe"
+
+// Probe checks that mongodump can dump the database. Authentication and read access are
+// verified by dumping a missing collection with the same credentials the backup uses.
+func (d *Dumper) Probe(ctx context.Context) []backup.Check {
+	var checks []backup.Check
+	if _, err := d.buildArgs(); err != nil {
+		return append(checks, backup.Check{Name: "Options", Err: err})
+	}
+
+	mongodump := backup.ToolCheck(ctx, backup.ToolPath(d.BinDir, "mongodump"), "mongo-bin-dir")
+	checks = append(checks, mongodump)
+
+	if serverVersion, err := d.queryServerVersion(ctx); err != nil {
+		checks = append(checks, backup.Check{Name: "Version compatibility", Err: err, Warning: true})
+	} else if mongodump.Err == nil {
+		checks = append(checks, backup.Check{
+			Name:   "Version compatibility",
+			Detail: fmt.Sprintf("%s, server %s", mongodump.Detail, serverVersion),
+			Err:    checkCompatibility(mongodump.Detail, serverVersion),
+		})
+	}
+	if mongodump.Err != nil {
+		return checks
+	}
+
+	database := d.Database
+	if database == "" {
+		database = "admin"
+	}
+	access := backup.Check{Name: "Authentication and read access", Detail: "database " + database}
+	access.Err = d.probeDump(ctx, database)
+	checks = append(checks, access)
+
+	// Sizes need an authenticated dbStats command, which mongodump cannot run
+	return append(checks, backup.SizeCheck(0, fmt.Errorf("not available for MongoDB")))
+}
+
+// probeDump dumps probeCollection of a database, discarding the archive
+func (d *Dumper) probeDump(ctx context.Context, database string) error {
+	args := []string{"--archive", "--db", database, "--collection", probeCollection}
+	if d.AuthenticationDatabase != "" {
+		args = append(args, "--authenticationDatabase", d.AuthenticationDatabase)
+	}
+
+	cmd, cleanup, err := d.command(ctx, args)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	var stderr bytes.Buffer
+	cmd.Stdout = io.Discard
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("mongodump failed: %w: %s", err, lastLine(stderr.String()))
+	}
+	return nil
+}
+
+// lastLine returns the last non-empty line of mongodump's log output, which holds the error
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
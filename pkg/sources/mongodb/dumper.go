@@ -2,13 +2,18 @@ package mongodb
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
-	"log"
-	"os"
+	"net"
+	"net/url"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
 )
 
 type Dumper struct {
@@ -17,43 +22,63 @@ type Dumper struct {
 	Database string
 	Username string
 	Password string
+	TLS      backup.TLSConfig
+
+	// URI is a full connection string (replica sets, SRV, authSource...). When set it is
+	// used as-is instead of building one from Host, Port, Username and Password.
+	URI string
+
+	// Dump scope and consistency options
+	Collection             string
+	ExcludeCollections     []string
+	Query                  string
+	Oplog                  bool
+	ReadPreference         string
+	AuthenticationDatabase string
+	NumParallelCollections int
+
+	// BinDir is the directory holding mongodump, to pick a specific release
+	// (default: look it up in PATH)
+	BinDir string
+
+	clientVersion string
+	serverVersion string
 }
 
 func (d *Dumper) CreateBackupStream(ctx context.Context) (io.ReadCloser, error) {
-	// Build MongoDB connection URI
-	uri := fmt.Sprintf("mongodb://%s:%d", d.Host, d.Port)
-	if d.Username != "" && d.Password != "" {
-		uri = fmt.Sprintf("mongodb://%s:%s@%s:%d", d.Username, d.Password, d.Host, d.Port)
+	dumpArgs, err := d.buildArgs()
+	if err != nil {
+		return nil, err
 	}
 
-	args := []string{
-		"--uri", uri,
-		"--archive",
-		"--gzip",
+	if err := d.checkVersions(ctx); err != nil {
+		return nil, err
 	}
 
-	if d.Database != "" {
-		args = append(args, "--db", d.Database)
+	cmd, cleanup, err := d.command(ctx, append([]string{"--archive", "--gzip"}, dumpArgs...))
+	if err != nil {
+		return nil, err
 	}
 
-	cmd := exec.CommandContext(ctx, "mongodump", args...)
-
 	// Create pipes for both stdout and stderr
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
+		cleanup()
 		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
 
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
 		stdout.Close()
+		cleanup()
 		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
 
 	// Start the command
-	if err := cmd.Start(); err != nil {
+	if err := backup.StartCommand(cmd); err != nil {
 		stdout.Close()
 		stderr.Close()
+		cleanup()
 		return nil, fmt.Errorf("failed to start mongodump: %w", err)
 	}
 
@@ -62,15 +87,137 @@ func (d *Dumper) CreateBackupStream(ctx context.Context) (io.ReadCloser, error)
 		reader:    stdout,
 		stderr:    stderr,
 		cmd:       cmd,
+		cleanup:   cleanup,
 		validated: false,
 		firstRead: false,
 	}, nil
 }
 
+// command builds a mongodump command. The URI may carry credentials, so it is passed in a
+// config file instead of on the command line; cleanup removes it and the TLS files.
+func (d *Dumper) command(ctx context.Context, args []string) (*exec.Cmd, func(), error) {
+	sslArgs, tlsCleanup, err := tlsArgs(d.TLS)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	configPath, configCleanup, err := backup.WriteSecretFile("dbbackup-mongodump-*.yaml", fmt.Sprintf("uri: %s\n", strconv.Quote(d.connectionURI())))
+	if err != nil {
+		tlsCleanup()
+		return nil, nil, err
+	}
+
+	cleanup := func() {
+		configCleanup()
+		tlsCleanup()
+	}
+
+	args = append(append([]string{"--config", configPath}, sslArgs...), args...)
+	return exec.CommandContext(ctx, backup.ToolPath(d.BinDir, "mongodump"), args...), cleanup, nil
+}
+
+// DescribeCommand returns the mongodump command line and the redacted connection URI that
+// its temporary config file holds
+func (d *Dumper) DescribeCommand() ([]string, error) {
+	dumpArgs, err := d.buildArgs()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd, cleanup, err := d.command(context.Background(), append([]string{"--archive", "--gzip"}, dumpArgs...))
+	if err != nil {
+		return nil, err
+	}
+	cleanup()
+
+	return []string{
+		backup.FormatCommand(cmd),
+		"config file uri: " + logger.Redact(d.connectionURI()),
+	}, nil
+}
+
+func (d *Dumper) buildArgs() ([]string, error) {
+	if d.Collection != "" && d.Database == "" {
+		return nil, fmt.Errorf("--collection requires a database name")
+	}
+	if len(d.ExcludeCollections) > 0 && d.Database == "" {
+		return nil, fmt.Errorf("--exclude-collection requires a database name")
+	}
+	if d.Collection != "" && len(d.ExcludeCollections) > 0 {
+		return nil, fmt.Errorf("--collection cannot be combined with --exclude-collection")
+	}
+	if d.Query != "" {
+		if d.Collection == "" {
+			return nil, fmt.Errorf("--query requires --collection")
+		}
+		if !json.Valid([]byte(d.Query)) {
+			return nil, fmt.Errorf("--query must be a JSON document (Extended JSON v2)")
+		}
+	}
+	// The oplog is only consistent for a full-instance dump of a replica set member
+	if d.Oplog && d.Database != "" {
+		return nil, fmt.Errorf("--oplog can only be used when dumping all databases")
+	}
+
+	var args []string
+
+	if d.Database != "" {
+		args = append(args, "--db", d.Database)
+	}
+
+	if d.Collection != "" {
+		args = append(args, "--collection", d.Collection)
+	}
+
+	for _, collection := range d.ExcludeCollections {
+		args = append(args, "--excludeCollection", collection)
+	}
+
+	if d.Query != "" {
+		args = append(args, "--query", d.Query)
+	}
+
+	if d.Oplog {
+		args = append(args, "--oplog")
+	}
+
+	if d.ReadPreference != "" {
+		args = append(args, "--readPreference", d.ReadPreference)
+	}
+
+	if d.AuthenticationDatabase != "" {
+		args = append(args, "--authenticationDatabase", d.AuthenticationDatabase)
+	}
+
+	if d.NumParallelCollections > 0 {
+		args = append(args, "--numParallelCollections", strconv.Itoa(d.NumParallelCollections))
+	}
+
+	return args, nil
+}
+
+// connectionURI returns the MongoDB connection URI with credentials escaped
+func (d *Dumper) connectionURI() string {
+	if d.URI != "" {
+		return d.URI
+	}
+
+	uri := url.URL{
+		Scheme: "mongodb",
+		Host:   net.JoinHostPort(d.Host, strconv.Itoa(d.Port)),
+	}
+	if d.Username != "" && d.Password != "" {
+		uri.User = url.UserPassword(d.Username, d.Password)
+	}
+
+	return uri.String()
+}
+
 type cmdReader struct {
 	reader    io.ReadCloser
 	stderr    io.ReadCloser
 	cmd       *exec.Cmd
+	cleanup   func()
 	validated bool
 	firstRead bool
 }
@@ -117,6 +264,7 @@ func (cr *cmdReader) Read(p []byte) (int, error) {
 
 func (cr *cmdReader) Close() error {
 	cr.reader.Close()
+	defer cr.cleanup()
 
 	// Read remaining stderr to capture any error messages
 	remainingStderr, _ := io.ReadAll(cr.stderr)
@@ -124,23 +272,16 @@ func (cr *cmdReader) Close() error {
 
 	if err := cr.cmd.Wait(); err != nil {
 		if len(remainingStderr) > 0 {
-			// Check if we should log debug output
 			stderrStr := string(remainingStderr)
-			if strings.Contains(os.Getenv("LOG_LEVEL"), "debug") ||
-				strings.Contains(os.Getenv("LOG_LEVEL"), "DEBUG") {
-				log.Printf("DEBUG: mongodump stderr: %s", stderrStr)
-			}
+			logger.Debugf("mongodump stderr: %s", stderrStr)
 			return fmt.Errorf("mongodump failed: %w\nOutput: %s", err, stderrStr)
 		}
 		return fmt.Errorf("mongodump failed: %w", err)
 	}
 
-	// Also log any warnings/messages even on success if debug is enabled
+	// Also log any warnings/messages even on success at debug level
 	if len(remainingStderr) > 0 {
-		if strings.Contains(os.Getenv("LOG_LEVEL"), "debug") ||
-			strings.Contains(os.Getenv("LOG_LEVEL"), "DEBUG") {
-			log.Printf("DEBUG: mongodump stderr: %s", string(remainingStderr))
-		}
+		logger.Debugf("mongodump stderr: %s", string(remainingStderr))
 	}
 
 	return nil
@@ -163,3 +304,24 @@ func (d *Dumper) GetDatabaseName() string {
 	}
 	return d.Database
 }
+
+// GetDatabaseHost returns the database host for object key templates, taken from the URI
+// when one is set
+func (d *Dumper) GetDatabaseHost() string {
+	if d.URI != "" {
+		if u, err := url.Parse(d.URI); err == nil {
+			// Replica set URIs list several hosts; use the first
+			host, _, _ := strings.Cut(u.Host, ",")
+			if name, _, err := net.SplitHostPort(host); err == nil {
+				return name
+			}
+			return host
+		}
+	}
+	return d.Host
+}
+
+// GetBackupMetadata returns the mongodump and server versions
+func (d *Dumper) GetBackupMetadata() map[string]string {
+	return backup.VersionMetadata(d.clientVersion, d.serverVersion)
+}
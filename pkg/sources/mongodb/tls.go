@@ -0,0 +1,71 @@
+package mongodb
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+)
+
+// tlsArgs maps TLS options to mongodump/mongorestore --tls* options. The MongoDB tools expect
+// the client certificate and key in a single PEM file, so separate files are combined into a
+// temporary file; the returned cleanup function removes it and must always be called.
+func tlsArgs(tls backup.TLSConfig) ([]string, func(), error) {
+	cleanup := func() {}
+
+	mode, err := tls.NormalizedMode()
+	if err != nil {
+		return nil, cleanup, err
+	}
+
+	// The MongoDB tools cannot negotiate TLS opportunistically, so allow/prefer leave it off
+	if mode == "" || mode == backup.SSLModeDisable || mode == backup.SSLModeAllow || mode == backup.SSLModePrefer {
+		return nil, cleanup, nil
+	}
+
+	args := []string{"--tls"}
+
+	switch mode {
+	case backup.SSLModeRequire:
+		args = append(args, "--tlsInsecure")
+	case backup.SSLModeVerifyCA:
+		args = append(args, "--tlsAllowInvalidHostnames")
+	}
+
+	if tls.CAFile != "" {
+		args = append(args, "--tlsCAFile="+tls.CAFile)
+	}
+
+	if tls.CertFile != "" {
+		certificateKeyFile := tls.CertFile
+		if tls.KeyFile != "" && tls.KeyFile != tls.CertFile {
+			combined, err := combinePEM(tls.CertFile, tls.KeyFile)
+			if err != nil {
+				return nil, cleanup, err
+			}
+
+			path, remove, err := backup.WriteSecretFile("dbbackup-mongodb-*.pem", combined)
+			if err != nil {
+				return nil, cleanup, err
+			}
+			certificateKeyFile, cleanup = path, remove
+		}
+		args = append(args, "--tlsCertificateKeyFile="+certificateKeyFile)
+	}
+
+	return args, cleanup, nil
+}
+
+func combinePEM(certFile, keyFile string) (string, error) {
+	cert, err := os.ReadFile(certFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read client certificate: %w", err)
+	}
+
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read client key: %w", err)
+	}
+
+	return string(cert) + "\n" + string(key), nil
+}
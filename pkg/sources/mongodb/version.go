@@ -0,0 +1,288 @@
+package mongodb
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// opMsg is the wire protocol opcode of OP_MSG, supported since MongoDB 3.6
+const opMsg = 2013
+
+// checkVersions detects the mongodump and server versions and fails early when the tools
+// do not support the server. Without a server version the check is skipped.
+func (d *Dumper) checkVersions(ctx context.Context) error {
+	clientVersion, err := backup.ToolVersion(ctx, backup.ToolPath(d.BinDir, "mongodump"), "mongo-bin-dir")
+	if err != nil {
+		return err
+	}
+	d.clientVersion = clientVersion
+
+	serverVersion, err := d.queryServerVersion(ctx)
+	if err != nil {
+		logger.Warnf("⚠️  Could not detect the MongoDB server version, skipping compatibility check: %v", err)
+		return nil
+	}
+	d.serverVersion = serverVersion
+	logger.Infof("🔍 %s, server %s", clientVersion, serverVersion)
+
+	return checkCompatibility(clientVersion, serverVersion)
+}
+
+// checkCompatibility rejects mongodump releases that do not support the server. Versions
+// that cannot be parsed are accepted.
+func checkCompatibility(clientVersion, serverVersion string) error {
+	client, ok := backup.ParseVersion(clientVersion)
+	if !ok {
+		return nil
+	}
+	server, ok := backup.ParseVersion(serverVersion)
+	if !ok {
+		return nil
+	}
+
+	// The MongoDB Database Tools are versioned 100.x and later; older mongodump
+	// releases shipped with, and only know, the server of the same version
+	legacy := client.Major < 100
+	switch {
+	case legacy && (client.Major < server.Major || client.Major == server.Major && client.Minor < server.Minor):
+		return fmt.Errorf("mongodump %d.%d is older than the MongoDB %s server: install the MongoDB Database Tools and pass their directory with --mongo-bin-dir", client.Major, client.Minor, serverVersion)
+	case !legacy && !server.AtLeast(4, 0):
+		return fmt.Errorf("MongoDB Database Tools %s do not support MongoDB %s: pass the directory of the mongodump shipped with that server release with --mongo-bin-dir", client, serverVersion)
+	}
+
+	return nil
+}
+
+// queryServerVersion runs buildInfo, which needs no authentication, on the first host of the
+// connection. SRV connection strings are not resolved.
+func (d *Dumper) queryServerVersion(ctx context.Context) (string, error) {
+	host, port, useTLS, err := d.firstHost()
+	if err != nil {
+		return "", err
+	}
+
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	tlsConfig, err := d.TLS.ClientConfig(host)
+	if err != nil {
+		return "", err
+	}
+	if tlsConfig == nil && useTLS {
+		tlsConfig = &tls.Config{ServerName: host}
+	}
+	if tlsConfig != nil {
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return "", fmt.Errorf("TLS handshake with %s failed: %w", address, err)
+		}
+		conn = tlsConn
+	}
+
+	command := bsonDocument(
+		bsonInt32("buildInfo", 1),
+		bsonString("$db", "admin"),
+	)
+	if err := writeMessage(conn, command); err != nil {
+		return "", err
+	}
+
+	reply, err := readMessage(conn)
+	if err != nil {
+		return "", fmt.Errorf("buildInfo failed: %w", err)
+	}
+
+	version, ok := bsonLookupString(reply, "version")
+	if !ok {
+		return "", fmt.Errorf("buildInfo reply has no version")
+	}
+	return version, nil
+}
+
+// firstHost returns the first host of the connection and whether the URI asks for TLS
+func (d *Dumper) firstHost() (string, int, bool, error) {
+	if d.URI == "" {
+		return d.Host, d.Port, false, nil
+	}
+
+	uri, err := url.Parse(d.URI)
+	if err != nil {
+		return "", 0, false, fmt.Errorf("invalid connection URI")
+	}
+	if uri.Scheme != "mongodb" {
+		return "", 0, false, fmt.Errorf("%s connection strings are not supported", uri.Scheme)
+	}
+
+	query := uri.Query()
+	useTLS := query.Get("tls") == "true" || query.Get("ssl") == "true"
+
+	first, _, _ := strings.Cut(uri.Host, ",")
+	host, portText, err := net.SplitHostPort(first)
+	if err != nil {
+		return first, 27017, useTLS, nil
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil {
+		return "", 0, false, fmt.Errorf("invalid port %q", portText)
+	}
+	return host, port, useTLS, nil
+}
+
+// writeMessage sends a command document as an OP_MSG with a single body section
+func writeMessage(w io.Writer, document []byte) error {
+	var message bytes.Buffer
+	header := make([]byte, 16)
+	binary.LittleEndian.PutUint32(header[0:], uint32(16+4+1+len(document)))
+	binary.LittleEndian.PutUint32(header[4:], 1)
+	binary.LittleEndian.PutUint32(header[12:], opMsg)
+	message.Write(header)
+	message.Write([]byte{0, 0, 0, 0}) // flag bits
+	message.WriteByte(0)              // body section
+	message.Write(document)
+
+	_, err := w.Write(message.Bytes())
+	return err
+}
+
+// readMessage reads an OP_MSG reply and returns its body document
+func readMessage(r io.Reader) ([]byte, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	length := binary.LittleEndian.Uint32(header[0:])
+	if binary.LittleEndian.Uint32(header[12:]) != opMsg || length < 21 || length > 16<<20 {
+		return nil, fmt.Errorf("unexpected reply (server older than MongoDB 3.6?)")
+	}
+
+	body := make([]byte, length-16)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	if body[4] != 0 {
+		return nil, fmt.Errorf("unexpected reply section kind %d", body[4])
+	}
+	return body[5:], nil
+}
+
+func bsonDocument(elements ...[]byte) []byte {
+	size := 4 + 1
+	for _, element := range elements {
+		size += len(element)
+	}
+
+	document := binary.LittleEndian.AppendUint32(nil, uint32(size))
+	for _, element := range elements {
+		document = append(document, element...)
+	}
+	return append(document, 0)
+}
+
+func bsonInt32(name string, value int32) []byte {
+	element := append([]byte{0x10}, name...)
+	element = append(element, 0)
+	return binary.LittleEndian.AppendUint32(element, uint32(value))
+}
+
+func bsonString(name, value string) []byte {
+	element := append([]byte{0x02}, name...)
+	element = append(element, 0)
+	element = binary.LittleEndian.AppendUint32(element, uint32(len(value)+1))
+	element = append(element, value...)
+	return append(element, 0)
+}
+
+// bsonLookupString finds a top-level string field in a BSON document
+func bsonLookupString(document []byte, name string) (string, bool) {
+	if len(document) < 5 {
+		return "", false
+	}
+
+	pos := 4
+	for pos < len(document) && document[pos] != 0 {
+		elementType := document[pos]
+		pos++
+		end := bytes.IndexByte(document[pos:], 0)
+		if end < 0 {
+			return "", false
+		}
+		key := string(document[pos : pos+end])
+		pos += end + 1
+
+		size, ok := bsonValueSize(elementType, document[pos:])
+		if !ok || pos+size > len(document) {
+			return "", false
+		}
+		if key == name && elementType == 0x02 {
+			return string(document[pos+4 : pos+size-1]), true
+		}
+		pos += size
+	}
+
+	return "", false
+}
+
+// bsonValueSize returns the encoded size of a value of the given BSON type
+func bsonValueSize(elementType byte, data []byte) (int, bool) {
+	length := func(offset int) (int, bool) {
+		if len(data) < offset+4 {
+			return 0, false
+		}
+		return int(binary.LittleEndian.Uint32(data[offset:])), true
+	}
+
+	switch elementType {
+	case 0x06, 0x0A, 0x7F, 0xFF: // undefined, null, max key, min key
+		return 0, true
+	case 0x08: // boolean
+		return 1, true
+	case 0x10: // int32
+		return 4, true
+	case 0x01, 0x09, 0x11, 0x12: // double, datetime, timestamp, int64
+		return 8, true
+	case 0x07: // object id
+		return 12, true
+	case 0x13: // decimal128
+		return 16, true
+	case 0x02, 0x0D, 0x0E: // string, JavaScript code, symbol
+		n, ok := length(0)
+		return 4 + n, ok
+	case 0x03, 0x04, 0x0F: // document, array, code with scope
+		return length(0)
+	case 0x05: // binary
+		n, ok := length(0)
+		return 4 + 1 + n, ok
+	case 0x0C: // DB pointer
+		n, ok := length(0)
+		return 4 + n + 12, ok
+	case 0x0B: // regular expression: two C strings
+		first := bytes.IndexByte(data, 0)
+		if first < 0 {
+			return 0, false
+		}
+		second := bytes.IndexByte(data[first+1:], 0)
+		if second < 0 {
+			return 0, false
+		}
+		return first + 1 + second + 1, true
+	}
+	return 0, false
+}
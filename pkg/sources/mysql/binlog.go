@@ -0,0 +1,291 @@
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// BinlogDir is the directory under the storage path prefix that holds archived binlog files
+const BinlogDir = "binlog"
+
+// coordinateScanLimit bounds how much of the dump header is inspected for binlog coordinates
+const coordinateScanLimit = 1 << 20
+
+var (
+	changeMasterPattern = regexp.MustCompile(`(?:MASTER|SOURCE)_LOG_FILE='([^']+)',\s*(?:MASTER|SOURCE)_LOG_POS=(\d+)`)
+	gtidPurgedPattern   = regexp.MustCompile(`(?s)GTID_PURGED=(?:/\*!80000 '\+'\*/ )?'([^']*)'`)
+)
+
+// BinlogCoordinates is the binlog position a dump is consistent with
+type BinlogCoordinates struct {
+	File       string
+	Position   int64
+	GTIDPurged string
+}
+
+// Metadata returns the coordinates as backup manifest metadata
+func (c *BinlogCoordinates) Metadata() map[string]string {
+	if c.File == "" {
+		return nil
+	}
+
+	metadata := map[string]string{
+		"binlog_file":     c.File,
+		"binlog_position": strconv.FormatInt(c.Position, 10),
+	}
+	if c.GTIDPurged != "" {
+		metadata["gtid_purged"] = c.GTIDPurged
+	}
+	return metadata
+}
+
+// CoordinatesFromMetadata reads binlog coordinates back from backup manifest metadata
+func CoordinatesFromMetadata(metadata map[string]string) (*BinlogCoordinates, error) {
+	file := metadata["binlog_file"]
+	if file == "" {
+		return nil, fmt.Errorf("backup has no binlog coordinates (was it taken with --master-data?)")
+	}
+
+	position, err := strconv.ParseInt(metadata["binlog_position"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid binlog position %q: %w", metadata["binlog_position"], err)
+	}
+
+	return &BinlogCoordinates{
+		File:       file,
+		Position:   position,
+		GTIDPurged: metadata["gtid_purged"],
+	}, nil
+}
+
+// coordinateRecorder captures the dump header and parses binlog coordinates from it on close
+type coordinateRecorder struct {
+	reader      io.ReadCloser
+	header      bytes.Buffer
+	coordinates *BinlogCoordinates
+}
+
+func newCoordinateRecorder(reader io.ReadCloser, coordinates *BinlogCoordinates) *coordinateRecorder {
+	return &coordinateRecorder{reader: reader, coordinates: coordinates}
+}
+
+func (cr *coordinateRecorder) Read(p []byte) (int, error) {
+	n, err := cr.reader.Read(p)
+	if remaining := coordinateScanLimit - cr.header.Len(); remaining > 0 && n > 0 {
+		cr.header.Write(p[:min(n, remaining)])
+	}
+	return n, err
+}
+
+func (cr *coordinateRecorder) Close() error {
+	header := cr.header.Bytes()
+	if match := changeMasterPattern.FindSubmatch(header); match != nil {
+		cr.coordinates.File = string(match[1])
+		cr.coordinates.Position, _ = strconv.ParseInt(string(match[2]), 10, 64)
+	}
+	if match := gtidPurgedPattern.FindSubmatch(header); match != nil {
+		cr.coordinates.GTIDPurged = strings.Join(strings.Fields(string(match[1])), "")
+	}
+
+	return cr.reader.Close()
+}
+
+// BinlogStreamer continuously archives binlog files from a MySQL server to storage
+type BinlogStreamer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	TLS      backup.TLSConfig
+
+	StartFile    string
+	ServerID     int
+	PollInterval time.Duration
+
+	Uploader   backup.StorageUploader
+	PathPrefix string
+}
+
+// BinlogKey returns the storage key of an archived binlog file
+func BinlogKey(pathPrefix, name string) string {
+	return backup.JoinKey(pathPrefix, BinlogDir, name)
+}
+
+// BinlogPrefix returns the storage prefix holding archived binlog files
+func BinlogPrefix(pathPrefix string) string {
+	return backup.JoinKey(pathPrefix, BinlogDir) + "/"
+}
+
+// Run streams binlogs with mysqlbinlog until the context is cancelled or the connection fails.
+// Files are uploaded once the server rotates to the next binlog.
+func (s *BinlogStreamer) Run(ctx context.Context) error {
+	connectionArgs, cleanup, err := clientArgs(s.Host, s.Port, s.Username, s.Password, s.TLS)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	startFile, err := s.resolveStartFile(ctx, connectionArgs)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "dbbackup-binlog-*")
+	if err != nil {
+		return fmt.Errorf("failed to create binlog staging directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	args := append([]string{}, connectionArgs...)
+	args = append(args,
+		"--read-from-remote-server",
+		"--raw",
+		"--stop-never",
+		"--result-file="+dir+string(os.PathSeparator),
+	)
+	if s.ServerID > 0 {
+		args = append(args, fmt.Sprintf("--connection-server-id=%d", s.ServerID))
+	}
+	args = append(args, startFile)
+
+	// mysqlbinlog is stopped whenever Run returns, so a failed upload never leaves it running
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(streamCtx, "mysqlbinlog", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start mysqlbinlog: %w", err)
+	}
+
+	logger.Infof("🔄 Streaming binlogs from %s:%d starting at %s", s.Host, s.Port, startFile)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	interval := s.PollInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.uploadFiles(ctx, dir, false); err != nil {
+				// Files that were not uploaded are streamed again on restart, which resumes
+				// from the last archived file
+				cancel()
+				<-done
+				return err
+			}
+		case waitErr := <-done:
+			// Upload whatever was received, including the partially written current file.
+			// A restart re-streams that file from the beginning and overwrites it.
+			uploadErr := s.uploadFiles(context.Background(), dir, true)
+			if ctx.Err() != nil {
+				return uploadErr
+			}
+			if waitErr != nil {
+				return fmt.Errorf("mysqlbinlog failed: %w\nOutput: %s", waitErr, stderr.String())
+			}
+			return uploadErr
+		}
+	}
+}
+
+// resolveStartFile picks the binlog to start from: the explicit start file, the last archived
+// file when resuming, or the oldest binlog still available on the server
+func (s *BinlogStreamer) resolveStartFile(ctx context.Context, connectionArgs []string) (string, error) {
+	if s.StartFile != "" {
+		return s.StartFile, nil
+	}
+
+	if lister, ok := s.Uploader.(backup.StorageDownloader); ok {
+		keys, err := lister.List(ctx, BinlogPrefix(s.PathPrefix))
+		if err != nil {
+			return "", err
+		}
+		if len(keys) > 0 {
+			sort.Strings(keys)
+			return path.Base(keys[len(keys)-1]), nil
+		}
+	}
+
+	args := append(append([]string{}, connectionArgs...), "--batch", "--skip-column-names", "--execute=SHOW BINARY LOGS")
+	output, err := exec.CommandContext(ctx, "mysql", args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list binary logs: %w", err)
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("server has no binary logs (is log_bin enabled?)")
+	}
+
+	return fields[0], nil
+}
+
+// uploadFiles uploads staged binlog files; the newest file is still being written and is
+// only uploaded when all is set
+func (s *BinlogStreamer) uploadFiles(ctx context.Context, dir string, all bool) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read binlog staging directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	if !all && len(names) > 0 {
+		names = names[:len(names)-1]
+	}
+
+	for _, name := range names {
+		if err := s.uploadFile(ctx, filepath.Join(dir, name), name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *BinlogStreamer) uploadFile(ctx context.Context, filePath, name string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open binlog %s: %w", name, err)
+	}
+	defer file.Close()
+
+	key := BinlogKey(s.PathPrefix, name)
+	if _, err := s.Uploader.Upload(ctx, key, file); err != nil {
+		return fmt.Errorf("failed to upload binlog %s: %w", name, err)
+	}
+
+	logger.Infof("✅ Archived binlog %s → %s", name, key)
+	return os.Remove(filePath)
+}
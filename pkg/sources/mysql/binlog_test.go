@@ -0,0 +1,67 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type failingUploader struct{}
+
+func (failingUploader) Upload(ctx context.Context, key string, reader io.Reader) (int64, error) {
+	return 0, errors.New("bucket not found")
+}
+
+func (failingUploader) GetStorageType() string { return "failing" }
+
+func TestBinlogStreamerStopsOnUploadFailure(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	stubTools(t, map[string]string{
+		// Receives a rotated binlog and then streams forever
+		"mysqlbinlog": `for arg in "$@"; do case $arg in --result-file=*) dir=${arg#--result-file=};; esac; done
+echo a > "${dir}binlog.000001"
+echo b > "${dir}binlog.000002"
+echo $$ > ` + pidFile + `
+exec sleep 1000`,
+	})
+
+	streamer := &BinlogStreamer{
+		Host:         "localhost",
+		Port:         3306,
+		StartFile:    "binlog.000001",
+		PollInterval: 20 * time.Millisecond,
+		Uploader:     failingUploader{},
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- streamer.Run(context.Background()) }()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "bucket not found") {
+			t.Fatalf("Run() error = %v, want the upload error", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run() did not return after the upload failed")
+	}
+
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if process, err := os.FindProcess(pid); err == nil && process.Signal(syscall.Signal(0)) == nil {
+		process.Kill()
+		t.Error("mysqlbinlog was left running after Run() returned")
+	}
+}
@@ -0,0 +1,175 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+)
+
+// Probe checks that the dump can run: mysqldump for the CLI engine, the credentials, the
+// privileges the dump options need and the size of the dumped data
+func (d *Dumper) Probe(ctx context.Context) []backup.Check {
+	var checks []backup.Check
+	if err := d.validate(); err != nil {
+		return append(checks, backup.Check{Name: "Options", Err: err})
+	}
+
+	var mysqldump backup.Check
+	if d.engine() == backup.EngineCLI {
+		mysqldump = backup.ToolCheck(ctx, backup.ToolPath(d.BinDir, "mysqldump"), "mysql-bin-dir")
+		checks = append(checks, mysqldump)
+	}
+
+	db, err := d.openNative()
+	if err != nil {
+		return append(checks, backup.Check{Name: "Authentication", Err: err})
+	}
+	defer db.Close()
+
+	var user, serverVersion string
+	if err := db.QueryRowContext(ctx, "SELECT CURRENT_USER(), VERSION()").Scan(&user, &serverVersion); err != nil {
+		return append(checks, backup.Check{Name: "Authentication", Err: err})
+	}
+	checks = append(checks, backup.Check{Name: "Authentication", Detail: fmt.Sprintf("connected as %s to %s", user, serverVersion)})
+
+	if d.engine() == backup.EngineCLI && mysqldump.Err == nil {
+		checks = append(checks, backup.Check{
+			Name:   "Version compatibility",
+			Detail: fmt.Sprintf("%s, server %s", mysqldump.Detail, serverVersion),
+			Err:    checkCompatibility(mysqldump.Detail, serverVersion),
+		})
+	}
+
+	checks = append(checks, d.checkPrivileges(ctx, db))
+	return append(checks, backup.SizeCheck(d.estimateSize(ctx, db)))
+}
+
+// requiredPrivileges lists the privileges the dump options need. Alternatives are separated
+// by "|": MariaDB 10.5 renamed REPLICATION CLIENT to BINLOG MONITOR.
+func (d *Dumper) requiredPrivileges() []string {
+	privileges := []string{"SELECT", "SHOW VIEW", "TRIGGER"}
+	if d.Events {
+		privileges = append(privileges, "EVENT")
+	}
+	if d.MasterData {
+		privileges = append(privileges, "RELOAD", "REPLICATION CLIENT|BINLOG MONITOR")
+	}
+	return privileges
+}
+
+// checkPrivileges compares SHOW GRANTS with the required privileges. Privileges granted
+// through roles are not expanded, so missing ones are only a warning when roles are granted.
+func (d *Dumper) checkPrivileges(ctx context.Context, db *sql.DB) backup.Check {
+	check := backup.Check{Name: "Privileges"}
+
+	rows, err := db.QueryContext(ctx, "SHOW GRANTS")
+	if err != nil {
+		check.Err = err
+		return check
+	}
+	defer rows.Close()
+
+	granted := map[string]bool{}
+	hasRoles := false
+	for rows.Next() {
+		var grant string
+		if err := rows.Scan(&grant); err != nil {
+			check.Err = err
+			return check
+		}
+
+		privileges, target, ok := parseGrant(grant)
+		if !ok {
+			hasRoles = hasRoles || strings.HasPrefix(grant, "GRANT ")
+			continue
+		}
+		if target != "*.*" && (d.AllDatabases || target != quoteIdentifier(d.Database)+".*") {
+			continue
+		}
+		for _, privilege := range privileges {
+			granted[privilege] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		check.Err = err
+		return check
+	}
+
+	var missing []string
+	for _, required := range d.requiredPrivileges() {
+		found := granted["ALL PRIVILEGES"] || granted["ALL"]
+		for _, alternative := range strings.Split(required, "|") {
+			found = found || granted[alternative]
+		}
+		if !found {
+			missing = append(missing, strings.ReplaceAll(required, "|", " or "))
+		}
+	}
+
+	if len(missing) == 0 {
+		check.Detail = strings.Join(d.requiredPrivileges(), ", ")
+		check.Detail = strings.ReplaceAll(check.Detail, "|", " or ")
+		return check
+	}
+
+	check.Err = fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	if hasRoles {
+		check.Err = fmt.Errorf("%w (roles are granted and may provide them)", check.Err)
+		check.Warning = true
+	}
+	return check
+}
+
+// parseGrant splits "GRANT SELECT, SHOW VIEW ON `shop`.* TO `backup`@`%`" into its privileges
+// and target. Role grants, which have no ON clause, are not parsed.
+func parseGrant(grant string) ([]string, string, bool) {
+	rest, found := strings.CutPrefix(grant, "GRANT ")
+	if !found {
+		return nil, "", false
+	}
+	list, rest, found := strings.Cut(rest, " ON ")
+	if !found {
+		return nil, "", false
+	}
+	target, _, _ := strings.Cut(rest, " TO ")
+	target = strings.ReplaceAll(target, `\_`, "_")
+
+	var privileges []string
+	for _, privilege := range strings.Split(list, ",") {
+		// Column-level grants such as SELECT (id) do not cover whole tables
+		if privilege = strings.TrimSpace(privilege); !strings.Contains(privilege, "(") {
+			privileges = append(privileges, strings.ToUpper(privilege))
+		}
+	}
+	return privileges, strings.TrimSpace(target), true
+}
+
+// EstimateSize returns the data and index size of the dumped databases
+func (d *Dumper) EstimateSize(ctx context.Context) (int64, error) {
+	db, err := d.openNative()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	return d.estimateSize(ctx, db)
+}
+
+// estimateSize sums the data and index sizes of the dumped schemas from information_schema
+func (d *Dumper) estimateSize(ctx context.Context, db *sql.DB) (int64, error) {
+	query := "SELECT COALESCE(SUM(data_length + index_length), 0) FROM information_schema.TABLES WHERE table_schema = ?"
+	args := []interface{}{d.Database}
+	if d.AllDatabases {
+		query = "SELECT COALESCE(SUM(data_length + index_length), 0) FROM information_schema.TABLES WHERE table_schema NOT IN ('information_schema', 'performance_schema', 'sys')"
+		args = nil
+	}
+
+	var size int64
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&size); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
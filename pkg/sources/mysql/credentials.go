@@ -0,0 +1,84 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+)
+
+// clientArgs returns connection arguments for the MySQL client tools. The password is written
+// to a temporary option file passed via --defaults-extra-file so it never shows up in ps
+// output; the returned cleanup function removes that file and must always be called.
+func clientArgs(host string, port int, username, password string, tls backup.TLSConfig) ([]string, func(), error) {
+	sslArgs, err := tlsArgs(tls)
+	if err != nil {
+		return nil, func() {}, err
+	}
+
+	var args []string
+	cleanup := func() {}
+
+	// --defaults-extra-file must be the first argument
+	if password != "" {
+		content := fmt.Sprintf("[client]\npassword=\"%s\"\n", escapeOptionValue(password))
+		path, remove, err := backup.WriteSecretFile("dbbackup-mysql-*.cnf", content)
+		if err != nil {
+			return nil, cleanup, err
+		}
+		cleanup = remove
+		args = append(args, "--defaults-extra-file="+path)
+	}
+
+	args = append(args,
+		fmt.Sprintf("--host=%s", host),
+		fmt.Sprintf("--port=%d", port),
+	)
+
+	if username != "" {
+		args = append(args, fmt.Sprintf("--user=%s", username))
+	}
+
+	args = append(args, sslArgs...)
+
+	return args, cleanup, nil
+}
+
+// tlsArgs maps TLS options to the MySQL client --ssl-* options
+func tlsArgs(tls backup.TLSConfig) ([]string, error) {
+	mode, err := tls.NormalizedMode()
+	if err != nil {
+		return nil, err
+	}
+
+	var args []string
+	switch mode {
+	case backup.SSLModeDisable:
+		args = append(args, "--ssl-mode=DISABLED")
+	case backup.SSLModeAllow, backup.SSLModePrefer:
+		args = append(args, "--ssl-mode=PREFERRED")
+	case backup.SSLModeRequire:
+		args = append(args, "--ssl-mode=REQUIRED")
+	case backup.SSLModeVerifyCA:
+		args = append(args, "--ssl-mode=VERIFY_CA")
+	case backup.SSLModeVerifyFull:
+		args = append(args, "--ssl-mode=VERIFY_IDENTITY")
+	}
+
+	if tls.CAFile != "" {
+		args = append(args, "--ssl-ca="+tls.CAFile)
+	}
+	if tls.CertFile != "" {
+		args = append(args, "--ssl-cert="+tls.CertFile)
+	}
+	if tls.KeyFile != "" {
+		args = append(args, "--ssl-key="+tls.KeyFile)
+	}
+
+	return args, nil
+}
+
+// escapeOptionValue escapes a value for a double-quoted MySQL option file string
+func escapeOptionValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
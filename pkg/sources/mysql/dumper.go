@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
-	"os"
+	"net"
+	"net/url"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
 )
 
 type Dumper struct {
@@ -17,47 +21,89 @@ type Dumper struct {
 	Database string
 	Username string
 	Password string
+	TLS      backup.TLSConfig
+
+	// MasterData records binlog coordinates (--master-data=2) for point-in-time recovery
+	MasterData bool
+
+	// Dump scope and content options
+	AllDatabases         bool
+	Tables               []string
+	IgnoreTables         []string
+	NoData               bool
+	Events               bool
+	HexBlob              bool
+	SetGTIDPurged        string
+	DefaultCharacterSet  string
+	SkipColumnStatistics bool
+
+	// Engine selects mysqldump (backup.EngineCLI, the default) or the built-in exporter,
+	// which writes the same SQL through the Go driver (backup.EngineNative)
+	Engine string
+
+	// BinDir is the directory holding mysqldump, to pick a specific client version
+	// (default: look it up in PATH)
+	BinDir string
+
+	coordinates   *BinlogCoordinates
+	clientVersion string
+	serverVersion string
 }
 
 func (d *Dumper) CreateBackupStream(ctx context.Context) (io.ReadCloser, error) {
-	args := []string{
-		fmt.Sprintf("--host=%s", d.Host),
-		fmt.Sprintf("--port=%d", d.Port),
-		"--single-transaction",
-		"--routines",
-		"--triggers",
+	if err := d.validate(); err != nil {
+		return nil, err
 	}
 
-	if d.Username != "" {
-		args = append(args, fmt.Sprintf("--user=%s", d.Username))
+	var reader io.ReadCloser
+	var err error
+	if d.engine() == backup.EngineNative {
+		reader, err = d.createNativeDump(ctx)
+	} else {
+		reader, err = d.createCLIDump(ctx)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	if d.Password != "" {
-		args = append(args, fmt.Sprintf("--password=%s", d.Password))
+	if d.MasterData {
+		d.coordinates = &BinlogCoordinates{}
+		reader = newCoordinateRecorder(reader, d.coordinates)
 	}
 
-	if d.Database != "" {
-		args = append(args, d.Database)
+	return reader, nil
+}
+
+// createCLIDump runs mysqldump
+func (d *Dumper) createCLIDump(ctx context.Context) (io.ReadCloser, error) {
+	if err := d.checkVersions(ctx); err != nil {
+		return nil, err
 	}
 
-	cmd := exec.CommandContext(ctx, "mysqldump", args...)
+	cmd, cleanup, err := d.dumpCommand(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create pipes for both stdout and stderr
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
+		cleanup()
 		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
 
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
 		stdout.Close()
+		cleanup()
 		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
 
 	// Start the command
-	if err := cmd.Start(); err != nil {
+	if err := backup.StartCommand(cmd); err != nil {
 		stdout.Close()
 		stderr.Close()
+		cleanup()
 		return nil, fmt.Errorf("failed to start mysqldump: %w", err)
 	}
 
@@ -66,15 +112,57 @@ func (d *Dumper) CreateBackupStream(ctx context.Context) (io.ReadCloser, error)
 		reader:    stdout,
 		stderr:    stderr,
 		cmd:       cmd,
+		cleanup:   cleanup,
 		validated: false,
 		firstRead: false,
 	}, nil
 }
 
+// dumpCommand builds the mysqldump command. The cleanup function removes the option file
+// holding the password and must be called once the command has finished.
+func (d *Dumper) dumpCommand(ctx context.Context) (*exec.Cmd, func(), error) {
+	dumpArgs, err := d.buildArgs()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	args, cleanup, err := clientArgs(d.Host, d.Port, d.Username, d.Password, d.TLS)
+	if err != nil {
+		return nil, nil, err
+	}
+	args = append(args, dumpArgs...)
+
+	return exec.CommandContext(ctx, backup.ToolPath(d.BinDir, "mysqldump"), args...), cleanup, nil
+}
+
+// DescribeCommand returns the mysqldump command line, or the connection the built-in
+// exporter would use. The password stays in the temporary option file.
+func (d *Dumper) DescribeCommand() ([]string, error) {
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+
+	if d.engine() == backup.EngineNative {
+		target := url.URL{Scheme: "mysql", Host: net.JoinHostPort(d.Host, strconv.Itoa(d.Port)), Path: "/" + d.Database}
+		if d.Username != "" {
+			target.User = url.User(d.Username)
+		}
+		return []string{"built-in exporter (no external command) reading " + target.String()}, nil
+	}
+
+	cmd, cleanup, err := d.dumpCommand(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	cleanup()
+	return []string{backup.FormatCommand(cmd)}, nil
+}
+
 type cmdReader struct {
 	reader    io.ReadCloser
 	stderr    io.ReadCloser
 	cmd       *exec.Cmd
+	cleanup   func()
 	validated bool
 	firstRead bool
 }
@@ -121,6 +209,7 @@ func (cr *cmdReader) Read(p []byte) (int, error) {
 
 func (cr *cmdReader) Close() error {
 	cr.reader.Close()
+	defer cr.cleanup()
 
 	// Read remaining stderr to capture any error messages
 	remainingStderr, _ := io.ReadAll(cr.stderr)
@@ -128,28 +217,105 @@ func (cr *cmdReader) Close() error {
 
 	if err := cr.cmd.Wait(); err != nil {
 		if len(remainingStderr) > 0 {
-			// Check if we should log debug output
 			stderrStr := string(remainingStderr)
-			if strings.Contains(os.Getenv("LOG_LEVEL"), "debug") ||
-				strings.Contains(os.Getenv("LOG_LEVEL"), "DEBUG") {
-				log.Printf("DEBUG: mysqldump stderr: %s", stderrStr)
-			}
+			logger.Debugf("mysqldump stderr: %s", stderrStr)
 			return fmt.Errorf("mysqldump failed: %w\nOutput: %s", err, stderrStr)
 		}
 		return fmt.Errorf("mysqldump failed: %w", err)
 	}
 
-	// Also log any warnings/messages even on success if debug is enabled
+	// Also log any warnings/messages even on success at debug level
 	if len(remainingStderr) > 0 {
-		if strings.Contains(os.Getenv("LOG_LEVEL"), "debug") ||
-			strings.Contains(os.Getenv("LOG_LEVEL"), "DEBUG") {
-			log.Printf("DEBUG: mysqldump stderr: %s", string(remainingStderr))
+		logger.Debugf("mysqldump stderr: %s", string(remainingStderr))
+	}
+
+	return nil
+}
+
+// validate checks the dump scope and options shared by both engines
+func (d *Dumper) validate() error {
+	if d.AllDatabases && (d.Database != "" || len(d.Tables) > 0) {
+		return fmt.Errorf("--all-databases cannot be combined with a database name or --tables")
+	}
+	if !d.AllDatabases && d.Database == "" {
+		return fmt.Errorf("a database name or --all-databases is required")
+	}
+	if engine := d.engine(); engine != backup.EngineCLI && engine != backup.EngineNative {
+		return fmt.Errorf("unsupported engine %q (expected %s or %s)", d.Engine, backup.EngineCLI, backup.EngineNative)
+	}
+	switch strings.ToUpper(d.SetGTIDPurged) {
+	case "", "OFF", "ON", "AUTO":
+	default:
+		return fmt.Errorf("invalid --set-gtid-purged value %q (expected OFF, ON or AUTO)", d.SetGTIDPurged)
+	}
+
+	// Unqualified ignored tables default to the dumped database
+	for _, table := range d.IgnoreTables {
+		if !strings.Contains(table, ".") && d.Database == "" {
+			return fmt.Errorf("--ignore-table %q must be qualified as db.table with --all-databases", table)
 		}
 	}
 
 	return nil
 }
 
+func (d *Dumper) engine() string {
+	if d.Engine == "" {
+		return backup.EngineCLI
+	}
+	return d.Engine
+}
+
+func (d *Dumper) buildArgs() ([]string, error) {
+	args := []string{
+		"--single-transaction",
+		"--routines",
+		"--triggers",
+	}
+
+	if d.MasterData {
+		args = append(args, "--master-data=2")
+	}
+
+	if d.NoData {
+		args = append(args, "--no-data")
+	}
+
+	if d.Events {
+		args = append(args, "--events")
+	}
+
+	if d.HexBlob {
+		args = append(args, "--hex-blob")
+	}
+
+	if d.SetGTIDPurged != "" {
+		args = append(args, fmt.Sprintf("--set-gtid-purged=%s", strings.ToUpper(d.SetGTIDPurged)))
+	}
+
+	if d.DefaultCharacterSet != "" {
+		args = append(args, fmt.Sprintf("--default-character-set=%s", d.DefaultCharacterSet))
+	}
+
+	// mysqldump 8.0 queries information_schema.column_statistics, which older servers lack
+	if d.SkipColumnStatistics {
+		args = append(args, "--column-statistics=0")
+	}
+
+	for _, table := range d.IgnoreTables {
+		args = append(args, fmt.Sprintf("--ignore-table=%s", d.qualifyTable(table)))
+	}
+
+	if d.AllDatabases {
+		args = append(args, "--all-databases")
+	} else {
+		args = append(args, d.Database)
+		args = append(args, d.Tables...)
+	}
+
+	return args, nil
+}
+
 // GetFileExtension returns the file extension for MySQL dumps
 func (d *Dumper) GetFileExtension() string {
 	return ".sql"
@@ -162,5 +328,33 @@ func (d *Dumper) GetDatabaseType() string {
 
 // GetDatabaseName returns the database name
 func (d *Dumper) GetDatabaseName() string {
+	if d.AllDatabases {
+		return "all"
+	}
 	return d.Database
 }
+
+// GetDatabaseHost returns the database host for object key templates
+func (d *Dumper) GetDatabaseHost() string {
+	return d.Host
+}
+
+// qualifyTable returns a table name as db.table, defaulting to the dumped database
+func (d *Dumper) qualifyTable(table string) string {
+	if strings.Contains(table, ".") {
+		return table
+	}
+	return d.Database + "." + table
+}
+
+// GetBackupMetadata returns the dump engine, versions and the binlog coordinates captured from the dump
+func (d *Dumper) GetBackupMetadata() map[string]string {
+	metadata := backup.VersionMetadata(d.clientVersion, d.serverVersion)
+	metadata["engine"] = d.engine()
+	if d.coordinates != nil {
+		for key, value := range d.coordinates.Metadata() {
+			metadata[key] = value
+		}
+	}
+	return metadata
+}
@@ -0,0 +1,817 @@
+package mysql
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+	"github.com/go-sql-driver/mysql"
+)
+
+// insertBatchSize is the approximate size of one extended INSERT statement, well below
+// the default max_allowed_packet
+const insertBatchSize = 1 << 20
+
+// systemDatabases are skipped by --all-databases, as mysqldump does
+var systemDatabases = map[string]bool{
+	"information_schema": true,
+	"performance_schema": true,
+	"sys":                true,
+}
+
+// logTables hold server logs; only their definitions are dumped
+var logTables = map[string]bool{
+	"mysql.general_log": true,
+	"mysql.slow_log":    true,
+}
+
+// createNativeDump exports the databases as SQL through the Go driver, in the format
+// mysqldump --single-transaction --routines --triggers produces
+func (d *Dumper) createNativeDump(ctx context.Context) (io.ReadCloser, error) {
+	db, err := d.openNative()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to %s: %w", net.JoinHostPort(d.Host, strconv.Itoa(d.Port)), err)
+	}
+
+	exporter := &nativeExporter{dumper: d, conn: conn}
+	if err := exporter.begin(ctx); err != nil {
+		conn.Close()
+		db.Close()
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	reader := &nativeReader{reader: pr, done: make(chan error, 1)}
+
+	go func() {
+		defer db.Close()
+		defer conn.Close()
+
+		writer := bufio.NewWriterSize(pw, 256*1024)
+		exporter.out = writer
+		err := exporter.run(ctx)
+		if err == nil {
+			err = writer.Flush()
+		}
+		pw.CloseWithError(err)
+		reader.done <- err
+	}()
+
+	return reader, nil
+}
+
+// openNative opens a connection pool for the dump. Only a single connection is used.
+func (d *Dumper) openNative() (*sql.DB, error) {
+	config := mysql.NewConfig()
+	config.User = d.Username
+	config.Passwd = d.Password
+	config.Net = "tcp"
+	config.Addr = net.JoinHostPort(d.Host, strconv.Itoa(d.Port))
+	config.Timeout = 10 * time.Second
+	config.Loc = time.UTC
+	// Metadata queries are sent as plain statements instead of server-side prepared ones
+	config.InterpolateParams = true
+
+	mode, err := d.TLS.NormalizedMode()
+	if err != nil {
+		return nil, err
+	}
+	clientConfig, err := d.TLS.ClientConfig(d.Host)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case clientConfig != nil:
+		config.TLS = clientConfig
+	case mode == backup.SSLModeAllow || mode == backup.SSLModePrefer:
+		// Encrypt when the server supports it, like --ssl-mode=PREFERRED
+		config.TLSConfig = "preferred"
+	}
+
+	connector, err := mysql.NewConnector(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MySQL connection settings: %w", err)
+	}
+
+	db := sql.OpenDB(connector)
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// nativeExporter writes the dump of one consistent snapshot
+type nativeExporter struct {
+	dumper *Dumper
+	conn   *sql.Conn
+	out    *bufio.Writer
+
+	version    string
+	binlogFile string
+	binlogPos  string
+	gtidSet    string
+}
+
+// begin opens the consistent snapshot. With MasterData, tables are briefly locked so the
+// binlog coordinates match the snapshot exactly.
+func (e *nativeExporter) begin(ctx context.Context) error {
+	d := e.dumper
+
+	charset := d.DefaultCharacterSet
+	if charset == "" {
+		charset = "utf8mb4"
+	}
+	for _, statement := range []string{
+		"SET NAMES " + charset,
+		"SET SESSION time_zone = '+00:00'",
+		"SET SESSION sql_mode = ''",
+	} {
+		if _, err := e.conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to prepare session (%s): %w", statement, err)
+		}
+	}
+
+	if err := e.conn.QueryRowContext(ctx, "SELECT VERSION()").Scan(&e.version); err != nil {
+		return fmt.Errorf("failed to read server version: %w", err)
+	}
+	d.serverVersion = e.version
+
+	if d.MasterData {
+		if _, err := e.conn.ExecContext(ctx, "FLUSH TABLES WITH READ LOCK"); err != nil {
+			return fmt.Errorf("failed to lock tables for binlog coordinates: %w", err)
+		}
+		defer e.conn.ExecContext(context.Background(), "UNLOCK TABLES")
+	}
+
+	if _, err := e.conn.ExecContext(ctx, "SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ"); err != nil {
+		return fmt.Errorf("failed to set isolation level: %w", err)
+	}
+	if _, err := e.conn.ExecContext(ctx, "START TRANSACTION WITH CONSISTENT SNAPSHOT"); err != nil {
+		return fmt.Errorf("failed to start consistent snapshot: %w", err)
+	}
+
+	if d.MasterData {
+		status, err := e.queryRow(ctx, "SHOW MASTER STATUS")
+		if err != nil {
+			// MySQL 8.4 removed SHOW MASTER STATUS
+			status, err = e.queryRow(ctx, "SHOW BINARY LOG STATUS")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read binlog coordinates: %w", err)
+		}
+		if status == nil {
+			return fmt.Errorf("binary logging is not enabled on the server")
+		}
+		e.binlogFile, e.binlogPos = status["File"], status["Position"]
+	}
+
+	return e.readGTIDs(ctx)
+}
+
+// readGTIDs reads the executed GTID set when --set-gtid-purged asks for it
+func (e *nativeExporter) readGTIDs(ctx context.Context) error {
+	setting := strings.ToUpper(e.dumper.SetGTIDPurged)
+	if setting == "OFF" {
+		return nil
+	}
+
+	var gtidMode string
+	if err := e.conn.QueryRowContext(ctx, "SELECT @@GLOBAL.gtid_mode").Scan(&gtidMode); err != nil || !strings.EqualFold(gtidMode, "ON") {
+		if setting == "ON" {
+			return fmt.Errorf("--set-gtid-purged=ON requires a server with gtid_mode=ON")
+		}
+		return nil
+	}
+
+	if err := e.conn.QueryRowContext(ctx, "SELECT @@GLOBAL.gtid_executed").Scan(&e.gtidSet); err != nil {
+		return fmt.Errorf("failed to read gtid_executed: %w", err)
+	}
+	return nil
+}
+
+func (e *nativeExporter) run(ctx context.Context) error {
+	d := e.dumper
+	defer e.conn.ExecContext(context.Background(), "ROLLBACK")
+
+	e.writeHeader()
+
+	databases := []string{d.Database}
+	if d.AllDatabases {
+		var err error
+		if databases, err = e.listDatabases(ctx); err != nil {
+			return err
+		}
+	}
+
+	for _, database := range databases {
+		if err := e.dumpDatabase(ctx, database); err != nil {
+			return err
+		}
+	}
+
+	e.writeFooter()
+	return nil
+}
+
+func (e *nativeExporter) writeHeader() {
+	d := e.dumper
+	database := d.Database
+	if d.AllDatabases {
+		database = "(all databases)"
+	}
+
+	fmt.Fprintf(e.out, "-- dbbackup native MySQL dump\n--\n-- Host: %s    Database: %s\n", d.Host, database)
+	fmt.Fprintf(e.out, "-- Server version\t%s\n-- Dump started at %s\n\n", e.version, time.Now().UTC().Format(time.RFC3339))
+
+	e.out.WriteString("/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;\n")
+	e.out.WriteString("/*!40101 SET @OLD_CHARACTER_SET_RESULTS=@@CHARACTER_SET_RESULTS */;\n")
+	e.out.WriteString("/*!40101 SET @OLD_COLLATION_CONNECTION=@@COLLATION_CONNECTION */;\n")
+	fmt.Fprintf(e.out, "/*!50503 SET NAMES %s */;\n", e.charset())
+	e.out.WriteString("/*!40103 SET @OLD_TIME_ZONE=@@TIME_ZONE */;\n")
+	e.out.WriteString("/*!40103 SET TIME_ZONE='+00:00' */;\n")
+	e.out.WriteString("/*!40014 SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0 */;\n")
+	e.out.WriteString("/*!40014 SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0 */;\n")
+	e.out.WriteString("/*!40101 SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='NO_AUTO_VALUE_ON_ZERO' */;\n")
+	e.out.WriteString("/*!40111 SET @OLD_SQL_NOTES=@@SQL_NOTES, SQL_NOTES=0 */;\n\n")
+
+	if e.gtidSet != "" {
+		e.out.WriteString("SET @MYSQLDUMP_TEMP_LOG_BIN = @@SESSION.SQL_LOG_BIN;\nSET @@SESSION.SQL_LOG_BIN= 0;\n\n")
+		fmt.Fprintf(e.out, "--\n-- GTID state at the beginning of the backup\n--\n\nSET @@GLOBAL.GTID_PURGED=/*!80000 '+'*/ '%s';\n\n", e.gtidSet)
+	}
+
+	if e.binlogFile != "" {
+		// Commented out like mysqldump --master-data=2; the coordinates end up in the manifest
+		fmt.Fprintf(e.out, "--\n-- Position to start replication or point-in-time recovery from\n--\n\n-- CHANGE MASTER TO MASTER_LOG_FILE='%s', MASTER_LOG_POS=%s;\n\n", e.binlogFile, e.binlogPos)
+	}
+}
+
+func (e *nativeExporter) writeFooter() {
+	if e.gtidSet != "" {
+		e.out.WriteString("SET @@SESSION.SQL_LOG_BIN = @MYSQLDUMP_TEMP_LOG_BIN;\n")
+	}
+	e.out.WriteString("/*!40103 SET TIME_ZONE=@OLD_TIME_ZONE */;\n")
+	e.out.WriteString("/*!40101 SET SQL_MODE=@OLD_SQL_MODE */;\n")
+	e.out.WriteString("/*!40014 SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS */;\n")
+	e.out.WriteString("/*!40014 SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS */;\n")
+	e.out.WriteString("/*!40101 SET CHARACTER_SET_CLIENT=@OLD_CHARACTER_SET_CLIENT */;\n")
+	e.out.WriteString("/*!40101 SET CHARACTER_SET_RESULTS=@OLD_CHARACTER_SET_RESULTS */;\n")
+	e.out.WriteString("/*!40101 SET COLLATION_CONNECTION=@OLD_COLLATION_CONNECTION */;\n")
+	e.out.WriteString("/*!40111 SET SQL_NOTES=@OLD_SQL_NOTES */;\n\n")
+	fmt.Fprintf(e.out, "-- Dump completed at %s\n", time.Now().UTC().Format(time.RFC3339))
+}
+
+func (e *nativeExporter) charset() string {
+	if e.dumper.DefaultCharacterSet != "" {
+		return e.dumper.DefaultCharacterSet
+	}
+	return "utf8mb4"
+}
+
+func (e *nativeExporter) listDatabases(ctx context.Context) ([]string, error) {
+	names, err := e.queryStrings(ctx, "SHOW DATABASES")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list databases: %w", err)
+	}
+
+	var databases []string
+	for _, name := range names {
+		if !systemDatabases[strings.ToLower(name)] {
+			databases = append(databases, name)
+		}
+	}
+	return databases, nil
+}
+
+// dumpDatabase writes the tables, views, triggers, routines and events of a database
+func (e *nativeExporter) dumpDatabase(ctx context.Context, database string) error {
+	d := e.dumper
+	logger.Infof("📦 Dumping database %s", database)
+
+	if d.AllDatabases {
+		create, err := e.queryRow(ctx, "SHOW CREATE DATABASE IF NOT EXISTS "+quoteIdentifier(database))
+		if err != nil {
+			return fmt.Errorf("failed to read definition of database %s: %w", database, err)
+		}
+		fmt.Fprintf(e.out, "--\n-- Current Database: %s\n--\n\n%s;\n\nUSE %s;\n\n", quoteIdentifier(database), create["Create Database"], quoteIdentifier(database))
+	}
+
+	tables, views, err := e.listTables(ctx, database)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		if err := e.dumpTable(ctx, database, table); err != nil {
+			return err
+		}
+	}
+
+	// Views are created in two passes so views selecting from other views load in any order
+	for _, view := range views {
+		if err := e.writeViewStandIn(ctx, database, view); err != nil {
+			return err
+		}
+	}
+	for _, view := range views {
+		if err := e.writeView(ctx, database, view); err != nil {
+			return err
+		}
+	}
+
+	// Triggers come after all rows are loaded so they don't fire during the restore
+	if err := e.dumpTriggers(ctx, database, tables); err != nil {
+		return err
+	}
+	if err := e.dumpRoutines(ctx, database); err != nil {
+		return err
+	}
+	if d.Events {
+		if err := e.dumpEvents(ctx, database); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listTables returns the base tables and views of a database selected by --tables and --ignore-table
+func (e *nativeExporter) listTables(ctx context.Context, database string) ([]string, []string, error) {
+	d := e.dumper
+
+	rows, err := e.conn.QueryContext(ctx, "SHOW FULL TABLES FROM "+quoteIdentifier(database))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list tables of %s: %w", database, err)
+	}
+	defer rows.Close()
+
+	selected := map[string]bool{}
+	for _, table := range d.Tables {
+		selected[table] = true
+	}
+	ignored := map[string]bool{}
+	for _, table := range d.IgnoreTables {
+		ignored[d.qualifyTable(table)] = true
+	}
+
+	var tables, views []string
+	for rows.Next() {
+		var name, tableType string
+		if err := rows.Scan(&name, &tableType); err != nil {
+			return nil, nil, err
+		}
+		if len(selected) > 0 && !selected[name] {
+			continue
+		}
+		if ignored[database+"."+name] {
+			continue
+		}
+		if tableType == "VIEW" {
+			views = append(views, name)
+		} else {
+			tables = append(tables, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	for table := range selected {
+		if !contains(tables, table) && !contains(views, table) {
+			return nil, nil, fmt.Errorf("table %s.%s does not exist", database, table)
+		}
+	}
+
+	return tables, views, nil
+}
+
+func (e *nativeExporter) dumpTable(ctx context.Context, database, table string) error {
+	name := quoteIdentifier(database) + "." + quoteIdentifier(table)
+
+	create, err := e.queryRow(ctx, "SHOW CREATE TABLE "+name)
+	if err != nil {
+		return fmt.Errorf("failed to read definition of table %s.%s: %w", database, table, err)
+	}
+
+	fmt.Fprintf(e.out, "--\n-- Table structure for table %s\n--\n\n", quoteIdentifier(table))
+	fmt.Fprintf(e.out, "DROP TABLE IF EXISTS %s;\n", quoteIdentifier(table))
+	e.out.WriteString("/*!40101 SET @saved_cs_client     = @@character_set_client */;\n")
+	fmt.Fprintf(e.out, "/*!50503 SET character_set_client = %s */;\n", e.charset())
+	fmt.Fprintf(e.out, "%s;\n", create["Create Table"])
+	e.out.WriteString("/*!40101 SET character_set_client = @saved_cs_client */;\n\n")
+
+	if e.dumper.NoData || logTables[strings.ToLower(database+"."+table)] {
+		return nil
+	}
+
+	count, err := e.dumpRows(ctx, database, table)
+	if err != nil {
+		return fmt.Errorf("failed to dump rows of %s.%s: %w", database, table, err)
+	}
+	logger.Infof("📄 Dumped %d row(s) from %s.%s", count, database, table)
+
+	return nil
+}
+
+// dumpRows writes the rows of a table as extended INSERT statements. Generated columns
+// are left out, since the server computes them.
+func (e *nativeExporter) dumpRows(ctx context.Context, database, table string) (int64, error) {
+	columns, hasGenerated, err := e.storedColumns(ctx, database, table)
+	if err != nil {
+		return 0, err
+	}
+
+	selectList := "*"
+	insertPrefix := "INSERT INTO " + quoteIdentifier(table) + " VALUES "
+	if hasGenerated {
+		quoted := make([]string, len(columns))
+		for i, column := range columns {
+			quoted[i] = quoteIdentifier(column)
+		}
+		selectList = strings.Join(quoted, ",")
+		insertPrefix = "INSERT INTO " + quoteIdentifier(table) + " (" + selectList + ") VALUES "
+	}
+
+	rows, err := e.conn.QueryContext(ctx, "SELECT "+selectList+" FROM "+quoteIdentifier(database)+"."+quoteIdentifier(table))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, err
+	}
+	binary := make([]bool, len(types))
+	numeric := make([]bool, len(types))
+	for i, columnType := range types {
+		binary[i], numeric[i] = classifyColumn(columnType.DatabaseTypeName())
+	}
+
+	values := make([][]byte, len(types))
+	scanArgs := make([]interface{}, len(types))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+
+	fmt.Fprintf(e.out, "--\n-- Dumping data for table %s\n--\n\n", quoteIdentifier(table))
+	fmt.Fprintf(e.out, "LOCK TABLES %s WRITE;\n/*!40000 ALTER TABLE %s DISABLE KEYS */;\n", quoteIdentifier(table), quoteIdentifier(table))
+
+	var count int64
+	var statement strings.Builder
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return count, err
+		}
+
+		if statement.Len() == 0 {
+			statement.WriteString(insertPrefix)
+		} else {
+			statement.WriteByte(',')
+		}
+		statement.WriteByte('(')
+		for i, value := range values {
+			if i > 0 {
+				statement.WriteByte(',')
+			}
+			writeValue(&statement, value, binary[i], numeric[i])
+		}
+		statement.WriteByte(')')
+		count++
+
+		if statement.Len() >= insertBatchSize {
+			statement.WriteString(";\n")
+			if _, err := e.out.WriteString(statement.String()); err != nil {
+				return count, err
+			}
+			statement.Reset()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+	if statement.Len() > 0 {
+		statement.WriteString(";\n")
+		e.out.WriteString(statement.String())
+	}
+
+	fmt.Fprintf(e.out, "/*!40000 ALTER TABLE %s ENABLE KEYS */;\nUNLOCK TABLES;\n\n", quoteIdentifier(table))
+	return count, nil
+}
+
+// storedColumns returns the columns holding data and whether the table has generated columns
+func (e *nativeExporter) storedColumns(ctx context.Context, database, table string) ([]string, bool, error) {
+	rows, err := e.conn.QueryContext(ctx,
+		"SELECT COLUMN_NAME, EXTRA FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION",
+		database, table)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	hasGenerated := false
+	for rows.Next() {
+		var name, extra string
+		if err := rows.Scan(&name, &extra); err != nil {
+			return nil, false, err
+		}
+		extra = strings.ToUpper(extra)
+		if strings.Contains(extra, "VIRTUAL GENERATED") || strings.Contains(extra, "STORED GENERATED") || extra == "PERSISTENT GENERATED" {
+			hasGenerated = true
+			continue
+		}
+		columns = append(columns, name)
+	}
+
+	return columns, hasGenerated, rows.Err()
+}
+
+// classifyColumn reports whether values of a column type are written as hex literals
+// or unquoted numbers
+func classifyColumn(typeName string) (binary, numeric bool) {
+	typeName = strings.TrimPrefix(typeName, "UNSIGNED ")
+	switch typeName {
+	case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB", "BIT", "GEOMETRY":
+		return true, false
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT", "DECIMAL", "FLOAT", "DOUBLE", "YEAR":
+		return false, true
+	}
+	return false, false
+}
+
+// writeValue renders a column value in its text protocol form as an SQL literal
+func writeValue(b *strings.Builder, value []byte, binary, numeric bool) {
+	switch {
+	case value == nil:
+		b.WriteString("NULL")
+	case binary:
+		if len(value) == 0 {
+			b.WriteString("''")
+			return
+		}
+		b.WriteString("0x")
+		b.WriteString(hex.EncodeToString(value))
+	case numeric:
+		b.Write(value)
+	default:
+		b.WriteByte('\'')
+		writeEscaped(b, value)
+		b.WriteByte('\'')
+	}
+}
+
+// writeEscaped escapes a string literal like mysql_real_escape_string
+func writeEscaped(b *strings.Builder, value []byte) {
+	for _, c := range value {
+		switch c {
+		case 0:
+			b.WriteString(`\0`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '"':
+			b.WriteString(`\"`)
+		case 0x1a:
+			b.WriteString(`\Z`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+// writeViewStandIn creates a placeholder view with the view's columns
+func (e *nativeExporter) writeViewStandIn(ctx context.Context, database, view string) error {
+	columns, err := e.queryStrings(ctx,
+		"SELECT COLUMN_NAME FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION",
+		database, view)
+	if err != nil {
+		return fmt.Errorf("failed to read columns of view %s.%s: %w", database, view, err)
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("view %s.%s has no columns (is it invalid?)", database, view)
+	}
+
+	selectList := make([]string, len(columns))
+	for i, column := range columns {
+		selectList[i] = "1 AS " + quoteIdentifier(column)
+	}
+
+	fmt.Fprintf(e.out, "--\n-- Temporary view structure for view %s\n--\n\n", quoteIdentifier(view))
+	fmt.Fprintf(e.out, "DROP TABLE IF EXISTS %s;\n/*!50001 DROP VIEW IF EXISTS %s*/;\n", quoteIdentifier(view), quoteIdentifier(view))
+	fmt.Fprintf(e.out, "/*!50001 CREATE VIEW %s AS SELECT \n %s */;\n\n", quoteIdentifier(view), strings.Join(selectList, ",\n "))
+	return nil
+}
+
+func (e *nativeExporter) writeView(ctx context.Context, database, view string) error {
+	create, err := e.queryRow(ctx, "SHOW CREATE VIEW "+quoteIdentifier(database)+"."+quoteIdentifier(view))
+	if err != nil {
+		return fmt.Errorf("failed to read definition of view %s.%s: %w", database, view, err)
+	}
+
+	fmt.Fprintf(e.out, "--\n-- Final view structure for view %s\n--\n\n", quoteIdentifier(view))
+	fmt.Fprintf(e.out, "/*!50001 DROP VIEW IF EXISTS %s*/;\n", quoteIdentifier(view))
+	fmt.Fprintf(e.out, "/*!50001 SET character_set_client      = %s */;\n", create["character_set_client"])
+	fmt.Fprintf(e.out, "/*!50001 SET collation_connection      = %s */;\n", create["collation_connection"])
+	fmt.Fprintf(e.out, "%s;\n", create["Create View"])
+	fmt.Fprintf(e.out, "/*!50001 SET character_set_client      = %s */;\n", e.charset())
+	fmt.Fprintf(e.out, "/*!50001 SET collation_connection      = @@collation_database */;\n\n")
+	return nil
+}
+
+func (e *nativeExporter) dumpTriggers(ctx context.Context, database string, tables []string) error {
+	rows, err := e.queryRows(ctx, "SHOW TRIGGERS FROM "+quoteIdentifier(database))
+	if err != nil {
+		return fmt.Errorf("failed to list triggers of %s: %w", database, err)
+	}
+
+	for _, trigger := range rows {
+		if !contains(tables, trigger["Table"]) {
+			continue
+		}
+		create, err := e.queryRow(ctx, "SHOW CREATE TRIGGER "+quoteIdentifier(database)+"."+quoteIdentifier(trigger["Trigger"]))
+		if err != nil {
+			return fmt.Errorf("failed to read definition of trigger %s.%s: %w", database, trigger["Trigger"], err)
+		}
+		// Triggers were dropped along with their table
+		e.writeStoredProgram("trigger", trigger["Trigger"], "", create["sql_mode"], create["SQL Original Statement"])
+	}
+
+	return nil
+}
+
+func (e *nativeExporter) dumpRoutines(ctx context.Context, database string) error {
+	routines, err := e.queryRows(ctx,
+		"SELECT ROUTINE_NAME, ROUTINE_TYPE FROM information_schema.ROUTINES WHERE ROUTINE_SCHEMA = ? ORDER BY ROUTINE_TYPE, ROUTINE_NAME",
+		database)
+	if err != nil {
+		return fmt.Errorf("failed to list routines of %s: %w", database, err)
+	}
+
+	for _, routine := range routines {
+		kind := strings.ToUpper(routine["ROUTINE_TYPE"])
+		if kind != "PROCEDURE" && kind != "FUNCTION" {
+			continue
+		}
+		name := routine["ROUTINE_NAME"]
+
+		create, err := e.queryRow(ctx, "SHOW CREATE "+kind+" "+quoteIdentifier(database)+"."+quoteIdentifier(name))
+		if err != nil {
+			return fmt.Errorf("failed to read definition of %s %s.%s: %w", strings.ToLower(kind), database, name, err)
+		}
+		// SHOW CREATE returns the definition in a "Create Procedure" or "Create Function" column
+		column := "Create " + kind[:1] + strings.ToLower(kind[1:])
+		drop := fmt.Sprintf("/*!50003 DROP %s IF EXISTS %s */;\n", kind, quoteIdentifier(name))
+		e.writeStoredProgram(strings.ToLower(kind), name, drop, create["sql_mode"], create[column])
+	}
+
+	return nil
+}
+
+func (e *nativeExporter) dumpEvents(ctx context.Context, database string) error {
+	events, err := e.queryRows(ctx, "SHOW EVENTS FROM "+quoteIdentifier(database))
+	if err != nil {
+		return fmt.Errorf("failed to list events of %s: %w", database, err)
+	}
+
+	for _, event := range events {
+		create, err := e.queryRow(ctx, "SHOW CREATE EVENT "+quoteIdentifier(database)+"."+quoteIdentifier(event["Name"]))
+		if err != nil {
+			return fmt.Errorf("failed to read definition of event %s.%s: %w", database, event["Name"], err)
+		}
+		drop := fmt.Sprintf("/*!50106 DROP EVENT IF EXISTS %s */;\n", quoteIdentifier(event["Name"]))
+		e.writeStoredProgram("event", event["Name"], drop, create["sql_mode"], create["Create Event"])
+	}
+
+	return nil
+}
+
+// writeStoredProgram writes a trigger, routine or event definition with the sql_mode it
+// was created under. Bodies may contain semicolons, so the delimiter is changed.
+func (e *nativeExporter) writeStoredProgram(kind, name, drop, sqlMode, definition string) {
+	fmt.Fprintf(e.out, "--\n-- Definition of %s %s\n--\n\n", kind, quoteIdentifier(name))
+	e.out.WriteString(drop)
+	fmt.Fprintf(e.out, "/*!50003 SET @saved_sql_mode = @@sql_mode */;\n/*!50003 SET sql_mode = '%s' */;\n", escapeString(sqlMode))
+	fmt.Fprintf(e.out, "DELIMITER ;;\n%s ;;\nDELIMITER ;\n", definition)
+	e.out.WriteString("/*!50003 SET sql_mode = @saved_sql_mode */;\n\n")
+}
+
+// queryRows runs a query and returns every row as a map of column name to value
+func (e *nativeExporter) queryRows(ctx context.Context, query string, args ...interface{}) ([]map[string]string, error) {
+	rows, err := e.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]sql.NullString, len(columns))
+	scanArgs := make([]interface{}, len(columns))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+
+	var result []map[string]string
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]string, len(columns))
+		for i, column := range columns {
+			row[column] = values[i].String
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+// queryRow returns the first row of a query, or nil when there is none
+func (e *nativeExporter) queryRow(ctx context.Context, query string, args ...interface{}) (map[string]string, error) {
+	rows, err := e.queryRows(ctx, query, args...)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return rows[0], nil
+}
+
+// queryStrings returns the first column of every row of a query
+func (e *nativeExporter) queryStrings(ctx context.Context, query string, args ...interface{}) ([]string, error) {
+	rows, err := e.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+	}
+
+	return result, rows.Err()
+}
+
+// quoteIdentifier quotes a database, table or column name with backticks
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func escapeString(value string) string {
+	var b strings.Builder
+	writeEscaped(&b, []byte(value))
+	return b.String()
+}
+
+func contains(items []string, item string) bool {
+	for _, candidate := range items {
+		if candidate == item {
+			return true
+		}
+	}
+	return false
+}
+
+// nativeReader streams the output of the export goroutine. Closing it waits for the goroutine.
+type nativeReader struct {
+	reader *io.PipeReader
+	done   chan error
+}
+
+func (nr *nativeReader) Read(p []byte) (int, error) {
+	return nr.reader.Read(p)
+}
+
+func (nr *nativeReader) Close() error {
+	nr.reader.Close()
+
+	if err := <-nr.done; err != nil && !errors.Is(err, io.ErrClosedPipe) {
+		return fmt.Errorf("native dump failed: %w", err)
+	}
+
+	return nil
+}
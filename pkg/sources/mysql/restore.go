@@ -0,0 +1,170 @@
+package mysql
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+)
+
+// Restorer loads dumps and replays binlogs into a MySQL server
+type Restorer struct {
+	Host     string
+	Port     int
+	Database string
+	Username string
+	Password string
+	TLS      backup.TLSConfig
+}
+
+// ReplayOptions controls how far archived binlogs are replayed
+type ReplayOptions struct {
+	StartPosition int64
+	StopDatetime  time.Time
+	StopGTID      string
+	// SourceDatabase limits the replay to the events of the backed up database, which are
+	// applied to the restorer's database. Empty replays every database under its own name.
+	SourceDatabase string
+}
+
+// SelectBinlogs returns the archived binlog keys from the start file onwards, in order
+func SelectBinlogs(keys []string, startFile string) []string {
+	var selected []string
+	for _, key := range keys {
+		if path.Base(key) >= startFile && !strings.HasSuffix(key, ".index") {
+			selected = append(selected, key)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return path.Base(selected[i]) < path.Base(selected[j])
+	})
+	return selected
+}
+
+// LoadDump pipes a (optionally gzip-compressed) SQL dump into the mysql client
+func (r *Restorer) LoadDump(ctx context.Context, reader io.Reader) error {
+	buffered := bufio.NewReader(reader)
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return fmt.Errorf("failed to open gzip stream: %w", err)
+		}
+		defer gz.Close()
+		return r.runMySQL(ctx, gz, r.Database)
+	}
+
+	return r.runMySQL(ctx, buffered, r.Database)
+}
+
+// ReplayBinlogs decodes local binlog files with mysqlbinlog and applies them with the mysql client
+func (r *Restorer) ReplayBinlogs(ctx context.Context, files []string, options ReplayOptions) error {
+	if len(files) == 0 {
+		return fmt.Errorf("no binlog files to replay")
+	}
+
+	args := []string{}
+	if options.StartPosition > 0 {
+		args = append(args, fmt.Sprintf("--start-position=%d", options.StartPosition))
+	}
+	if !options.StopDatetime.IsZero() {
+		// mysqlbinlog reads the datetime in the local time zone
+		args = append(args, "--stop-datetime="+options.StopDatetime.Local().Format("2006-01-02 15:04:05"))
+	}
+	if options.StopGTID != "" {
+		exclude, err := gtidsAfter(options.StopGTID)
+		if err != nil {
+			return err
+		}
+		args = append(args, "--exclude-gtids="+exclude)
+	}
+	args = append(args, replayDatabaseArgs(options.SourceDatabase, r.Database)...)
+	args = append(args, files...)
+
+	decoder := exec.CommandContext(ctx, "mysqlbinlog", args...)
+	var decoderStderr bytes.Buffer
+	decoder.Stderr = &decoderStderr
+
+	events, err := decoder.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	if err := decoder.Start(); err != nil {
+		return fmt.Errorf("failed to start mysqlbinlog: %w", err)
+	}
+
+	// Binlog events carry their own USE statements, so no default database is selected
+	applyErr := r.runMySQL(ctx, events, "")
+
+	// If mysql exits before reading everything, closing the last read end of the pipe makes
+	// mysqlbinlog fail with a broken pipe instead of blocking on a full pipe forever
+	events.Close()
+	decodeErr := decoder.Wait()
+	if applyErr != nil {
+		return applyErr
+	}
+	if decodeErr != nil {
+		return fmt.Errorf("mysqlbinlog failed: %w\nOutput: %s", decodeErr, decoderStderr.String())
+	}
+
+	return nil
+}
+
+// replayDatabaseArgs keeps other databases on the server out of the replay. mysqlbinlog
+// rewrites database names before filtering, so the filter names the target database.
+func replayDatabaseArgs(source, target string) []string {
+	if source == "" {
+		return nil
+	}
+	if target == "" || target == source {
+		return []string{"--database=" + source}
+	}
+	return []string{"--rewrite-db=" + source + "->" + target, "--database=" + target}
+}
+
+func (r *Restorer) runMySQL(ctx context.Context, input io.Reader, database string) error {
+	args, cleanup, err := clientArgs(r.Host, r.Port, r.Username, r.Password, r.TLS)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if database != "" {
+		args = append(args, database)
+	}
+
+	cmd := exec.CommandContext(ctx, "mysql", args...)
+	cmd.Stdin = input
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("mysql failed: %w\nOutput: %s", err, string(output))
+	}
+
+	return nil
+}
+
+// gtidsAfter turns a stop GTID (uuid:N) into the GTID range to exclude (uuid:N+1-max)
+func gtidsAfter(stopGTID string) (string, error) {
+	uuid, number, found := strings.Cut(stopGTID, ":")
+	if !found {
+		return "", fmt.Errorf("invalid GTID %q (expected uuid:transaction)", stopGTID)
+	}
+
+	transaction, err := strconv.ParseInt(number, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid GTID %q (expected uuid:transaction): %w", stopGTID, err)
+	}
+
+	return fmt.Sprintf("%s:%d-%d", uuid, transaction+1, int64(1<<63-2)), nil
+}
@@ -0,0 +1,105 @@
+package mysql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubTools puts shell scripts named after the MySQL client tools first on PATH
+func stubTools(t *testing.T, scripts map[string]string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the tool stubs are shell scripts")
+	}
+
+	dir := t.TempDir()
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestReplayBinlogsMySQLFails(t *testing.T) {
+	stubTools(t, map[string]string{
+		// Writes far more than a pipe buffer holds and only stops on a broken pipe
+		"mysqlbinlog": `exec yes "INSERT INTO t VALUES (1);"`,
+		"mysql":       `echo "ERROR 1045 (28000): Access denied" >&2; exit 1`,
+	})
+
+	restorer := &Restorer{Host: "localhost", Port: 3306}
+	done := make(chan error, 1)
+	go func() {
+		done <- restorer.ReplayBinlogs(context.Background(), []string{"binlog.000001"}, ReplayOptions{})
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "Access denied") {
+			t.Errorf("ReplayBinlogs() error = %v, want the mysql error", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("ReplayBinlogs() hung after mysql exited")
+	}
+}
+
+func TestReplayBinlogsDatabaseFilter(t *testing.T) {
+	argsFile := filepath.Join(t.TempDir(), "args")
+	stubTools(t, map[string]string{
+		"mysqlbinlog": `echo "$@" > ` + argsFile,
+		"mysql":       `cat > /dev/null`,
+	})
+
+	tests := []struct {
+		source, target string
+		want           string
+	}{
+		{source: "", target: "", want: "binlog.000001"},
+		{source: "shop", target: "", want: "--database=shop binlog.000001"},
+		{source: "shop", target: "shop", want: "--database=shop binlog.000001"},
+		{source: "shop", target: "shop_restored", want: "--rewrite-db=shop->shop_restored --database=shop_restored binlog.000001"},
+	}
+
+	for _, tt := range tests {
+		restorer := &Restorer{Host: "localhost", Port: 3306, Database: tt.target}
+		options := ReplayOptions{SourceDatabase: tt.source}
+		if err := restorer.ReplayBinlogs(context.Background(), []string{"binlog.000001"}, options); err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(argsFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.TrimSpace(string(got)) != tt.want {
+			t.Errorf("%q into %q: mysqlbinlog %s, want %s", tt.source, tt.target, strings.TrimSpace(string(got)), tt.want)
+		}
+	}
+}
+
+func TestReplayBinlogsStopDatetime(t *testing.T) {
+	argsFile := filepath.Join(t.TempDir(), "args")
+	stubTools(t, map[string]string{
+		"mysqlbinlog": `echo "$@" > ` + argsFile,
+		"mysql":       `cat > /dev/null`,
+	})
+
+	target := time.Date(2026, 3, 1, 12, 30, 0, 0, time.FixedZone("", 5*3600))
+	restorer := &Restorer{Host: "localhost", Port: 3306}
+	if err := restorer.ReplayBinlogs(context.Background(), []string{"binlog.000001"}, ReplayOptions{StopDatetime: target}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "--stop-datetime=" + target.In(time.Local).Format("2006-01-02 15:04:05")
+	if !strings.Contains(string(got), want) {
+		t.Errorf("mysqlbinlog %s, want %s", strings.TrimSpace(string(got)), want)
+	}
+}
@@ -0,0 +1,108 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// checkVersions detects the mysqldump and server versions, fails early on combinations that
+// cannot produce a usable dump and turns off column statistics where the server lacks them.
+// Without a server connection the check is skipped and mysqldump reports the problem.
+func (d *Dumper) checkVersions(ctx context.Context) error {
+	clientVersion, err := backup.ToolVersion(ctx, backup.ToolPath(d.BinDir, "mysqldump"), "mysql-bin-dir")
+	if err != nil {
+		return err
+	}
+	d.clientVersion = clientVersion
+
+	serverVersion, err := d.queryServerVersion(ctx)
+	if err != nil {
+		logger.Warnf("⚠️  Could not detect the MySQL server version, skipping compatibility check: %v", err)
+		return nil
+	}
+	d.serverVersion = serverVersion
+	logger.Infof("🔍 %s, server %s", clientVersion, serverVersion)
+
+	if err := checkCompatibility(clientVersion, serverVersion); err != nil {
+		return err
+	}
+
+	client, clientMariaDB, ok := parseClientVersion(clientVersion)
+	if !ok {
+		return nil
+	}
+	server, ok := backup.ParseVersion(serverVersion)
+	if !ok {
+		return nil
+	}
+	serverMariaDB := strings.Contains(serverVersion, "MariaDB")
+
+	// mysqldump 8.0 queries information_schema.column_statistics, which older servers lack
+	if !clientMariaDB && client.AtLeast(8, 0) && (serverMariaDB || !server.AtLeast(8, 0)) && !d.SkipColumnStatistics {
+		logger.Infof("ℹ️  Server %s has no column statistics, passing --column-statistics=0", serverVersion)
+		d.SkipColumnStatistics = true
+	}
+
+	return nil
+}
+
+// checkCompatibility rejects mysqldump and server combinations that cannot produce a usable
+// dump. Versions that cannot be parsed are accepted.
+func checkCompatibility(clientVersion, serverVersion string) error {
+	client, clientMariaDB, ok := parseClientVersion(clientVersion)
+	if !ok {
+		return nil
+	}
+	server, ok := backup.ParseVersion(serverVersion)
+	if !ok {
+		return nil
+	}
+	serverMariaDB := strings.Contains(serverVersion, "MariaDB")
+
+	switch {
+	case clientMariaDB && !serverMariaDB && server.AtLeast(8, 0):
+		return fmt.Errorf("the MariaDB mysqldump %s cannot reliably dump MySQL %s: install the MySQL client and pass its directory with --mysql-bin-dir, or use --engine native", client, serverVersion)
+	case !clientMariaDB && !serverMariaDB && minorVersion(client).Less(minorVersion(server)):
+		return fmt.Errorf("mysqldump %s is older than the MySQL %s server: install a matching client and pass its directory with --mysql-bin-dir, or use --engine native", client, serverVersion)
+	}
+	return nil
+}
+
+// parseClientVersion reads the server release a mysqldump belongs to, e.g.
+// "mysqldump Ver 8.0.36 for Linux", "mysqldump Ver 10.19 Distrib 10.6.16-MariaDB, for
+// debian-linux-gnu" or "mysqldump from 11.4.2-MariaDB, client 10.19"
+func parseClientVersion(output string) (backup.Version, bool, bool) {
+	for _, marker := range []string{"Distrib ", "from ", "Ver "} {
+		if _, release, found := strings.Cut(output, marker); found {
+			output = release
+			break
+		}
+	}
+
+	version, ok := backup.ParseVersion(output)
+	return version, strings.Contains(output, "MariaDB"), ok
+}
+
+// minorVersion drops the patch level, which client and server may differ in
+func minorVersion(v backup.Version) backup.Version {
+	return backup.Version{Major: v.Major, Minor: v.Minor}
+}
+
+// queryServerVersion reads VERSION() over a driver connection
+func (d *Dumper) queryServerVersion(ctx context.Context) (string, error) {
+	db, err := d.openNative()
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+
+	var version string
+	if err := db.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+		return "", err
+	}
+	return version, nil
+}
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+)
+
+// unreadableRelationsQuery lists tables, sequences and materialized views whose data the
+// current user cannot read, which would make pg_dump fail with "permission denied"
+const unreadableRelationsQuery = `SELECT quote_ident(n.nspname) || '.' || quote_ident(c.relname)
+FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE c.relkind IN ('r', 'p', 'S', 'm')
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname NOT LIKE 'pg\_toast%' AND n.nspname NOT LIKE 'pg\_temp%'
+  AND NOT (has_schema_privilege(n.oid, 'USAGE') AND has_table_privilege(c.oid, 'SELECT'))
+ORDER BY 1`
+
+// Probe checks that pg_dump can dump the database, using psql for the server-side checks
+func (d *Dumper) Probe(ctx context.Context) []backup.Check {
+	pgDump := backup.ToolCheck(ctx, backup.ToolPath(d.BinDir, "pg_dump"), "pg-bin-dir")
+	psql := backup.ToolCheck(ctx, backup.ToolPath(d.BinDir, "psql"), "pg-bin-dir")
+	checks := []backup.Check{pgDump, psql}
+	if psql.Err != nil {
+		return checks
+	}
+
+	user, err := d.psql(ctx, "SELECT current_user")
+	if err != nil {
+		return append(checks, backup.Check{Name: "Authentication", Err: err})
+	}
+	checks = append(checks, backup.Check{Name: "Authentication", Detail: fmt.Sprintf("connected to %s as %s", d.Database, user)})
+
+	if serverVersion, err := d.queryServerVersion(ctx); err != nil {
+		checks = append(checks, backup.Check{Name: "Version compatibility", Err: err, Warning: true})
+	} else if pgDump.Err == nil {
+		check := backup.Check{Name: "Version compatibility", Detail: fmt.Sprintf("%s, server %s", pgDump.Detail, serverVersion)}
+		client, clientOK := backup.ParseVersion(pgDump.Detail)
+		server, serverOK := backup.ParseVersion(serverVersion)
+		if clientOK && serverOK {
+			check.Err = checkCompatibility(majorVersion(client), majorVersion(server))
+		}
+		checks = append(checks, check)
+	}
+
+	privileges := backup.Check{Name: "Privileges", Detail: "all tables and sequences are readable"}
+	if output, err := d.psql(ctx, unreadableRelationsQuery); err != nil {
+		privileges.Err = err
+	} else if output != "" {
+		relations := strings.Split(output, "\n")
+		privileges.Detail = ""
+		privileges.Err = fmt.Errorf("%s cannot read %d relations: %s", user, len(relations), summarize(relations, 5))
+	}
+	checks = append(checks, privileges)
+
+	return append(checks, backup.SizeCheck(d.EstimateSize(ctx)))
+}
+
+// EstimateSize returns the on-disk size of the database
+func (d *Dumper) EstimateSize(ctx context.Context) (int64, error) {
+	output, err := d.psql(ctx, "SELECT pg_database_size(current_database())")
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(output, 10, 64)
+}
+
+// summarize joins the first few names of a list, noting how many were left out
+func summarize(names []string, limit int) string {
+	if len(names) <= limit {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:limit], ", "), len(names)-limit)
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
 )
 
 type Dumper struct {
@@ -17,30 +20,27 @@ type Dumper struct {
 	Database string
 	Username string
 	Password string
-}
+	TLS      backup.TLSConfig
 
-func (d *Dumper) CreateBackupStream(ctx context.Context) (io.ReadCloser, error) {
-	args := []string{
-		"-h", d.Host,
-		"-p", fmt.Sprintf("%d", d.Port),
-		"--format=custom",
-		"--compress=6",
-		"--no-password",
-	}
+	// Options are extra libpq connection parameters (e.g. from a connection URL)
+	Options map[string]string
 
-	if d.Username != "" {
-		args = append(args, "-U", d.Username)
-	}
+	// BinDir is the directory holding pg_dump and psql, to pick a specific PostgreSQL
+	// version (default: look them up in PATH)
+	BinDir string
 
-	if d.Database != "" {
-		args = append(args, d.Database)
-	}
+	clientVersion string
+	serverVersion string
+}
 
-	cmd := exec.CommandContext(ctx, "pg_dump", args...)
+func (d *Dumper) CreateBackupStream(ctx context.Context) (io.ReadCloser, error) {
+	if err := d.checkVersions(ctx); err != nil {
+		return nil, err
+	}
 
-	// Set password via environment variable if provided
-	if d.Password != "" {
-		cmd.Env = append(os.Environ(), fmt.Sprintf("PGPASSWORD=%s", d.Password))
+	cmd, err := d.dumpCommand(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create pipes for both stdout and stderr
@@ -56,7 +56,7 @@ func (d *Dumper) CreateBackupStream(ctx context.Context) (io.ReadCloser, error)
 	}
 
 	// Start the command
-	if err := cmd.Start(); err != nil {
+	if err := backup.StartCommand(cmd); err != nil {
 		stdout.Close()
 		stderr.Close()
 		return nil, fmt.Errorf("failed to start pg_dump: %w", err)
@@ -127,28 +127,133 @@ func (cr *cmdReader) Close() error {
 
 	if err := cr.cmd.Wait(); err != nil {
 		if len(remainingStderr) > 0 {
-			// Check if we should log debug output
 			stderrStr := string(remainingStderr)
-			if strings.Contains(os.Getenv("LOG_LEVEL"), "debug") ||
-				strings.Contains(os.Getenv("LOG_LEVEL"), "DEBUG") {
-				log.Printf("DEBUG: pg_dump stderr: %s", stderrStr)
-			}
+			logger.Debugf("pg_dump stderr: %s", stderrStr)
 			return fmt.Errorf("pg_dump failed: %w\nOutput: %s", err, stderrStr)
 		}
 		return fmt.Errorf("pg_dump failed: %w", err)
 	}
 
-	// Also log any warnings/messages even on success if debug is enabled
+	// Also log any warnings/messages even on success at debug level
 	if len(remainingStderr) > 0 {
-		if strings.Contains(os.Getenv("LOG_LEVEL"), "debug") ||
-			strings.Contains(os.Getenv("LOG_LEVEL"), "DEBUG") {
-			log.Printf("DEBUG: pg_dump stderr: %s", string(remainingStderr))
-		}
+		logger.Debugf("pg_dump stderr: %s", string(remainingStderr))
 	}
 
 	return nil
 }
 
+// dumpCommand builds the pg_dump command
+func (d *Dumper) dumpCommand(ctx context.Context) (*exec.Cmd, error) {
+	args := []string{
+		"--format=custom",
+		"--compress=6",
+	}
+	args = append(args, d.connectionArgs()...)
+
+	env, err := d.environment()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, backup.ToolPath(d.BinDir, "pg_dump"), args...)
+	cmd.Env = env
+	return cmd, nil
+}
+
+// DescribeCommand returns the pg_dump command line with the password redacted
+func (d *Dumper) DescribeCommand() ([]string, error) {
+	cmd, err := d.dumpCommand(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return []string{backup.FormatCommand(cmd)}, nil
+}
+
+// connectionArgs returns the connection arguments shared by pg_dump and psql, ending with the database
+func (d *Dumper) connectionArgs() []string {
+	args := []string{
+		"-h", d.Host,
+		"-p", fmt.Sprintf("%d", d.Port),
+		"--no-password",
+	}
+
+	if d.Username != "" {
+		args = append(args, "-U", d.Username)
+	}
+
+	if len(d.Options) > 0 {
+		args = append(args, "--dbname="+conninfo(d.Database, d.Options))
+	} else if d.Database != "" {
+		args = append(args, d.Database)
+	}
+
+	return args
+}
+
+// environment returns the process environment with TLS settings and the password
+func (d *Dumper) environment() ([]string, error) {
+	tlsEnv, err := TLSEnv(d.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	env := append(os.Environ(), tlsEnv...)
+
+	// Set password via environment variable if provided
+	if d.Password != "" {
+		env = append(env, fmt.Sprintf("PGPASSWORD=%s", d.Password))
+	}
+
+	return env, nil
+}
+
+// conninfo builds a libpq keyword/value connection string
+func conninfo(database string, options map[string]string) string {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	quote := func(value string) string {
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+	}
+
+	var parts []string
+	if database != "" {
+		parts = append(parts, "dbname="+quote(database))
+	}
+	for _, key := range keys {
+		parts = append(parts, key+"="+quote(options[key]))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// TLSEnv maps TLS options to libpq environment variables
+func TLSEnv(tls backup.TLSConfig) ([]string, error) {
+	mode, err := tls.NormalizedMode()
+	if err != nil {
+		return nil, err
+	}
+
+	var env []string
+	if mode != "" {
+		env = append(env, "PGSSLMODE="+mode)
+	}
+	if tls.CAFile != "" {
+		env = append(env, "PGSSLROOTCERT="+tls.CAFile)
+	}
+	if tls.CertFile != "" {
+		env = append(env, "PGSSLCERT="+tls.CertFile)
+	}
+	if tls.KeyFile != "" {
+		env = append(env, "PGSSLKEY="+tls.KeyFile)
+	}
+
+	return env, nil
+}
+
 // GetFileExtension returns the file extension for PostgreSQL dumps
 func (d *Dumper) GetFileExtension() string {
 	return ".dump"
@@ -163,3 +268,13 @@ func (d *Dumper) GetDatabaseType() string {
 func (d *Dumper) GetDatabaseName() string {
 	return d.Database
 }
+
+// GetDatabaseHost returns the database host for object key templates
+func (d *Dumper) GetDatabaseHost() string {
+	return d.Host
+}
+
+// GetBackupMetadata returns the pg_dump and server versions
+func (d *Dumper) GetBackupMetadata() map[string]string {
+	return backup.VersionMetadata(d.clientVersion, d.serverVersion)
+}
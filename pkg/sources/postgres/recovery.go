@@ -0,0 +1,260 @@
+package postgres
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+)
+
+// Point-in-time recovery expects the following layout under the storage path prefix:
+//
+//	<prefix>/basebackups/<name>_<YYYYMMDD_HHMMSS>.tar[.gz]  (pg_basebackup -Ft output)
+//	<prefix>/wal/<segment>                                  (archived WAL files)
+//
+// The time a base backup was taken comes from its manifest, so any key template works. Base
+// backups without a manifest predate manifests and were named in local time.
+const (
+	BaseBackupDir = "basebackups"
+	WALDir        = "wal"
+)
+
+var backupTimestampPattern = regexp.MustCompile(`_(\d{8}_\d{6})\.`)
+
+// BaseBackup describes a base backup found in storage
+type BaseBackup struct {
+	Key       string
+	Timestamp time.Time
+}
+
+// RecoveryConfig holds the settings written into the restored data directory
+type RecoveryConfig struct {
+	RestoreCommand string
+	TargetTime     time.Time
+	TargetAction   string
+}
+
+// BaseBackupPrefix returns the storage prefix holding base backups
+func BaseBackupPrefix(pathPrefix string) string {
+	return backup.JoinKey(pathPrefix, BaseBackupDir) + "/"
+}
+
+// WALKey returns the storage key of an archived WAL file
+func WALKey(pathPrefix, walFile string) string {
+	return backup.JoinKey(pathPrefix, WALDir, walFile)
+}
+
+// SelectBaseBackup picks the most recent base backup taken at or before the target time.
+// The key timestamps of base backups without a manifest are read in legacyLocation.
+func SelectBaseBackup(ctx context.Context, downloader backup.StorageDownloader, keys []string, target time.Time, legacyLocation *time.Location) (*BaseBackup, error) {
+	hasManifest := make(map[string]bool)
+	for _, key := range keys {
+		hasManifest[key] = true
+	}
+
+	var candidates []BaseBackup
+	for _, key := range keys {
+		if !isBaseBackupArchive(key) {
+			continue
+		}
+
+		var timestamp time.Time
+		if hasManifest[backup.ManifestKey(key)] {
+			manifest, err := backup.ReadManifest(ctx, downloader, key)
+			if err != nil {
+				return nil, err
+			}
+			timestamp = manifest.CreatedAt
+		} else {
+			match := backupTimestampPattern.FindStringSubmatch(path.Base(key))
+			if match == nil {
+				continue
+			}
+			var err error
+			if timestamp, err = time.ParseInLocation("20060102_150405", match[1], legacyLocation); err != nil {
+				continue
+			}
+		}
+
+		if !timestamp.After(target) {
+			candidates = append(candidates, BaseBackup{Key: key, Timestamp: timestamp})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no base backup found at or before %s", target.Format(time.RFC3339))
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Timestamp.After(candidates[j].Timestamp)
+	})
+
+	return &candidates[0], nil
+}
+
+// PrepareDataDirectory makes sure the data directory exists, is empty and has the
+// permissions PostgreSQL requires
+func PrepareDataDirectory(dataDir string) error {
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return fmt.Errorf("failed to create data directory %s: %w", dataDir, err)
+	}
+
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		return fmt.Errorf("failed to read data directory %s: %w", dataDir, err)
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("data directory %s is not empty", dataDir)
+	}
+
+	return os.Chmod(dataDir, 0700)
+}
+
+// ExtractBaseBackup unpacks a tar (optionally gzip-compressed) base backup into the data directory
+func ExtractBaseBackup(reader io.Reader, key, dataDir string) error {
+	if strings.HasSuffix(key, ".gz") {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return fmt.Errorf("failed to open gzip stream: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read base backup archive: %w", err)
+		}
+
+		target := filepath.Join(dataDir, filepath.FromSlash(header.Name))
+		if target != filepath.Clean(dataDir) && !strings.HasPrefix(target, filepath.Clean(dataDir)+string(os.PathSeparator)) {
+			return fmt.Errorf("base backup entry %q escapes the data directory", header.Name)
+		}
+		// Symlinks such as pg_tblspc entries may point anywhere, so nothing is written through them
+		if err := checkNoSymlink(dataDir, target); err != nil {
+			return fmt.Errorf("base backup entry %q: %w", header.Name, err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0700); err != nil {
+				return fmt.Errorf("failed to create %s: %w", target, err)
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+				return fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+			}
+			if err := writeFile(target, tr, os.FileMode(header.Mode).Perm()&0700); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+				return fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return fmt.Errorf("failed to create symlink %s: %w", target, err)
+			}
+		}
+	}
+}
+
+// WriteRecoveryConfig writes recovery.signal and the recovery settings into postgresql.auto.conf
+func WriteRecoveryConfig(dataDir string, config RecoveryConfig) error {
+	signalPath := filepath.Join(dataDir, "recovery.signal")
+	if err := os.WriteFile(signalPath, nil, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", signalPath, err)
+	}
+
+	action := config.TargetAction
+	if action == "" {
+		action = "promote"
+	}
+
+	var settings strings.Builder
+	settings.WriteString("\n# Added by dbbackup for point-in-time recovery\n")
+	fmt.Fprintf(&settings, "restore_command = %s\n", quoteSetting(config.RestoreCommand))
+	if !config.TargetTime.IsZero() {
+		fmt.Fprintf(&settings, "recovery_target_time = %s\n", quoteSetting(config.TargetTime.Format("2006-01-02 15:04:05-07:00")))
+	}
+	fmt.Fprintf(&settings, "recovery_target_action = %s\n", quoteSetting(action))
+
+	confPath := filepath.Join(dataDir, "postgresql.auto.conf")
+	file, err := os.OpenFile(confPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", confPath, err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(settings.String()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", confPath, err)
+	}
+
+	return nil
+}
+
+func isBaseBackupArchive(key string) bool {
+	return strings.HasSuffix(key, ".tar") || strings.HasSuffix(key, ".tar.gz")
+}
+
+// quoteSetting quotes a value for postgresql.conf
+func quoteSetting(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// checkNoSymlink fails if target or a directory between dataDir and target is a symlink
+func checkNoSymlink(dataDir, target string) error {
+	relative, err := filepath.Rel(dataDir, target)
+	if err != nil || relative == "." {
+		return err
+	}
+
+	current := dataDir
+	for _, part := range strings.Split(relative, string(os.PathSeparator)) {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("refusing to write through the symlink %s", current)
+		}
+	}
+	return nil
+}
+
+func writeFile(target string, reader io.Reader, mode os.FileMode) error {
+	if mode == 0 {
+		mode = 0600
+	}
+
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", target, err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, reader); err != nil {
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package postgres
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+)
+
+type tarEntry struct {
+	name     string
+	typeflag byte
+	linkname string
+	body     string
+}
+
+func buildTar(t *testing.T, entries []tarEntry) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Typeflag: entry.typeflag, Linkname: entry.linkname, Mode: 0600, Size: int64(len(entry.body))}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(entry.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestExtractBaseBackup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on Windows")
+	}
+	dataDir := t.TempDir()
+	tablespace := t.TempDir()
+
+	archive := buildTar(t, []tarEntry{
+		{name: "PG_VERSION", typeflag: tar.TypeReg, body: "16\n"},
+		{name: "global/", typeflag: tar.TypeDir},
+		{name: "global/pg_control", typeflag: tar.TypeReg, body: "control"},
+		{name: "pg_tblspc/16384", typeflag: tar.TypeSymlink, linkname: tablespace},
+	})
+	if err := ExtractBaseBackup(archive, "base.tar", dataDir); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dataDir, "global", "pg_control")); err != nil || string(data) != "control" {
+		t.Errorf("global/pg_control = %q, %v", data, err)
+	}
+	if link, err := os.Readlink(filepath.Join(dataDir, "pg_tblspc", "16384")); err != nil || link != tablespace {
+		t.Errorf("tablespace link = %q, %v, want %q", link, err, tablespace)
+	}
+}
+
+func TestExtractBaseBackupEscapes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on Windows")
+	}
+
+	tests := []struct {
+		name    string
+		entries []tarEntry
+	}{
+		{name: "dot-dot path", entries: []tarEntry{
+			{name: "../outside/file", typeflag: tar.TypeReg, body: "x"},
+		}},
+		{name: "file through symlink", entries: []tarEntry{
+			{name: "link", typeflag: tar.TypeSymlink, linkname: "OUTSIDE"},
+			{name: "link/file", typeflag: tar.TypeReg, body: "x"},
+		}},
+		{name: "directory through relative symlink", entries: []tarEntry{
+			{name: "link", typeflag: tar.TypeSymlink, linkname: "../outside"},
+			{name: "link/dir/", typeflag: tar.TypeDir},
+		}},
+		{name: "file over symlink", entries: []tarEntry{
+			{name: "link", typeflag: tar.TypeSymlink, linkname: "OUTSIDE/file"},
+			{name: "link", typeflag: tar.TypeReg, body: "x"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			dataDir := filepath.Join(root, "data")
+			outside := filepath.Join(root, "outside")
+			for _, dir := range []string{dataDir, outside} {
+				if err := os.Mkdir(dir, 0700); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for i := range tt.entries {
+				tt.entries[i].linkname = strings.ReplaceAll(tt.entries[i].linkname, "OUTSIDE", outside)
+			}
+
+			if err := ExtractBaseBackup(buildTar(t, tt.entries), "base.tar", dataDir); err == nil {
+				t.Error("ExtractBaseBackup() succeeded, want an error")
+			}
+			if entries, _ := os.ReadDir(outside); len(entries) > 0 {
+				t.Errorf("an entry was written outside the data directory: %s", entries[0].Name())
+			}
+		})
+	}
+}
+
+// manifestDownloader serves the manifests of base backups
+type manifestDownloader map[string]time.Time
+
+func (d manifestDownloader) Download(ctx context.Context, key string, writer io.Writer) error {
+	createdAt, ok := d[strings.TrimSuffix(key, backup.ManifestSuffix)]
+	if !ok {
+		return os.ErrNotExist
+	}
+	return json.NewEncoder(writer).Encode(backup.Manifest{CreatedAt: createdAt})
+}
+
+func (d manifestDownloader) List(ctx context.Context, prefix string) ([]string, error) {
+	return nil, nil
+}
+
+func (d manifestDownloader) GetStorageType() string { return "manifests" }
+
+func TestSelectBaseBackup(t *testing.T) {
+	// Legacy keys were named in a local time zone five hours behind UTC
+	legacyLocation := time.FixedZone("UTC-5", -5*3600)
+	downloader := manifestDownloader{
+		"basebackups/custom-name-1.tar.gz": time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC),
+		"basebackups/custom-name-2.tar.gz": time.Date(2026, 10, 1, 11, 0, 0, 0, time.UTC),
+		// The key says 08:00, but the manifest records when the backup was taken
+		"basebackups/db_20261001_080000.tar": time.Date(2026, 10, 1, 13, 0, 0, 0, time.UTC),
+	}
+	keys := []string{
+		"basebackups/custom-name-1.tar.gz", "basebackups/custom-name-1.tar.gz" + backup.ManifestSuffix,
+		"basebackups/custom-name-2.tar.gz", "basebackups/custom-name-2.tar.gz" + backup.ManifestSuffix,
+		"basebackups/db_20261001_080000.tar", "basebackups/db_20261001_080000.tar" + backup.ManifestSuffix,
+		// Without a manifest: 07:30 local time is 12:30 UTC
+		"basebackups/db_20261001_073000.tar",
+		"basebackups/notes.txt",
+	}
+
+	tests := []struct {
+		target  time.Time
+		want    string
+		wantErr bool
+	}{
+		{target: time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC), wantErr: true},
+		{target: time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC), want: "basebackups/custom-name-1.tar.gz"},
+		{target: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC), want: "basebackups/custom-name-2.tar.gz"},
+		{target: time.Date(2026, 10, 1, 12, 45, 0, 0, time.UTC), want: "basebackups/db_20261001_073000.tar"},
+		{target: time.Date(2026, 10, 1, 14, 0, 0, 0, time.UTC), want: "basebackups/db_20261001_080000.tar"},
+	}
+
+	for _, tt := range tests {
+		got, err := SelectBaseBackup(context.Background(), downloader, keys, tt.target, legacyLocation)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("SelectBaseBackup(%s) = %s, want an error", tt.target.Format(time.Kitchen), got.Key)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("SelectBaseBackup(%s) error = %v", tt.target.Format(time.Kitchen), err)
+			continue
+		}
+		if got.Key != tt.want {
+			t.Errorf("SelectBaseBackup(%s) = %s, want %s", tt.target.Format(time.Kitchen), got.Key, tt.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// checkVersions detects the pg_dump and server versions and fails early when this pg_dump
+// cannot dump the server. The server version is read with psql; without it the check is skipped.
+func (d *Dumper) checkVersions(ctx context.Context) error {
+	clientVersion, err := backup.ToolVersion(ctx, backup.ToolPath(d.BinDir, "pg_dump"), "pg-bin-dir")
+	if err != nil {
+		return err
+	}
+	d.clientVersion = clientVersion
+
+	serverVersion, err := d.queryServerVersion(ctx)
+	if err != nil {
+		logger.Warnf("⚠️  Could not detect the PostgreSQL server version, skipping compatibility check: %v", err)
+		return nil
+	}
+	d.serverVersion = serverVersion
+	logger.Infof("🔍 %s, server %s", clientVersion, serverVersion)
+
+	client, ok := backup.ParseVersion(clientVersion)
+	if !ok {
+		return nil
+	}
+	server, ok := backup.ParseVersion(serverVersion)
+	if !ok {
+		return nil
+	}
+
+	return checkCompatibility(majorVersion(client), majorVersion(server))
+}
+
+// checkCompatibility applies pg_dump's rules: it refuses servers of a newer major version,
+// and since PostgreSQL 15 servers older than 9.2
+func checkCompatibility(client, server backup.Version) error {
+	if client.Less(server) {
+		return fmt.Errorf("pg_dump %s cannot dump a PostgreSQL %s server: install the PostgreSQL %s client tools and pass their directory with --pg-bin-dir (e.g. /usr/lib/postgresql/%s/bin)",
+			formatMajor(client), formatMajor(server), formatMajor(server), formatMajor(server))
+	}
+	if client.AtLeast(15, 0) && server.Less(backup.Version{Major: 9, Minor: 2}) {
+		return fmt.Errorf("pg_dump %s cannot dump a PostgreSQL %s server: use pg_dump 14 or older via --pg-bin-dir", formatMajor(client), formatMajor(server))
+	}
+	return nil
+}
+
+// majorVersion reduces a version to its major release: 16.2 → 16, but 9.6.24 → 9.6
+func majorVersion(v backup.Version) backup.Version {
+	if v.Major >= 10 {
+		return backup.Version{Major: v.Major}
+	}
+	return backup.Version{Major: v.Major, Minor: v.Minor}
+}
+
+func formatMajor(v backup.Version) string {
+	if v.Major >= 10 {
+		return fmt.Sprintf("%d", v.Major)
+	}
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// queryServerVersion reads server_version with psql, e.g. "16.2"
+func (d *Dumper) queryServerVersion(ctx context.Context) (string, error) {
+	output, err := d.psql(ctx, "SHOW server_version")
+	if err != nil {
+		return "", err
+	}
+
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("psql returned no server version")
+	}
+	return fields[0], nil
+}
+
+// psql runs a query with psql and returns its unaligned, tuples-only output
+func (d *Dumper) psql(ctx context.Context, query string) (string, error) {
+	env, err := d.environment()
+	if err != nil {
+		return "", err
+	}
+
+	args := append([]string{"-X", "-A", "-t", "-c", query}, d.connectionArgs()...)
+	cmd := exec.CommandContext(ctx, backup.ToolPath(d.BinDir, "psql"), args...)
+	cmd.Env = env
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("psql failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,152 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+)
+
+// Probe checks that the nodes the backup would dump can be reached and authenticated to,
+// that the user may run the replication command the dump uses, and how much memory they use
+func (d *Dumper) Probe(ctx context.Context) []backup.Check {
+	var checks []backup.Check
+	if err := d.validate(); err != nil {
+		return append(checks, backup.Check{Name: "Options", Err: err})
+	}
+
+	if d.engine() == backup.EngineCLI {
+		err := d.checkClientVersion(ctx)
+		checks = append(checks, backup.Check{Name: "Client tool redis-cli", Detail: d.clientVersion, Err: err})
+		if d.clientVersion == "" {
+			return checks
+		}
+	}
+
+	var nodes []ClusterNode
+	switch {
+	case d.Cluster:
+		masters, _, err := d.discoverClusterMasters(ctx)
+		if err != nil {
+			return append(checks, backup.Check{Name: "Cluster discovery", Err: err})
+		}
+		nodes = masters
+		checks = append(checks, backup.Check{Name: "Cluster discovery", Detail: fmt.Sprintf("%d masters", len(masters))})
+	case len(d.Sentinels) > 0:
+		host, port, err := d.discoverMaster(ctx)
+		if err != nil {
+			return append(checks, backup.Check{Name: "Sentinel discovery", Err: err})
+		}
+		nodes = []ClusterNode{{Host: host, Port: port}}
+		checks = append(checks, backup.Check{Name: "Sentinel discovery", Detail: fmt.Sprintf("master %q at %s", d.SentinelMaster, nodes[0].Address())})
+	default:
+		nodes = []ClusterNode{{Host: d.Host, Port: d.Port}}
+	}
+
+	var total int64
+	var sizeErr error
+	for _, node := range nodes {
+		auth := backup.Check{Name: "Authentication " + node.Address()}
+		output, err := d.query(ctx, node.Host, node.Port, d.Username, d.Password, "PING")
+		if err == nil && strings.TrimSpace(output) != "PONG" {
+			err = fmt.Errorf("unexpected PING reply %q", strings.TrimSpace(output))
+		}
+		auth.Err = err
+		checks = append(checks, auth)
+		if err != nil {
+			sizeErr = fmt.Errorf("%s is not reachable", node.Address())
+			continue
+		}
+
+		if d.Username != "" {
+			checks = append(checks, d.checkReplicationPermission(ctx, node))
+		}
+
+		used, err := d.usedMemory(ctx, node)
+		if err != nil {
+			sizeErr = err
+		}
+		total += used
+	}
+
+	return append(checks, backup.SizeCheck(total, sizeErr))
+}
+
+// checkReplicationPermission asks the server whether the ACL user may start a replication
+// stream, with ACL DRYRUN (Redis 7.0+). Without permission to run ACL itself it is a warning.
+func (d *Dumper) checkReplicationPermission(ctx context.Context, node ClusterNode) backup.Check {
+	command := "SYNC"
+	if d.engine() == backup.EngineNative {
+		command = "PSYNC"
+	}
+	check := backup.Check{Name: "Privileges " + node.Address(), Detail: d.Username + " may run " + command}
+
+	args := []string{"ACL", "DRYRUN", d.Username, command}
+	if command == "PSYNC" {
+		args = append(args, "?", "-1")
+	}
+	output, err := d.query(ctx, node.Host, node.Port, d.Username, d.Password, args...)
+	output = strings.TrimSpace(output)
+
+	switch {
+	case err == nil && output == "OK":
+	case err != nil || strings.HasPrefix(output, "ERR") || strings.HasPrefix(output, "NOPERM") || strings.HasPrefix(output, "(error)"):
+		if err == nil {
+			err = fmt.Errorf("%s", output)
+		}
+		check.Detail = ""
+		check.Err = fmt.Errorf("could not verify %s permission: %w", command, err)
+		check.Warning = true
+	default:
+		check.Detail = ""
+		check.Err = fmt.Errorf("%s", output)
+	}
+	return check
+}
+
+// EstimateSize returns the memory used by the nodes the backup dumps
+func (d *Dumper) EstimateSize(ctx context.Context) (int64, error) {
+	nodes := []ClusterNode{{Host: d.Host, Port: d.Port}}
+	switch {
+	case d.Cluster:
+		masters, _, err := d.discoverClusterMasters(ctx)
+		if err != nil {
+			return 0, err
+		}
+		nodes = masters
+	case len(d.Sentinels) > 0:
+		host, port, err := d.discoverMaster(ctx)
+		if err != nil {
+			return 0, err
+		}
+		nodes = []ClusterNode{{Host: host, Port: port}}
+	}
+
+	var total int64
+	for _, node := range nodes {
+		used, err := d.usedMemory(ctx, node)
+		if err != nil {
+			return 0, err
+		}
+		total += used
+	}
+	return total, nil
+}
+
+// usedMemory reads used_memory from INFO memory, an upper bound for the RDB size
+func (d *Dumper) usedMemory(ctx context.Context, node ClusterNode) (int64, error) {
+	output, err := d.query(ctx, node.Host, node.Port, d.Username, d.Password, "INFO", "memory")
+	if err != nil {
+		return 0, err
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		if value, found := strings.CutPrefix(strings.TrimSpace(line), "used_memory:"); found {
+			return strconv.ParseInt(value, 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("INFO memory of %s has no used_memory", net.JoinHostPort(node.Host, strconv.Itoa(node.Port)))
+}
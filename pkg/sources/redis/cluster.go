@@ -0,0 +1,165 @@
+package redis
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// ClusterNodesFile is the tar entry holding the CLUSTER NODES output at dump time
+const ClusterNodesFile = "nodes.txt"
+
+// createClusterStream dumps every master shard to a temporary RDB file and streams them
+// back as a tar archive with one <host>_<port>.rdb entry per shard
+func (d *Dumper) createClusterStream(ctx context.Context) (io.ReadCloser, error) {
+	masters, nodesOutput, err := d.discoverClusterMasters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mode = "cluster"
+	d.sources = nil
+	for _, master := range masters {
+		d.sources = append(d.sources, master.Address())
+	}
+	logger.Infof("🔎 Found %d Redis Cluster master shard(s): %s", len(masters), strings.Join(d.sources, ", "))
+	d.detectServerVersion(ctx, masters[0].Host, masters[0].Port)
+
+	workDir, err := os.MkdirTemp("", "dbbackup-redis-cluster-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+
+	pr, pw := io.Pipe()
+	reader := &clusterReader{
+		reader:  pr,
+		done:    make(chan error, 1),
+		workDir: workDir,
+	}
+
+	go func() {
+		err := d.writeClusterArchive(ctx, pw, masters, nodesOutput, workDir)
+		pw.CloseWithError(err)
+		reader.done <- err
+	}()
+
+	return reader, nil
+}
+
+func (d *Dumper) writeClusterArchive(ctx context.Context, w io.Writer, masters []ClusterNode, nodesOutput, workDir string) error {
+	archive := tar.NewWriter(w)
+
+	if err := archive.WriteHeader(&tar.Header{
+		Name:    ClusterNodesFile,
+		Mode:    0644,
+		Size:    int64(len(nodesOutput)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(archive, nodesOutput); err != nil {
+		return err
+	}
+
+	for _, master := range masters {
+		name := fmt.Sprintf("%s_%d.rdb", master.Host, master.Port)
+		path := filepath.Join(workDir, name)
+
+		logger.Infof("📦 Dumping shard %s (slots %s)", master.Address(), strings.Join(master.Slots, " "))
+		if err := d.dumpShard(ctx, master, path); err != nil {
+			return fmt.Errorf("failed to dump shard %s: %w", master.Address(), err)
+		}
+
+		if err := addShardToArchive(archive, path, name, master.Address(), &d.rdbVersion); err != nil {
+			return err
+		}
+
+		// Shards can be large, so each one is removed as soon as it is archived
+		os.Remove(path)
+	}
+
+	return archive.Close()
+}
+
+// dumpShard saves the RDB of a master shard to path
+func (d *Dumper) dumpShard(ctx context.Context, master ClusterNode, path string) error {
+	if d.engine() != backup.EngineNative {
+		_, err := d.runCLI(ctx, master.Host, master.Port, d.Username, d.Password, "--rdb", path)
+		return err
+	}
+
+	reader, err := d.syncRDB(ctx, master.Host, master.Port)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, reader); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func addShardToArchive(archive *tar.Writer, path, name, source string, version *string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open shard dump: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat shard dump: %w", err)
+	}
+
+	if err := archive.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}); err != nil {
+		return err
+	}
+
+	// Verify the shard's RDB while copying it into the archive
+	if _, err := io.Copy(archive, newRDBValidator(file, source, version)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// clusterReader streams the cluster archive and waits for the dump goroutine when closed
+type clusterReader struct {
+	reader  *io.PipeReader
+	done    chan error
+	workDir string
+}
+
+func (cr *clusterReader) Read(p []byte) (int, error) {
+	return cr.reader.Read(p)
+}
+
+func (cr *clusterReader) Close() error {
+	cr.reader.Close()
+	defer os.RemoveAll(cr.workDir)
+
+	if err := <-cr.done; err != nil && err != io.ErrClosedPipe {
+		return fmt.Errorf("redis cluster dump failed: %w", err)
+	}
+
+	return nil
+}
@@ -4,31 +4,91 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
+	"net"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
 )
 
 type Dumper struct {
 	Host     string
 	Port     int
+	Username string
 	Password string
+	TLS      backup.TLSConfig
+
+	// DBIndex is the logical database the application uses. An RDB always contains every
+	// database, so it is only recorded in the backup manifest.
+	DBIndex int
+
+	// Sentinels, when set, are asked for the current address of SentinelMaster instead of
+	// dumping Host and Port directly
+	Sentinels        []string
+	SentinelMaster   string
+	SentinelPassword string
+
+	// Cluster dumps every master shard of the Redis Cluster that Host belongs to
+	Cluster bool
+
+	// Engine selects redis-cli (backup.EngineCLI, the default) or the built-in client, which
+	// receives the RDB through the replication protocol (backup.EngineNative)
+	Engine string
+
+	// BinDir is the directory holding redis-cli, to pick a specific version
+	// (default: look it up in PATH)
+	BinDir string
+
+	// Details of the dumped instance, recorded in the backup manifest
+	mode          string
+	sources       []string
+	rdbVersion    string
+	clientVersion string
+	serverVersion string
 }
 
 func (d *Dumper) CreateBackupStream(ctx context.Context) (io.ReadCloser, error) {
-	args := []string{
-		"-h", d.Host,
-		"-p", fmt.Sprintf("%d", d.Port),
-		"--rdb", "-",
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+	if err := d.checkClientVersion(ctx); err != nil {
+		return nil, err
+	}
+
+	if d.Cluster {
+		return d.createClusterStream(ctx)
+	}
+
+	host, port := d.Host, d.Port
+	d.mode = "standalone"
+	if len(d.Sentinels) > 0 {
+		var err error
+		host, port, err = d.discoverMaster(ctx)
+		if err != nil {
+			return nil, err
+		}
+		d.mode = "sentinel"
+		logger.Infof("🔎 Sentinel reports master %q at %s:%d", d.SentinelMaster, host, port)
 	}
+	d.sources = []string{net.JoinHostPort(host, strconv.Itoa(port))}
+	d.detectServerVersion(ctx, host, port)
 
-	if d.Password != "" {
-		args = append(args, "-a", d.Password)
+	if d.engine() == backup.EngineNative {
+		reader, err := d.syncRDB(ctx, host, port)
+		if err != nil {
+			return nil, err
+		}
+		return newRDBValidator(reader, d.sources[0], &d.rdbVersion), nil
 	}
 
-	cmd := exec.CommandContext(ctx, "redis-cli", args...)
+	cmd, err := d.command(ctx, host, port, d.Username, d.Password, "--rdb", "-")
+	if err != nil {
+		return nil, err
+	}
 
 	// Create pipes for both stdout and stderr
 	stdout, err := cmd.StdoutPipe()
@@ -43,20 +103,109 @@ func (d *Dumper) CreateBackupStream(ctx context.Context) (io.ReadCloser, error)
 	}
 
 	// Start the command
-	if err := cmd.Start(); err != nil {
+	if err := backup.StartCommand(cmd); err != nil {
 		stdout.Close()
 		stderr.Close()
 		return nil, fmt.Errorf("failed to start redis-cli: %w", err)
 	}
 
-	// Return a wrapper that will wait for the command to finish when closed
-	return &cmdReader{
+	// Return a wrapper that will wait for the command to finish when closed,
+	// verifying the RDB magic and checksum on the way through
+	return newRDBValidator(&cmdReader{
 		reader:    stdout,
 		stderr:    stderr,
 		cmd:       cmd,
 		validated: false,
 		firstRead: false,
-	}, nil
+	}, d.sources[0], &d.rdbVersion), nil
+}
+
+func (d *Dumper) validate() error {
+	if engine := d.engine(); engine != backup.EngineCLI && engine != backup.EngineNative {
+		return fmt.Errorf("unsupported engine %q (expected %s or %s)", d.Engine, backup.EngineCLI, backup.EngineNative)
+	}
+	if d.DBIndex < 0 {
+		return fmt.Errorf("invalid Redis database index %d", d.DBIndex)
+	}
+	if d.Cluster && len(d.Sentinels) > 0 {
+		return fmt.Errorf("Redis Cluster and Sentinel discovery cannot be combined")
+	}
+	if d.Cluster && d.DBIndex != 0 {
+		return fmt.Errorf("Redis Cluster only supports database 0")
+	}
+	if len(d.Sentinels) > 0 && d.SentinelMaster == "" {
+		return fmt.Errorf("a Sentinel master name is required when Sentinel addresses are given")
+	}
+	if d.SentinelMaster != "" && len(d.Sentinels) == 0 {
+		return fmt.Errorf("at least one Sentinel address is required with a Sentinel master name")
+	}
+	return nil
+}
+
+func (d *Dumper) engine() string {
+	if d.Engine == "" {
+		return backup.EngineCLI
+	}
+	return d.Engine
+}
+
+// query runs a command against a node with redis-cli or the native client
+func (d *Dumper) query(ctx context.Context, host string, port int, username, password string, args ...string) (string, error) {
+	if d.engine() == backup.EngineNative {
+		return d.runNative(ctx, host, port, username, password, args...)
+	}
+	return d.runCLI(ctx, host, port, username, password, args...)
+}
+
+// command builds a redis-cli invocation for a node, with credentials passed via environment
+func (d *Dumper) command(ctx context.Context, host string, port int, username, password string, args ...string) (*exec.Cmd, error) {
+	cliArgs := []string{
+		"-h", host,
+		"-p", strconv.Itoa(port),
+	}
+
+	if username != "" {
+		cliArgs = append(cliArgs, "--user", username)
+	}
+
+	sslArgs, err := tlsArgs(d.TLS)
+	if err != nil {
+		return nil, err
+	}
+	cliArgs = append(cliArgs, sslArgs...)
+	cliArgs = append(cliArgs, args...)
+
+	cmd := exec.CommandContext(ctx, backup.ToolPath(d.BinDir, "redis-cli"), cliArgs...)
+
+	// Pass the password via environment so it is not visible in ps output
+	if password != "" {
+		cmd.Env = append(os.Environ(), fmt.Sprintf("REDISCLI_AUTH=%s", password))
+	}
+
+	return cmd, nil
+}
+
+// runCLI runs a redis-cli command against a node and returns its output
+func (d *Dumper) runCLI(ctx context.Context, host string, port int, username, password string, args ...string) (string, error) {
+	cmd, err := d.command(ctx, host, port, username, password, args...)
+	if err != nil {
+		return "", err
+	}
+
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = backup.StartCommand(cmd)
+	if err == nil {
+		err = cmd.Wait()
+	}
+	output := stdout.String()
+	if err != nil {
+		return "", fmt.Errorf("redis-cli failed: %w\nOutput: %s%s", err, output, stderr.String())
+	}
+
+	return output, nil
 }
 
 type cmdReader struct {
@@ -116,30 +265,56 @@ func (cr *cmdReader) Close() error {
 
 	if err := cr.cmd.Wait(); err != nil {
 		if len(remainingStderr) > 0 {
-			// Check if we should log debug output
 			stderrStr := string(remainingStderr)
-			if strings.Contains(os.Getenv("LOG_LEVEL"), "debug") ||
-				strings.Contains(os.Getenv("LOG_LEVEL"), "DEBUG") {
-				log.Printf("DEBUG: redis-cli stderr: %s", stderrStr)
-			}
+			logger.Debugf("redis-cli stderr: %s", stderrStr)
 			return fmt.Errorf("redis-cli failed: %w\nOutput: %s", err, stderrStr)
 		}
 		return fmt.Errorf("redis-cli failed: %w", err)
 	}
 
-	// Also log any warnings/messages even on success if debug is enabled
+	// Also log any warnings/messages even on success at debug level
 	if len(remainingStderr) > 0 {
-		if strings.Contains(os.Getenv("LOG_LEVEL"), "debug") ||
-			strings.Contains(os.Getenv("LOG_LEVEL"), "DEBUG") {
-			log.Printf("DEBUG: redis-cli stderr: %s", string(remainingStderr))
-		}
+		logger.Debugf("redis-cli stderr: %s", string(remainingStderr))
 	}
 
 	return nil
 }
 
+// tlsArgs maps TLS options to redis-cli --tls options
+func tlsArgs(tls backup.TLSConfig) ([]string, error) {
+	mode, err := tls.NormalizedMode()
+	if err != nil {
+		return nil, err
+	}
+
+	// redis-cli cannot negotiate TLS opportunistically, so allow/prefer leave it off
+	if mode == "" || mode == backup.SSLModeDisable || mode == backup.SSLModeAllow || mode == backup.SSLModePrefer {
+		return nil, nil
+	}
+
+	args := []string{"--tls"}
+	if mode == backup.SSLModeRequire {
+		args = append(args, "--insecure")
+	}
+	if tls.CAFile != "" {
+		args = append(args, "--cacert", tls.CAFile)
+	}
+	if tls.CertFile != "" {
+		args = append(args, "--cert", tls.CertFile)
+	}
+	if tls.KeyFile != "" {
+		args = append(args, "--key", tls.KeyFile)
+	}
+
+	return args, nil
+}
+
 // GetFileExtension returns the file extension for Redis dumps
 func (d *Dumper) GetFileExtension() string {
+	if d.Cluster {
+		// One RDB per master shard, bundled in a tar archive
+		return ".tar"
+	}
 	return ".rdb"
 }
 
@@ -152,3 +327,55 @@ func (d *Dumper) GetDatabaseType() string {
 func (d *Dumper) GetDatabaseName() string {
 	return "default"
 }
+
+// GetDatabaseHost returns the database host for object key templates
+func (d *Dumper) GetDatabaseHost() string {
+	return d.Host
+}
+
+// GetBackupMetadata returns the topology and RDB details of the dump
+func (d *Dumper) GetBackupMetadata() map[string]string {
+	metadata := backup.VersionMetadata(d.clientVersion, d.serverVersion)
+	metadata["redis_mode"] = d.mode
+	metadata["engine"] = d.engine()
+	metadata["db_index"] = strconv.Itoa(d.DBIndex)
+	metadata["nodes"] = strings.Join(d.sources, ",")
+	if d.SentinelMaster != "" {
+		metadata["sentinel_master"] = d.SentinelMaster
+	}
+	if d.rdbVersion != "" {
+		metadata["rdb_version"] = d.rdbVersion
+	}
+	return metadata
+}
+
+// DescribeCommand returns the redis-cli command line, or the replication command the
+// built-in client sends. With Sentinel or Cluster the nodes are only known once discovered,
+// so the command is shown for the configured host.
+func (d *Dumper) DescribeCommand() ([]string, error) {
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	switch {
+	case d.Cluster:
+		lines = append(lines, fmt.Sprintf("CLUSTER NODES on %s, then for each master shard (shown for the seed node):", net.JoinHostPort(d.Host, strconv.Itoa(d.Port))))
+	case len(d.Sentinels) > 0:
+		lines = append(lines, fmt.Sprintf("SENTINEL get-master-addr-by-name %s on %s, then on the reported master (shown for --db-host):", d.SentinelMaster, strings.Join(d.Sentinels, ", ")))
+	}
+
+	if d.engine() == backup.EngineNative {
+		return append(lines, fmt.Sprintf("built-in client (no external command): PSYNC ? -1 on %s", net.JoinHostPort(d.Host, strconv.Itoa(d.Port)))), nil
+	}
+
+	output := "-"
+	if d.Cluster {
+		output = "<shard>.rdb"
+	}
+	cmd, err := d.command(context.Background(), d.Host, d.Port, d.Username, d.Password, "--rdb", output)
+	if err != nil {
+		return nil, err
+	}
+	return append(lines, backup.FormatCommand(cmd)), nil
+}
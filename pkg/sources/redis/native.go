@@ -0,0 +1,276 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+)
+
+// respConn is a minimal RESP client, enough to run administrative commands and to
+// receive an RDB snapshot through the replication protocol
+type respConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	stop   func() bool
+}
+
+// respError is an error reply from the server
+type respError string
+
+func (e respError) Error() string {
+	return string(e)
+}
+
+// dialNode connects and authenticates to a Redis node without redis-cli
+func dialNode(ctx context.Context, host string, port int, username, password string, tlsConfig backup.TLSConfig) (*respConn, error) {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+
+	clientConfig, err := tlsConfig.ClientConfig(host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if clientConfig != nil {
+		tlsConn := tls.Client(conn, clientConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS handshake with %s failed: %w", address, err)
+		}
+		conn = tlsConn
+	}
+
+	rc := &respConn{
+		conn:   conn,
+		reader: bufio.NewReaderSize(conn, 64*1024),
+		// Unblock reads when the context is cancelled
+		stop: context.AfterFunc(ctx, func() { conn.Close() }),
+	}
+
+	if password != "" {
+		args := []string{"AUTH", password}
+		if username != "" {
+			args = []string{"AUTH", username, password}
+		}
+		if _, err := rc.do(args...); err != nil {
+			rc.Close()
+			return nil, fmt.Errorf("authentication to %s failed: %w", address, err)
+		}
+	}
+
+	return rc, nil
+}
+
+func (rc *respConn) Close() error {
+	rc.stop()
+	return rc.conn.Close()
+}
+
+// send writes a command as a RESP array of bulk strings
+func (rc *respConn) send(args ...string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err := io.WriteString(rc.conn, b.String())
+	return err
+}
+
+// do sends a command and reads its reply
+func (rc *respConn) do(args ...string) (interface{}, error) {
+	if err := rc.send(args...); err != nil {
+		return nil, err
+	}
+	return rc.readReply()
+}
+
+func (rc *respConn) readLine() (string, error) {
+	line, err := rc.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readReply reads a reply: strings for simple and bulk strings, int64 for integers,
+// []interface{} for arrays and nil for null values. Error replies are returned as respError.
+func (rc *respConn) readReply() (interface{}, error) {
+	line, err := rc.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if line == "" {
+		return nil, fmt.Errorf("empty reply from server")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, respError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid bulk length %q", line)
+		}
+		if length < 0 {
+			return nil, nil
+		}
+		data := make([]byte, length+2)
+		if _, err := io.ReadFull(rc.reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:length]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid array length %q", line)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = rc.readReply(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected reply %q", line)
+	}
+}
+
+// runNative runs a command and formats the reply like redis-cli does for scripts:
+// one line per array element
+func (d *Dumper) runNative(ctx context.Context, host string, port int, username, password string, args ...string) (string, error) {
+	conn, err := dialNode(ctx, host, port, username, password, d.TLS)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	reply, err := conn.do(args...)
+	if err != nil {
+		return "", err
+	}
+
+	return formatReply(reply), nil
+}
+
+func formatReply(reply interface{}) string {
+	switch value := reply.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		lines := make([]string, len(value))
+		for i, item := range value {
+			lines[i] = formatReply(item)
+		}
+		return strings.Join(lines, "\n")
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// syncRDB registers as a replica and returns the RDB snapshot the master sends for a full
+// resynchronization. The connection is closed, ending the replication, once the snapshot
+// has been read.
+func (d *Dumper) syncRDB(ctx context.Context, host string, port int) (io.ReadCloser, error) {
+	conn, err := dialNode(ctx, host, port, d.Username, d.Password, d.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	// PSYNC with an unknown replication ID forces a full resync; masters older than
+	// Redis 2.8 only understand SYNC
+	if err := conn.send("PSYNC", "?", "-1"); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	line, err := conn.readLine()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("PSYNC failed: %w", err)
+	}
+	if strings.HasPrefix(line, "-") {
+		if !strings.Contains(strings.ToLower(line), "unknown command") {
+			conn.Close()
+			return nil, fmt.Errorf("PSYNC failed: %s", line[1:])
+		}
+		if err := conn.send("SYNC"); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	} else if !strings.HasPrefix(line, "+FULLRESYNC") {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected PSYNC reply %q", line)
+	}
+
+	// The master sends newlines as keep-alives while it produces the snapshot
+	for {
+		line, err = conn.readLine()
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to receive RDB snapshot: %w", err)
+		}
+		if line != "" {
+			break
+		}
+	}
+
+	if strings.HasPrefix(line, "-") {
+		conn.Close()
+		return nil, fmt.Errorf("master refused to sync: %s", line[1:])
+	}
+	if !strings.HasPrefix(line, "$") || strings.HasPrefix(line, "$EOF:") {
+		// Diskless transfers with an EOF marker are only used for replicas announcing capa eof
+		conn.Close()
+		return nil, fmt.Errorf("unexpected RDB transfer header %q", line)
+	}
+
+	length, err := strconv.ParseInt(line[1:], 10, 64)
+	if err != nil || length < 0 {
+		conn.Close()
+		return nil, fmt.Errorf("invalid RDB length %q", line)
+	}
+
+	return &syncReader{reader: io.LimitReader(conn.reader, length), conn: conn, remaining: length}, nil
+}
+
+// syncReader reads a fixed-length RDB transfer and fails on a truncated snapshot
+type syncReader struct {
+	reader    io.Reader
+	conn      *respConn
+	remaining int64
+}
+
+func (sr *syncReader) Read(p []byte) (int, error) {
+	n, err := sr.reader.Read(p)
+	sr.remaining -= int64(n)
+	if errors.Is(err, io.EOF) && sr.remaining > 0 {
+		return n, fmt.Errorf("RDB transfer ended %d bytes early", sr.remaining)
+	}
+	return n, err
+}
+
+func (sr *syncReader) Close() error {
+	return sr.conn.Close()
+}
@@ -0,0 +1,302 @@
+package rdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var errCorrupt = errors.New("corrupt encoded value")
+
+// decodeZiplist returns the entries of a ziplist blob
+func decodeZiplist(blob []byte) ([][]byte, error) {
+	// zlbytes (4), zltail (4), zllen (2)
+	if len(blob) < 11 {
+		return nil, fmt.Errorf("ziplist: %w", errCorrupt)
+	}
+
+	var entries [][]byte
+	pos := 10
+	for {
+		if pos >= len(blob) {
+			return nil, fmt.Errorf("ziplist: %w", errCorrupt)
+		}
+		if blob[pos] == 0xff {
+			return entries, nil
+		}
+
+		// Length of the previous entry
+		if blob[pos] == 0xfe {
+			pos += 5
+		} else {
+			pos++
+		}
+		if pos >= len(blob) {
+			return nil, fmt.Errorf("ziplist: %w", errCorrupt)
+		}
+
+		entry, size, err := decodeZiplistEntry(blob[pos:])
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+		pos += size
+	}
+}
+
+func decodeZiplistEntry(b []byte) ([]byte, int, error) {
+	header := b[0]
+
+	switch header >> 6 {
+	case 0:
+		length := int(header & 0x3f)
+		return slice(b, 1, length)
+	case 1:
+		if len(b) < 2 {
+			return nil, 0, fmt.Errorf("ziplist: %w", errCorrupt)
+		}
+		length := int(header&0x3f)<<8 | int(b[1])
+		return slice(b, 2, length)
+	case 2:
+		if len(b) < 5 {
+			return nil, 0, fmt.Errorf("ziplist: %w", errCorrupt)
+		}
+		length := int(binary.BigEndian.Uint32(b[1:5]))
+		return slice(b, 5, length)
+	}
+
+	var value int64
+	size := 1
+	switch header {
+	case 0xc0:
+		size += 2
+		if len(b) < size {
+			return nil, 0, fmt.Errorf("ziplist: %w", errCorrupt)
+		}
+		value = int64(int16(binary.LittleEndian.Uint16(b[1:])))
+	case 0xd0:
+		size += 4
+		if len(b) < size {
+			return nil, 0, fmt.Errorf("ziplist: %w", errCorrupt)
+		}
+		value = int64(int32(binary.LittleEndian.Uint32(b[1:])))
+	case 0xe0:
+		size += 8
+		if len(b) < size {
+			return nil, 0, fmt.Errorf("ziplist: %w", errCorrupt)
+		}
+		value = int64(binary.LittleEndian.Uint64(b[1:]))
+	case 0xf0:
+		size += 3
+		if len(b) < size {
+			return nil, 0, fmt.Errorf("ziplist: %w", errCorrupt)
+		}
+		value = signExtend(uint64(b[1])|uint64(b[2])<<8|uint64(b[3])<<16, 24)
+	case 0xfe:
+		size++
+		if len(b) < size {
+			return nil, 0, fmt.Errorf("ziplist: %w", errCorrupt)
+		}
+		value = int64(int8(b[1]))
+	default:
+		// 1111xxxx: immediate 4-bit value between 0 and 12
+		if header >= 0xf1 && header <= 0xfd {
+			value = int64(header&0x0f) - 1
+		} else {
+			return nil, 0, fmt.Errorf("ziplist: unknown entry encoding 0x%02x", header)
+		}
+	}
+
+	return []byte(strconv.FormatInt(value, 10)), size, nil
+}
+
+// decodeListpack returns the entries of a listpack blob
+func decodeListpack(blob []byte) ([][]byte, error) {
+	// total bytes (4), number of elements (2)
+	if len(blob) < 7 {
+		return nil, fmt.Errorf("listpack: %w", errCorrupt)
+	}
+
+	var entries [][]byte
+	pos := 6
+	for {
+		if pos >= len(blob) {
+			return nil, fmt.Errorf("listpack: %w", errCorrupt)
+		}
+		if blob[pos] == 0xff {
+			return entries, nil
+		}
+
+		entry, size, err := decodeListpackEntry(blob[pos:])
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+		pos += size + listpackBacklenSize(size)
+	}
+}
+
+func decodeListpackEntry(b []byte) ([]byte, int, error) {
+	header := b[0]
+
+	switch {
+	case header&0x80 == 0: // 0xxxxxxx: 7-bit unsigned integer
+		return []byte(strconv.Itoa(int(header & 0x7f))), 1, nil
+	case header&0xc0 == 0x80: // 10xxxxxx: string up to 63 bytes
+		return slice(b, 1, int(header&0x3f))
+	case header&0xe0 == 0xc0: // 110xxxxx yyyyyyyy: 13-bit signed integer
+		if len(b) < 2 {
+			return nil, 0, fmt.Errorf("listpack: %w", errCorrupt)
+		}
+		value := signExtend(uint64(header&0x1f)<<8|uint64(b[1]), 13)
+		return []byte(strconv.FormatInt(value, 10)), 2, nil
+	case header&0xf0 == 0xe0: // 1110xxxx yyyyyyyy: string up to 4095 bytes
+		if len(b) < 2 {
+			return nil, 0, fmt.Errorf("listpack: %w", errCorrupt)
+		}
+		return slice(b, 2, int(header&0x0f)<<8|int(b[1]))
+	}
+
+	var size int
+	switch header {
+	case 0xf0: // 32-bit string length
+		if len(b) < 5 {
+			return nil, 0, fmt.Errorf("listpack: %w", errCorrupt)
+		}
+		return slice(b, 5, int(binary.LittleEndian.Uint32(b[1:5])))
+	case 0xf1:
+		size = 2
+	case 0xf2:
+		size = 3
+	case 0xf3:
+		size = 4
+	case 0xf4:
+		size = 8
+	default:
+		return nil, 0, fmt.Errorf("listpack: unknown entry encoding 0x%02x", header)
+	}
+
+	if len(b) < 1+size {
+		return nil, 0, fmt.Errorf("listpack: %w", errCorrupt)
+	}
+	var raw uint64
+	for i := 0; i < size; i++ {
+		raw |= uint64(b[1+i]) << (8 * i)
+	}
+	value := signExtend(raw, size*8)
+
+	return []byte(strconv.FormatInt(value, 10)), 1 + size, nil
+}
+
+// listpackBacklenSize returns the size of the back-length field following an entry
+func listpackBacklenSize(entrySize int) int {
+	switch {
+	case entrySize <= 127:
+		return 1
+	case entrySize < 16383:
+		return 2
+	case entrySize < 2097151:
+		return 3
+	case entrySize < 268435455:
+		return 4
+	default:
+		return 5
+	}
+}
+
+// decodeIntset returns the members of an intset blob
+func decodeIntset(blob []byte) ([][]byte, error) {
+	if len(blob) < 8 {
+		return nil, fmt.Errorf("intset: %w", errCorrupt)
+	}
+
+	width := int(binary.LittleEndian.Uint32(blob[0:4]))
+	count := int(binary.LittleEndian.Uint32(blob[4:8]))
+	if width != 2 && width != 4 && width != 8 {
+		return nil, fmt.Errorf("intset: invalid encoding %d", width)
+	}
+	if len(blob) < 8+width*count {
+		return nil, fmt.Errorf("intset: %w", errCorrupt)
+	}
+
+	members := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		b := blob[8+i*width:]
+		var value int64
+		switch width {
+		case 2:
+			value = int64(int16(binary.LittleEndian.Uint16(b)))
+		case 4:
+			value = int64(int32(binary.LittleEndian.Uint32(b)))
+		case 8:
+			value = int64(binary.LittleEndian.Uint64(b))
+		}
+		members = append(members, []byte(strconv.FormatInt(value, 10)))
+	}
+
+	return members, nil
+}
+
+// decodeZipmap returns the alternating fields and values of a (pre Redis 2.6) zipmap blob
+func decodeZipmap(blob []byte) ([][]byte, error) {
+	var entries [][]byte
+	pos := 1 // zmlen
+
+	readLen := func() (int, error) {
+		if pos >= len(blob) {
+			return 0, fmt.Errorf("zipmap: %w", errCorrupt)
+		}
+		if blob[pos] < 254 {
+			pos++
+			return int(blob[pos-1]), nil
+		}
+		if blob[pos] == 254 && pos+5 <= len(blob) {
+			length := int(binary.LittleEndian.Uint32(blob[pos+1 : pos+5]))
+			pos += 5
+			return length, nil
+		}
+		return 0, fmt.Errorf("zipmap: %w", errCorrupt)
+	}
+
+	for pos < len(blob) && blob[pos] != 0xff {
+		keyLen, err := readLen()
+		if err != nil {
+			return nil, err
+		}
+		if pos+keyLen > len(blob) {
+			return nil, fmt.Errorf("zipmap: %w", errCorrupt)
+		}
+		entries = append(entries, blob[pos:pos+keyLen])
+		pos += keyLen
+
+		valueLen, err := readLen()
+		if err != nil {
+			return nil, err
+		}
+		if pos >= len(blob) {
+			return nil, fmt.Errorf("zipmap: %w", errCorrupt)
+		}
+		free := int(blob[pos])
+		pos++
+		if pos+valueLen+free > len(blob) {
+			return nil, fmt.Errorf("zipmap: %w", errCorrupt)
+		}
+		entries = append(entries, blob[pos:pos+valueLen])
+		pos += valueLen + free
+	}
+
+	return entries, nil
+}
+
+func slice(b []byte, headerSize, length int) ([]byte, int, error) {
+	if len(b) < headerSize+length {
+		return nil, 0, errCorrupt
+	}
+	return b[headerSize : headerSize+length], headerSize + length, nil
+}
+
+func signExtend(value uint64, bits int) int64 {
+	shift := 64 - bits
+	return int64(value<<shift) >> shift
+}
@@ -0,0 +1,300 @@
+package rdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Length encodings (top two bits of the first byte)
+const (
+	len6Bit    = 0
+	len14Bit   = 1
+	len32or64  = 2
+	lenEncoded = 3
+
+	len32Bit = 0x80
+	len64Bit = 0x81
+)
+
+// Special string encodings (when the length type is lenEncoded)
+const (
+	encInt8  = 0
+	encInt16 = 1
+	encInt32 = 2
+	encLZF   = 3
+)
+
+// maxStringLength is the largest string Redis stores (proto-max-bulk-len). Longer lengths
+// only come from corrupt input and are rejected rather than allocated.
+const maxStringLength = 512 << 20
+
+// readChunkSize is the largest buffer allocated before its data has been read, so a length
+// in a truncated file does not allocate memory for data that is not there
+const readChunkSize = 1 << 20
+
+// lzfMaxRatio bounds how much LZF expands its input: a 3-byte back reference copies at most
+// 264 bytes
+const lzfMaxRatio = 88
+
+// countingReader tracks how many bytes of the RDB have been consumed
+type countingReader struct {
+	reader io.Reader
+	offset int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.reader.Read(p)
+	cr.offset += int64(n)
+	return n, err
+}
+
+func (p *Parser) readByte() (byte, error) {
+	if _, err := io.ReadFull(p.reader, p.scratch[:1]); err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	return p.scratch[0], nil
+}
+
+func (p *Parser) readBytes(n int) ([]byte, error) {
+	if n < 0 || n > maxStringLength {
+		return nil, fmt.Errorf("invalid length %d", n)
+	}
+	if n > readChunkSize {
+		var buf bytes.Buffer
+		if _, err := io.CopyN(&buf, p.reader, int64(n)); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		return buf.Bytes(), nil
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(p.reader, buf); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return buf, nil
+}
+
+func (p *Parser) skip(n int64) error {
+	if _, err := io.CopyN(io.Discard, p.reader, n); err != nil {
+		return unexpectedEOF(err)
+	}
+	return nil
+}
+
+func (p *Parser) readUint32LE() (uint32, error) {
+	buf, err := p.readBytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(buf), nil
+}
+
+func (p *Parser) readUint64LE() (uint64, error) {
+	buf, err := p.readBytes(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf), nil
+}
+
+// readLength reads a length-encoded integer. encoded reports a special string encoding,
+// in which case the returned value is the encoding type.
+func (p *Parser) readLength() (uint64, bool, error) {
+	first, err := p.readByte()
+	if err != nil {
+		return 0, false, err
+	}
+
+	switch first >> 6 {
+	case len6Bit:
+		return uint64(first & 0x3f), false, nil
+	case len14Bit:
+		second, err := p.readByte()
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(first&0x3f)<<8 | uint64(second), false, nil
+	case lenEncoded:
+		return uint64(first & 0x3f), true, nil
+	}
+
+	switch first {
+	case len32Bit:
+		buf, err := p.readBytes(4)
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(binary.BigEndian.Uint32(buf)), false, nil
+	case len64Bit:
+		buf, err := p.readBytes(8)
+		if err != nil {
+			return 0, false, err
+		}
+		return binary.BigEndian.Uint64(buf), false, nil
+	}
+
+	return 0, false, fmt.Errorf("unknown length encoding 0x%02x", first)
+}
+
+// readLen reads a plain length, rejecting special encodings
+func (p *Parser) readLen() (int, error) {
+	length, encoded, err := p.readLength()
+	if err != nil {
+		return 0, err
+	}
+	if encoded {
+		return 0, fmt.Errorf("unexpected encoded value where a length was expected")
+	}
+	if length > math.MaxInt32 {
+		return 0, fmt.Errorf("length %d too large", length)
+	}
+	return int(length), nil
+}
+
+// readString reads a string, which may be stored as an integer or LZF-compressed
+func (p *Parser) readString() ([]byte, error) {
+	length, encoded, err := p.readLength()
+	if err != nil {
+		return nil, err
+	}
+
+	if !encoded {
+		if length > math.MaxInt32 {
+			return nil, fmt.Errorf("string length %d too large", length)
+		}
+		return p.readBytes(int(length))
+	}
+
+	switch length {
+	case encInt8:
+		b, err := p.readByte()
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strconv.Itoa(int(int8(b)))), nil
+	case encInt16:
+		buf, err := p.readBytes(2)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strconv.Itoa(int(int16(binary.LittleEndian.Uint16(buf))))), nil
+	case encInt32:
+		buf, err := p.readBytes(4)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strconv.Itoa(int(int32(binary.LittleEndian.Uint32(buf))))), nil
+	case encLZF:
+		compressedLen, err := p.readLen()
+		if err != nil {
+			return nil, err
+		}
+		uncompressedLen, err := p.readLen()
+		if err != nil {
+			return nil, err
+		}
+		if uncompressedLen > compressedLen*lzfMaxRatio {
+			return nil, fmt.Errorf("corrupt LZF string: %d bytes cannot decompress to %d", compressedLen, uncompressedLen)
+		}
+		compressed, err := p.readBytes(compressedLen)
+		if err != nil {
+			return nil, err
+		}
+		return lzfDecompress(compressed, uncompressedLen)
+	}
+
+	return nil, fmt.Errorf("unknown string encoding %d", length)
+}
+
+// readFloatString reads a ZSET score in the legacy string format
+func (p *Parser) readFloatString() (float64, error) {
+	length, err := p.readByte()
+	if err != nil {
+		return 0, err
+	}
+
+	switch length {
+	case 253:
+		return math.NaN(), nil
+	case 254:
+		return math.Inf(1), nil
+	case 255:
+		return math.Inf(-1), nil
+	}
+
+	buf, err := p.readBytes(int(length))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(string(buf), 64)
+}
+
+func (p *Parser) readBinaryDouble() (float64, error) {
+	bits, err := p.readUint64LE()
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(bits), nil
+}
+
+// lzfDecompress decompresses LZF data as written by Redis
+func lzfDecompress(in []byte, outLen int) ([]byte, error) {
+	if outLen > len(in)*lzfMaxRatio {
+		return nil, fmt.Errorf("corrupt LZF data: %d bytes cannot decompress to %d", len(in), outLen)
+	}
+	out := make([]byte, 0, outLen)
+
+	for i := 0; i < len(in); {
+		ctrl := int(in[i])
+		i++
+
+		if ctrl < 32 {
+			// Literal run of ctrl+1 bytes
+			end := i + ctrl + 1
+			if end > len(in) {
+				return nil, errors.New("corrupt LZF data: literal overruns input")
+			}
+			out = append(out, in[i:end]...)
+			i = end
+			continue
+		}
+
+		// Back reference
+		length := ctrl >> 5
+		if length == 7 {
+			if i >= len(in) {
+				return nil, errors.New("corrupt LZF data: truncated back reference")
+			}
+			length += int(in[i])
+			i++
+		}
+		if i >= len(in) {
+			return nil, errors.New("corrupt LZF data: truncated back reference")
+		}
+		ref := len(out) - ((ctrl & 0x1f) << 8) - int(in[i]) - 1
+		i++
+		if ref < 0 {
+			return nil, errors.New("corrupt LZF data: back reference before start")
+		}
+		for j := 0; j < length+2; j++ {
+			out = append(out, out[ref+j])
+		}
+	}
+
+	if len(out) != outLen {
+		return nil, fmt.Errorf("corrupt LZF data: expected %d bytes, got %d", outLen, len(out))
+	}
+
+	return out, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
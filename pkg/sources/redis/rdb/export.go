@@ -0,0 +1,154 @@
+package rdb
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Exporter writes entries in an export format
+type Exporter interface {
+	Write(entry *Entry) error
+	Flush() error
+}
+
+// NewExporter creates an exporter for the "json" (JSON lines) or "resp" (Redis protocol commands) format
+func NewExporter(format string, w io.Writer) (Exporter, error) {
+	switch format {
+	case "json":
+		buffered := bufio.NewWriter(w)
+		return &jsonExporter{writer: buffered, encoder: json.NewEncoder(buffered)}, nil
+	case "resp":
+		return &respExporter{writer: bufio.NewWriter(w), db: -1}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q (expected json or resp)", format)
+	}
+}
+
+// jsonRecord is one line of JSON export. Strings that are not valid UTF-8 are replaced
+// with U+FFFD by encoding/json, so binary values should be exported as RESP instead.
+type jsonRecord struct {
+	DB       int         `json:"db"`
+	Key      string      `json:"key"`
+	Type     Type        `json:"type"`
+	ExpireAt *time.Time  `json:"expire_at,omitempty"`
+	Value    interface{} `json:"value"`
+}
+
+type jsonExporter struct {
+	writer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func (e *jsonExporter) Write(entry *Entry) error {
+	record := jsonRecord{DB: entry.DB, Key: entry.Key, Type: entry.Type}
+	if !entry.ExpireAt.IsZero() {
+		expireAt := entry.ExpireAt.UTC()
+		record.ExpireAt = &expireAt
+	}
+
+	switch entry.Type {
+	case TypeString:
+		record.Value = entry.String
+	case TypeList, TypeSet:
+		record.Value = entry.Members
+	case TypeZSet:
+		members := make([]map[string]interface{}, 0, len(entry.ZSet))
+		for _, member := range entry.ZSet {
+			members = append(members, map[string]interface{}{"member": member.Member, "score": formatScore(member.Score)})
+		}
+		record.Value = members
+	case TypeHash:
+		fields := make(map[string]string, len(entry.Hash))
+		for _, field := range entry.Hash {
+			fields[field.Field] = field.Value
+		}
+		record.Value = fields
+	default:
+		// Stream contents are not decoded
+		return nil
+	}
+
+	return e.encoder.Encode(record)
+}
+
+func (e *jsonExporter) Flush() error {
+	return e.writer.Flush()
+}
+
+// respExporter writes commands in the Redis protocol, suitable for `redis-cli --pipe`
+type respExporter struct {
+	writer *bufio.Writer
+	db     int
+}
+
+func (e *respExporter) Write(entry *Entry) error {
+	if entry.Type == TypeStream {
+		return nil
+	}
+
+	if entry.DB != e.db {
+		if err := e.command("SELECT", strconv.Itoa(entry.DB)); err != nil {
+			return err
+		}
+		e.db = entry.DB
+	}
+
+	// Replace any existing value so collections are not merged into
+	if entry.Type != TypeString {
+		if err := e.command("DEL", entry.Key); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	switch entry.Type {
+	case TypeString:
+		err = e.command("SET", entry.Key, entry.String)
+	case TypeList:
+		err = e.command("RPUSH", append([]string{entry.Key}, entry.Members...)...)
+	case TypeSet:
+		err = e.command("SADD", append([]string{entry.Key}, entry.Members...)...)
+	case TypeZSet:
+		args := []string{entry.Key}
+		for _, member := range entry.ZSet {
+			args = append(args, formatScore(member.Score), member.Member)
+		}
+		err = e.command("ZADD", args...)
+	case TypeHash:
+		args := []string{entry.Key}
+		for _, field := range entry.Hash {
+			args = append(args, field.Field, field.Value)
+		}
+		err = e.command("HSET", args...)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !entry.ExpireAt.IsZero() {
+		return e.command("PEXPIREAT", entry.Key, strconv.FormatInt(entry.ExpireAt.UnixMilli(), 10))
+	}
+
+	return nil
+}
+
+func (e *respExporter) command(name string, args ...string) error {
+	fmt.Fprintf(e.writer, "*%d\r\n$%d\r\n%s\r\n", len(args)+1, len(name), name)
+	for _, arg := range args {
+		fmt.Fprintf(e.writer, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return nil
+}
+
+func (e *respExporter) Flush() error {
+	return e.writer.Flush()
+}
+
+// formatScore formats a sorted set score the way Redis accepts it
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'g', 17, 64)
+}
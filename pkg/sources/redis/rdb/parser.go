@@ -0,0 +1,568 @@
+// Package rdb reads Redis RDB snapshots, such as those produced by redis.Dumper,
+// into a stream of keys and values.
+package rdb
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Type is the logical type of a Redis key
+type Type string
+
+const (
+	TypeString Type = "string"
+	TypeList   Type = "list"
+	TypeSet    Type = "set"
+	TypeZSet   Type = "zset"
+	TypeHash   Type = "hash"
+	TypeStream Type = "stream"
+)
+
+// Opcodes
+const (
+	opSlotInfo      = 0xf4
+	opFunction2     = 0xf5
+	opFunctionPreGA = 0xf6
+	opModuleAux     = 0xf7
+	opIdle          = 0xf8
+	opFreq          = 0xf9
+	opAux           = 0xfa
+	opResizeDB      = 0xfb
+	opExpireTimeMs  = 0xfc
+	opExpireTime    = 0xfd
+	opSelectDB      = 0xfe
+	opEOF           = 0xff
+)
+
+// Value types
+const (
+	typeString           = 0
+	typeList             = 1
+	typeSet              = 2
+	typeZSet             = 3
+	typeHash             = 4
+	typeZSet2            = 5
+	typeModule           = 6
+	typeModule2          = 7
+	typeHashZipmap       = 9
+	typeListZiplist      = 10
+	typeSetIntset        = 11
+	typeZSetZiplist      = 12
+	typeHashZiplist      = 13
+	typeListQuicklist    = 14
+	typeStreamListpacks  = 15
+	typeHashListpack     = 16
+	typeZSetListpack     = 17
+	typeListQuicklist2   = 18
+	typeStreamListpacks2 = 19
+	typeSetListpack      = 20
+	typeStreamListpacks3 = 21
+)
+
+// Quicklist 2 container formats
+const (
+	quicklistNodePlain  = 1
+	quicklistNodePacked = 2
+)
+
+// ZMember is a sorted set member and its score
+type ZMember struct {
+	Member string
+	Score  float64
+}
+
+// HashField is a hash field and its value
+type HashField struct {
+	Field string
+	Value string
+}
+
+// Entry is a key read from an RDB file. Only the value field matching Type is set;
+// stream contents are skipped and only their length is reported.
+type Entry struct {
+	DB       int
+	Key      string
+	Type     Type
+	ExpireAt time.Time // zero when the key has no TTL
+	Size     int64     // serialized size of the key and value in the RDB
+	Length   int       // number of elements (1 for strings)
+
+	String  string
+	Members []string // list elements in order, or set members
+	ZSet    []ZMember
+	Hash    []HashField
+}
+
+// Parser reads entries from an RDB stream
+type Parser struct {
+	reader  *countingReader
+	scratch [8]byte
+	started bool
+	done    bool
+
+	// Version is the RDB format version from the file header
+	Version int
+	// Aux holds auxiliary fields such as redis-ver and ctime
+	Aux map[string]string
+
+	db       int
+	expireAt time.Time
+}
+
+// NewParser creates a parser reading an uncompressed RDB stream
+func NewParser(reader io.Reader) *Parser {
+	return &Parser{
+		reader: &countingReader{reader: reader},
+		Aux:    map[string]string{},
+	}
+}
+
+// Next returns the next key in the file, or io.EOF after the last one
+func (p *Parser) Next() (*Entry, error) {
+	if p.done {
+		return nil, io.EOF
+	}
+
+	if !p.started {
+		if err := p.readHeader(); err != nil {
+			return nil, err
+		}
+		p.started = true
+	}
+
+	for {
+		start := p.reader.offset
+		opcode, err := p.readByte()
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case opEOF:
+			p.done = true
+			// Versions 5+ end with a CRC64, verified when the backup was taken
+			if p.Version >= 5 {
+				if err := p.skip(8); err != nil {
+					return nil, err
+				}
+			}
+			return nil, io.EOF
+		case opSelectDB:
+			if p.db, err = p.readLen(); err != nil {
+				return nil, err
+			}
+		case opExpireTime:
+			seconds, err := p.readUint32LE()
+			if err != nil {
+				return nil, err
+			}
+			p.expireAt = time.Unix(int64(seconds), 0)
+		case opExpireTimeMs:
+			millis, err := p.readUint64LE()
+			if err != nil {
+				return nil, err
+			}
+			p.expireAt = time.UnixMilli(int64(millis))
+		case opResizeDB:
+			if err := p.skipLengths(2); err != nil {
+				return nil, err
+			}
+		case opAux:
+			key, err := p.readString()
+			if err != nil {
+				return nil, err
+			}
+			value, err := p.readString()
+			if err != nil {
+				return nil, err
+			}
+			p.Aux[string(key)] = string(value)
+		case opFreq:
+			if _, err := p.readByte(); err != nil {
+				return nil, err
+			}
+		case opIdle:
+			if err := p.skipLengths(1); err != nil {
+				return nil, err
+			}
+		case opSlotInfo:
+			if err := p.skipLengths(3); err != nil {
+				return nil, err
+			}
+		case opFunction2:
+			if _, err := p.readString(); err != nil {
+				return nil, err
+			}
+		case opModuleAux, opFunctionPreGA:
+			return nil, fmt.Errorf("unsupported RDB opcode 0x%02x (module data or pre-release functions)", opcode)
+		default:
+			entry, err := p.readEntry(opcode)
+			if err != nil {
+				return nil, err
+			}
+			entry.Size = p.reader.offset - start
+			return entry, nil
+		}
+	}
+}
+
+func (p *Parser) readHeader() error {
+	header, err := p.readBytes(9)
+	if err != nil {
+		return fmt.Errorf("failed to read RDB header: %w", err)
+	}
+	if !bytes.HasPrefix(header, []byte("REDIS")) {
+		return fmt.Errorf("not an RDB file (missing REDIS magic)")
+	}
+
+	version, err := strconv.Atoi(string(header[5:]))
+	if err != nil {
+		return fmt.Errorf("invalid RDB version %q", header[5:])
+	}
+	p.Version = version
+
+	return nil
+}
+
+func (p *Parser) skipLengths(count int) error {
+	for i := 0; i < count; i++ {
+		if _, _, err := p.readLength(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Parser) readEntry(valueType byte) (*Entry, error) {
+	key, err := p.readString()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &Entry{
+		DB:       p.db,
+		Key:      string(key),
+		ExpireAt: p.expireAt,
+	}
+	p.expireAt = time.Time{}
+
+	if err := p.readValue(entry, valueType); err != nil {
+		return nil, fmt.Errorf("key %q: %w", entry.Key, err)
+	}
+
+	return entry, nil
+}
+
+func (p *Parser) readValue(entry *Entry, valueType byte) error {
+	switch valueType {
+	case typeString:
+		value, err := p.readString()
+		if err != nil {
+			return err
+		}
+		entry.Type, entry.String, entry.Length = TypeString, string(value), 1
+		return nil
+
+	case typeList, typeSet:
+		entry.Type = TypeList
+		if valueType == typeSet {
+			entry.Type = TypeSet
+		}
+		items, err := p.readStringList()
+		if err != nil {
+			return err
+		}
+		entry.setMembers(items)
+		return nil
+
+	case typeZSet, typeZSet2:
+		count, err := p.readLen()
+		if err != nil {
+			return err
+		}
+		entry.Type = TypeZSet
+		for i := 0; i < count; i++ {
+			member, err := p.readString()
+			if err != nil {
+				return err
+			}
+			var score float64
+			if valueType == typeZSet2 {
+				score, err = p.readBinaryDouble()
+			} else {
+				score, err = p.readFloatString()
+			}
+			if err != nil {
+				return err
+			}
+			entry.ZSet = append(entry.ZSet, ZMember{Member: string(member), Score: score})
+		}
+		entry.Length = len(entry.ZSet)
+		return nil
+
+	case typeHash:
+		count, err := p.readLen()
+		if err != nil {
+			return err
+		}
+		entry.Type = TypeHash
+		for i := 0; i < count; i++ {
+			field, err := p.readString()
+			if err != nil {
+				return err
+			}
+			value, err := p.readString()
+			if err != nil {
+				return err
+			}
+			entry.Hash = append(entry.Hash, HashField{Field: string(field), Value: string(value)})
+		}
+		entry.Length = len(entry.Hash)
+		return nil
+
+	case typeListQuicklist, typeListQuicklist2:
+		return p.readQuicklist(entry, valueType == typeListQuicklist2)
+
+	case typeHashZipmap, typeListZiplist, typeSetIntset, typeZSetZiplist, typeHashZiplist,
+		typeHashListpack, typeZSetListpack, typeSetListpack:
+		return p.readEncodedValue(entry, valueType)
+
+	case typeStreamListpacks, typeStreamListpacks2, typeStreamListpacks3:
+		entry.Type = TypeStream
+		return p.skipStream(entry, valueType)
+
+	case typeModule, typeModule2:
+		return fmt.Errorf("module value types are not supported")
+	}
+
+	return fmt.Errorf("unsupported RDB value type %d", valueType)
+}
+
+func (p *Parser) readStringList() ([]string, error) {
+	count, err := p.readLen()
+	if err != nil {
+		return nil, err
+	}
+
+	// The count is not trusted for preallocation, corrupt files claim billions of items
+	items := make([]string, 0, min(count, 1024))
+	for i := 0; i < count; i++ {
+		item, err := p.readString()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, string(item))
+	}
+
+	return items, nil
+}
+
+func (p *Parser) readQuicklist(entry *Entry, version2 bool) error {
+	count, err := p.readLen()
+	if err != nil {
+		return err
+	}
+
+	entry.Type = TypeList
+	for i := 0; i < count; i++ {
+		container := quicklistNodePacked
+		if version2 {
+			if container, err = p.readLen(); err != nil {
+				return err
+			}
+		}
+
+		blob, err := p.readString()
+		if err != nil {
+			return err
+		}
+
+		if container == quicklistNodePlain {
+			entry.Members = append(entry.Members, string(blob))
+			continue
+		}
+
+		decode := decodeZiplist
+		if version2 {
+			decode = decodeListpack
+		}
+		items, err := decode(blob)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			entry.Members = append(entry.Members, string(item))
+		}
+	}
+
+	entry.Length = len(entry.Members)
+	return nil
+}
+
+// readEncodedValue decodes values stored as a single ziplist, listpack, intset or zipmap blob
+func (p *Parser) readEncodedValue(entry *Entry, valueType byte) error {
+	blob, err := p.readString()
+	if err != nil {
+		return err
+	}
+
+	var items [][]byte
+	switch valueType {
+	case typeHashZipmap:
+		items, err = decodeZipmap(blob)
+	case typeSetIntset:
+		items, err = decodeIntset(blob)
+	case typeListZiplist, typeZSetZiplist, typeHashZiplist:
+		items, err = decodeZiplist(blob)
+	default:
+		items, err = decodeListpack(blob)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch valueType {
+	case typeListZiplist:
+		entry.Type = TypeList
+		entry.setMembers(bytesToStrings(items))
+	case typeSetIntset, typeSetListpack:
+		entry.Type = TypeSet
+		entry.setMembers(bytesToStrings(items))
+	case typeZSetZiplist, typeZSetListpack:
+		entry.Type = TypeZSet
+		if len(items)%2 != 0 {
+			return fmt.Errorf("sorted set: %w", errCorrupt)
+		}
+		for i := 0; i < len(items); i += 2 {
+			score, err := strconv.ParseFloat(string(items[i+1]), 64)
+			if err != nil {
+				return fmt.Errorf("sorted set: invalid score %q", items[i+1])
+			}
+			entry.ZSet = append(entry.ZSet, ZMember{Member: string(items[i]), Score: score})
+		}
+		entry.Length = len(entry.ZSet)
+	default:
+		entry.Type = TypeHash
+		if len(items)%2 != 0 {
+			return fmt.Errorf("hash: %w", errCorrupt)
+		}
+		for i := 0; i < len(items); i += 2 {
+			entry.Hash = append(entry.Hash, HashField{Field: string(items[i]), Value: string(items[i+1])})
+		}
+		entry.Length = len(entry.Hash)
+	}
+
+	return nil
+}
+
+// skipStream consumes a stream value, keeping only its length
+func (p *Parser) skipStream(entry *Entry, valueType byte) error {
+	nodes, err := p.readLen()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < nodes; i++ {
+		// Master entry ID and listpack of entries
+		if _, err := p.readString(); err != nil {
+			return err
+		}
+		if _, err := p.readString(); err != nil {
+			return err
+		}
+	}
+
+	length, err := p.readLen()
+	if err != nil {
+		return err
+	}
+	entry.Length = length
+
+	// Last ID, then (v2+) first ID, max deleted ID and entries added
+	lengths := 2
+	if valueType >= typeStreamListpacks2 {
+		lengths += 5
+	}
+	if err := p.skipLengths(lengths); err != nil {
+		return err
+	}
+
+	groups, err := p.readLen()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < groups; i++ {
+		if _, err := p.readString(); err != nil {
+			return err
+		}
+
+		// Last delivered ID, then (v2+) entries read
+		lengths := 2
+		if valueType >= typeStreamListpacks2 {
+			lengths++
+		}
+		if err := p.skipLengths(lengths); err != nil {
+			return err
+		}
+
+		// Group pending entries: raw ID (16), delivery time (8), delivery count
+		pending, err := p.readLen()
+		if err != nil {
+			return err
+		}
+		for j := 0; j < pending; j++ {
+			if err := p.skip(24); err != nil {
+				return err
+			}
+			if err := p.skipLengths(1); err != nil {
+				return err
+			}
+		}
+
+		consumers, err := p.readLen()
+		if err != nil {
+			return err
+		}
+		for j := 0; j < consumers; j++ {
+			if _, err := p.readString(); err != nil {
+				return err
+			}
+
+			// Seen time, then (v3) active time
+			timestamps := int64(8)
+			if valueType >= typeStreamListpacks3 {
+				timestamps += 8
+			}
+			if err := p.skip(timestamps); err != nil {
+				return err
+			}
+
+			// Consumer pending entries are raw IDs referencing the group PEL
+			pending, err := p.readLen()
+			if err != nil {
+				return err
+			}
+			if err := p.skip(int64(pending) * 16); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (e *Entry) setMembers(items []string) {
+	e.Members = items
+	e.Length = len(items)
+}
+
+func bytesToStrings(items [][]byte) []string {
+	result := make([]string, len(items))
+	for i, item := range items {
+		result[i] = string(item)
+	}
+	return result
+}
@@ -0,0 +1,304 @@
+package rdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// expectedEntry is a key as described by the JSON file next to each RDB fixture
+type expectedEntry struct {
+	DB         int               `json:"db"`
+	Key        string            `json:"key"`
+	Type       Type              `json:"type"`
+	Encoding   string            `json:"encoding"`
+	Expiration *time.Time        `json:"expiration"`
+	Value      string            `json:"value"`
+	Values     []string          `json:"values"`
+	Members    []string          `json:"members"`
+	Entries    json.RawMessage   `json:"entries"`
+	Hash       map[string]string `json:"hash"`
+	Len        int               `json:"len"`
+}
+
+func readAll(t *testing.T, reader io.Reader) ([]*Entry, *Parser) {
+	t.Helper()
+
+	parser := NewParser(reader)
+	var entries []*Entry
+	for {
+		entry, err := parser.Next()
+		if err == io.EOF {
+			return entries, parser
+		}
+		if err != nil {
+			t.Fatalf("Next() after %d entries: %v", len(entries), err)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// jsonString returns s as it reads back from JSON, where invalid UTF-8 becomes U+FFFD
+func jsonString(s string) string {
+	data, _ := json.Marshal(s)
+	var out string
+	json.Unmarshal(data, &out)
+	return out
+}
+
+func jsonStrings(items []string) []string {
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = jsonString(item)
+	}
+	return out
+}
+
+func sorted(items []string) []string {
+	out := append([]string{}, items...)
+	sort.Strings(out)
+	return out
+}
+
+func TestParserFixtures(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.rdb"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no RDB fixtures found: %v", err)
+	}
+
+	for _, file := range files {
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".rdb"), func(t *testing.T) {
+			data, err := os.ReadFile(strings.TrimSuffix(file, ".rdb") + ".json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want []expectedEntry
+			if err := json.Unmarshal(data, &want); err != nil {
+				t.Fatal(err)
+			}
+
+			rdbFile, err := os.Open(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rdbFile.Close()
+			got, _ := readAll(t, rdbFile)
+
+			if len(got) != len(want) {
+				t.Fatalf("read %d entries, want %d", len(got), len(want))
+			}
+			for i, expected := range want {
+				checkEntry(t, got[i], expected)
+			}
+		})
+	}
+}
+
+func checkEntry(t *testing.T, got *Entry, want expectedEntry) {
+	t.Helper()
+
+	key := jsonString(got.Key)
+	if key != want.Key || got.DB != want.DB || got.Type != want.Type {
+		t.Errorf("entry = db %d %s %q, want db %d %s %q", got.DB, got.Type, key, want.DB, want.Type, want.Key)
+		return
+	}
+	if want.Expiration != nil && !got.ExpireAt.Equal(*want.Expiration) {
+		t.Errorf("%s: expires %v, want %v", key, got.ExpireAt, *want.Expiration)
+	}
+	if want.Expiration == nil && !got.ExpireAt.IsZero() {
+		t.Errorf("%s: expires %v, want no TTL", key, got.ExpireAt)
+	}
+
+	switch want.Type {
+	case TypeString:
+		if value := jsonString(got.String); value != want.Value {
+			t.Errorf("%s = %q, want %q", key, value, want.Value)
+		}
+	case TypeList:
+		if values := jsonStrings(got.Members); !reflect.DeepEqual(values, want.Values) {
+			t.Errorf("%s (%s) = %q, want %q", key, want.Encoding, values, want.Values)
+		}
+	case TypeSet:
+		if members := sorted(jsonStrings(got.Members)); !reflect.DeepEqual(members, sorted(want.Members)) {
+			t.Errorf("%s (%s) = %q, want %q", key, want.Encoding, members, sorted(want.Members))
+		}
+	case TypeZSet:
+		var members []ZMember
+		if err := json.Unmarshal(want.Entries, &members); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got.ZSet, members) {
+			t.Errorf("%s (%s) = %v, want %v", key, want.Encoding, got.ZSet, members)
+		}
+	case TypeHash:
+		fields := map[string]string{}
+		for _, field := range got.Hash {
+			fields[jsonString(field.Field)] = jsonString(field.Value)
+		}
+		if !reflect.DeepEqual(fields, want.Hash) {
+			t.Errorf("%s (%s) = %v, want %v", key, want.Encoding, fields, want.Hash)
+		}
+	case TypeStream:
+		if got.Length != want.Len {
+			t.Errorf("%s: stream length %d, want %d", key, got.Length, want.Len)
+		}
+	}
+}
+
+// rdbBuilder writes RDB files for encodings the fixtures do not cover
+type rdbBuilder struct {
+	bytes.Buffer
+}
+
+func newRDB(version string) *rdbBuilder {
+	b := &rdbBuilder{}
+	b.WriteString("REDIS" + version)
+	return b
+}
+
+func (b *rdbBuilder) length(n int) *rdbBuilder {
+	if n >= 64 {
+		panic("rdbBuilder only writes 6-bit lengths")
+	}
+	b.WriteByte(byte(n))
+	return b
+}
+
+func (b *rdbBuilder) str(s string) *rdbBuilder {
+	b.length(len(s))
+	b.WriteString(s)
+	return b
+}
+
+func (b *rdbBuilder) score(s string) *rdbBuilder {
+	b.WriteByte(byte(len(s)))
+	b.WriteString(s)
+	return b
+}
+
+func (b *rdbBuilder) end() []byte {
+	b.WriteByte(opEOF)
+	b.Write(make([]byte, 8)) // checksum, not verified
+	return b.Bytes()
+}
+
+func TestParserPlainEncodings(t *testing.T) {
+	b := newRDB("0006")
+	b.WriteByte(opSelectDB)
+	b.length(3)
+
+	b.WriteByte(opExpireTime)
+	binary.Write(b, binary.LittleEndian, uint32(1767225600))
+	b.WriteByte(typeString)
+	b.str("session").str("abc")
+
+	b.WriteByte(typeList)
+	b.str("queue").length(3).str("first").str("second").str("third")
+
+	b.WriteByte(typeHash)
+	b.str("user:1").length(2).str("name").str("Ada").str("lang").str("en")
+
+	b.WriteByte(typeZSet)
+	b.str("scores").length(4).str("low").score("-1.5").str("high").score("2e3")
+	// Infinite scores are stored as the lengths 254 and 255 without a value
+	b.str("top").WriteByte(254)
+	b.str("bottom").WriteByte(255)
+
+	entries, parser := readAll(t, bytes.NewReader(b.end()))
+
+	if parser.Version != 6 {
+		t.Errorf("Version = %d, want 6", parser.Version)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("read %d entries, want 4", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.DB != 3 {
+			t.Errorf("%s: db %d, want 3", entry.Key, entry.DB)
+		}
+	}
+
+	if got := entries[0]; got.String != "abc" || !got.ExpireAt.Equal(time.Unix(1767225600, 0)) {
+		t.Errorf("session = %q expiring %v", got.String, got.ExpireAt)
+	}
+	if !entries[1].ExpireAt.IsZero() {
+		t.Errorf("the expiry of session was applied to %s as well", entries[1].Key)
+	}
+	if got := entries[1]; got.Type != TypeList || !reflect.DeepEqual(got.Members, []string{"first", "second", "third"}) {
+		t.Errorf("queue = %s %q", got.Type, got.Members)
+	}
+	if got := entries[2]; got.Type != TypeHash || !reflect.DeepEqual(got.Hash, []HashField{{"name", "Ada"}, {"lang", "en"}}) {
+		t.Errorf("user:1 = %s %v", got.Type, got.Hash)
+	}
+	wantZSet := []ZMember{{"low", -1.5}, {"high", 2000}, {"top", math.Inf(1)}, {"bottom", math.Inf(-1)}}
+	if got := entries[3]; got.Type != TypeZSet || !reflect.DeepEqual(got.ZSet, wantZSet) {
+		t.Errorf("scores = %s %v, want %v", got.Type, got.ZSet, wantZSet)
+	}
+}
+
+func TestParserTruncated(t *testing.T) {
+	files, _ := filepath.Glob(filepath.Join("testdata", "*.rdb"))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Every cut must fail cleanly, wherever it lands in a value or container
+		for cut := 0; cut < len(data); cut += 1 + len(data)/500 {
+			parser := NewParser(bytes.NewReader(data[:cut]))
+			for {
+				_, err = parser.Next()
+				if err != nil {
+					break
+				}
+			}
+			if err == io.EOF {
+				t.Errorf("%s cut at %d of %d bytes: read to the end without an error", filepath.Base(file), cut, len(data))
+			}
+		}
+	}
+}
+
+func TestParserRejectsHugeLengths(t *testing.T) {
+	tests := []struct {
+		name  string
+		value []byte
+	}{
+		// A 2 GB string with 3 bytes of data
+		{name: "string", value: []byte{typeString, 1, 'k', 0x80, 0x7f, 0xff, 0xff, 0xff, 'a', 'b', 'c'}},
+		// An LZF string claiming to decompress 3 bytes into 2 GB
+		{name: "lzf", value: []byte{typeString, 1, 'k', 0xc3, 3, 0x80, 0x7f, 0xff, 0xff, 0xff, 0, 'a', 'b'}},
+		// A list of 2 billion elements
+		{name: "list", value: []byte{typeList, 1, 'k', 0x80, 0x7f, 0xff, 0xff, 0xff, 1, 'a'}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append([]byte("REDIS0009"), tt.value...)
+
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			_, err := NewParser(bytes.NewReader(data)).Next()
+			runtime.ReadMemStats(&after)
+
+			if err == nil || errors.Is(err, io.EOF) {
+				t.Errorf("Next() error = %v, want the corrupt value rejected", err)
+			}
+			if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 64<<20 {
+				t.Errorf("Next() allocated %d MB for a corrupt length", allocated>>20)
+			}
+		})
+	}
+}
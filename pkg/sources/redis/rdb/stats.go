@@ -0,0 +1,136 @@
+package rdb
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// NoPrefix groups keys that do not contain the prefix delimiter
+const NoPrefix = "(no prefix)"
+
+// TTLBuckets lists the TTL distribution buckets in display order
+var TTLBuckets = []string{"no ttl", "expired", "< 1h", "1h - 1d", "1d - 7d", "7d - 30d", "> 30d"}
+
+// Usage counts keys and their serialized size
+type Usage struct {
+	Keys  int64
+	Bytes int64
+}
+
+func (u *Usage) add(size int64) {
+	u.Keys++
+	u.Bytes += size
+}
+
+// KeySize is a key and its serialized size
+type KeySize struct {
+	DB   int
+	Key  string
+	Type Type
+	Size int64
+}
+
+// Stats aggregates key counts, memory and TTLs over the entries of an RDB file.
+// Memory is measured as serialized RDB size, which tracks but understates in-memory usage.
+type Stats struct {
+	Total    Usage
+	ByType   map[Type]*Usage
+	ByDB     map[int]*Usage
+	ByPrefix map[string]*Usage
+	TTL      map[string]int64
+	Largest  []KeySize
+
+	delimiter string
+	top       int
+	now       time.Time
+}
+
+// NewStats creates an aggregator grouping keys by the part before the first delimiter
+// and keeping the top largest keys. TTLs are measured relative to now.
+func NewStats(delimiter string, top int, now time.Time) *Stats {
+	return &Stats{
+		ByType:    map[Type]*Usage{},
+		ByDB:      map[int]*Usage{},
+		ByPrefix:  map[string]*Usage{},
+		TTL:       map[string]int64{},
+		delimiter: delimiter,
+		top:       top,
+		now:       now,
+	}
+}
+
+// Add records an entry
+func (s *Stats) Add(entry *Entry) {
+	s.Total.add(entry.Size)
+	usage(s.ByType, entry.Type).add(entry.Size)
+	usage(s.ByDB, entry.DB).add(entry.Size)
+	usage(s.ByPrefix, s.prefix(entry.Key)).add(entry.Size)
+	s.TTL[s.ttlBucket(entry.ExpireAt)]++
+
+	full := len(s.Largest) >= s.top
+	if s.top > 0 && (!full || entry.Size > s.Largest[len(s.Largest)-1].Size) {
+		s.Largest = append(s.Largest, KeySize{DB: entry.DB, Key: entry.Key, Type: entry.Type, Size: entry.Size})
+		sort.SliceStable(s.Largest, func(i, j int) bool {
+			return s.Largest[i].Size > s.Largest[j].Size
+		})
+		if len(s.Largest) > s.top {
+			s.Largest = s.Largest[:s.top]
+		}
+	}
+}
+
+// Prefixes returns the key prefixes ordered by descending size
+func (s *Stats) Prefixes() []string {
+	prefixes := make([]string, 0, len(s.ByPrefix))
+	for prefix := range s.ByPrefix {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		a, b := s.ByPrefix[prefixes[i]], s.ByPrefix[prefixes[j]]
+		if a.Bytes != b.Bytes {
+			return a.Bytes > b.Bytes
+		}
+		return prefixes[i] < prefixes[j]
+	})
+	return prefixes
+}
+
+func (s *Stats) prefix(key string) string {
+	if s.delimiter == "" {
+		return NoPrefix
+	}
+	if index := strings.Index(key, s.delimiter); index >= 0 {
+		return key[:index+len(s.delimiter)] + "*"
+	}
+	return NoPrefix
+}
+
+func (s *Stats) ttlBucket(expireAt time.Time) string {
+	if expireAt.IsZero() {
+		return "no ttl"
+	}
+
+	ttl := expireAt.Sub(s.now)
+	switch {
+	case ttl <= 0:
+		return "expired"
+	case ttl < time.Hour:
+		return "< 1h"
+	case ttl < 24*time.Hour:
+		return "1h - 1d"
+	case ttl < 7*24*time.Hour:
+		return "1d - 7d"
+	case ttl < 30*24*time.Hour:
+		return "7d - 30d"
+	default:
+		return "> 30d"
+	}
+}
+
+func usage[K comparable](m map[K]*Usage, key K) *Usage {
+	if m[key] == nil {
+		m[key] = &Usage{}
+	}
+	return m[key]
+}
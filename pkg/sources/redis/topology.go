@@ -0,0 +1,147 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// DefaultSentinelPort is used for Sentinel addresses given without a port
+const DefaultSentinelPort = 26379
+
+// ClusterNode is a master shard reported by CLUSTER NODES
+type ClusterNode struct {
+	ID    string
+	Host  string
+	Port  int
+	Slots []string
+}
+
+// Address returns the node address as host:port
+func (n ClusterNode) Address() string {
+	return net.JoinHostPort(n.Host, strconv.Itoa(n.Port))
+}
+
+// discoverMaster asks each Sentinel in turn for the current address of the named master
+func (d *Dumper) discoverMaster(ctx context.Context) (string, int, error) {
+	var lastErr error
+
+	for _, sentinel := range d.Sentinels {
+		host, port, err := splitAddress(sentinel, DefaultSentinelPort)
+		if err != nil {
+			return "", 0, err
+		}
+
+		// Sentinels have their own credentials, separate from the data nodes
+		output, err := d.query(ctx, host, port, "", d.SentinelPassword, "SENTINEL", "get-master-addr-by-name", d.SentinelMaster)
+		if err != nil {
+			lastErr = fmt.Errorf("sentinel %s: %w", sentinel, err)
+			continue
+		}
+
+		fields := strings.Fields(output)
+		if len(fields) != 2 {
+			lastErr = fmt.Errorf("sentinel %s does not know master %q", sentinel, d.SentinelMaster)
+			continue
+		}
+
+		masterPort, err := strconv.Atoi(fields[1])
+		if err != nil {
+			lastErr = fmt.Errorf("sentinel %s returned invalid port %q", sentinel, fields[1])
+			continue
+		}
+
+		return fields[0], masterPort, nil
+	}
+
+	return "", 0, fmt.Errorf("failed to discover Redis master %q: %w", d.SentinelMaster, lastErr)
+}
+
+// discoverClusterMasters lists the master shards of the cluster reachable through the seed node
+func (d *Dumper) discoverClusterMasters(ctx context.Context) ([]ClusterNode, string, error) {
+	output, err := d.query(ctx, d.Host, d.Port, d.Username, d.Password, "CLUSTER", "NODES")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list cluster nodes: %w", err)
+	}
+
+	masters, err := parseClusterNodes(output)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(masters) == 0 {
+		return nil, "", fmt.Errorf("no healthy master nodes found in CLUSTER NODES output")
+	}
+
+	return masters, output, nil
+}
+
+// parseClusterNodes extracts healthy masters from CLUSTER NODES output:
+// <id> <ip:port@cport[,hostname]> <flags> <master> <ping-sent> <pong-recv> <config-epoch>
+// <link-state> <slot> ...
+func parseClusterNodes(output string) ([]ClusterNode, error) {
+	var masters []ClusterNode
+
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 8 {
+			if strings.HasPrefix(line, "ERR") {
+				return nil, fmt.Errorf("CLUSTER NODES failed: %s", strings.TrimSpace(line))
+			}
+			continue
+		}
+
+		flags := strings.Split(fields[2], ",")
+		if !hasFlag(flags, "master") || hasFlag(flags, "fail") || hasFlag(flags, "handshake") || hasFlag(flags, "noaddr") {
+			continue
+		}
+
+		address, _, _ := strings.Cut(fields[1], "@")
+		host, port, err := splitAddress(address, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		// Masters without slots hold no data
+		if len(fields) == 8 {
+			continue
+		}
+
+		masters = append(masters, ClusterNode{
+			ID:    fields[0],
+			Host:  host,
+			Port:  port,
+			Slots: fields[8:],
+		})
+	}
+
+	return masters, nil
+}
+
+func hasFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// splitAddress splits host[:port], using defaultPort when no port is given
+func splitAddress(address string, defaultPort int) (string, int, error) {
+	host, portString, err := net.SplitHostPort(address)
+	if err != nil {
+		if defaultPort == 0 {
+			return "", 0, fmt.Errorf("invalid address %q: %w", address, err)
+		}
+		return strings.Trim(address, "[]"), defaultPort, nil
+	}
+
+	port, err := strconv.Atoi(portString)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port in address %q", address)
+	}
+
+	return host, port, nil
+}
@@ -0,0 +1,56 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// checkClientVersion detects the redis-cli version and fails early when it lacks options
+// the connection needs. ACL users and TLS arrived in redis-cli 6.0.
+func (d *Dumper) checkClientVersion(ctx context.Context) error {
+	if d.engine() == backup.EngineNative {
+		return nil
+	}
+
+	clientVersion, err := backup.ToolVersion(ctx, backup.ToolPath(d.BinDir, "redis-cli"), "redis-bin-dir")
+	if err != nil {
+		return err
+	}
+	d.clientVersion = clientVersion
+
+	client, ok := backup.ParseVersion(clientVersion)
+	if !ok || client.AtLeast(6, 0) {
+		return nil
+	}
+	if d.Username != "" {
+		return fmt.Errorf("%s does not support ACL users (--db-user): install redis-cli 6.0 or newer and pass its directory with --redis-bin-dir, or use --engine native", clientVersion)
+	}
+	if d.TLS.Required() {
+		return fmt.Errorf("%s does not support TLS: install redis-cli 6.0 or newer and pass its directory with --redis-bin-dir, or use --engine native", clientVersion)
+	}
+	return nil
+}
+
+// detectServerVersion records the redis_version of the dumped node from INFO server
+func (d *Dumper) detectServerVersion(ctx context.Context, host string, port int) {
+	output, err := d.query(ctx, host, port, d.Username, d.Password, "INFO", "server")
+	if err != nil {
+		logger.Warnf("⚠️  Could not detect the Redis server version: %v", err)
+		return
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		if version, found := strings.CutPrefix(strings.TrimSpace(line), "redis_version:"); found {
+			d.serverVersion = version
+			break
+		}
+	}
+
+	if d.clientVersion != "" {
+		logger.Infof("🔍 %s, server %s", d.clientVersion, d.serverVersion)
+	}
+}
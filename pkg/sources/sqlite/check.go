@@ -0,0 +1,61 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+)
+
+// Probe checks that sqlite3 supports the backup method and can open the database file
+func (d *Dumper) Probe(ctx context.Context) []backup.Check {
+	var checks []backup.Check
+	if d.Method != "" && d.Method != MethodBackup && d.Method != MethodVacuum {
+		return append(checks, backup.Check{Name: "Options", Err: fmt.Errorf("unsupported SQLite backup method %q (expected %s or %s)", d.Method, MethodBackup, MethodVacuum)})
+	}
+
+	tool := backup.ToolCheck(ctx, "sqlite3", "")
+	if tool.Err == nil && d.Method == MethodVacuum {
+		if version, ok := backup.ParseVersion(tool.Detail); ok && !version.AtLeast(3, 27) {
+			tool.Err = fmt.Errorf("VACUUM INTO needs SQLite 3.27 or newer, found %s: use --method %s", version, MethodBackup)
+		}
+	}
+	checks = append(checks, tool)
+
+	info, err := os.Stat(d.Path)
+	if err == nil && info.IsDir() {
+		err = fmt.Errorf("%s is a directory", d.Path)
+	}
+	if err != nil {
+		return append(checks, backup.Check{Name: "Database file", Err: err})
+	}
+
+	if tool.Err == nil {
+		check := backup.Check{Name: "Database file", Detail: d.Path}
+		cmd := exec.CommandContext(ctx, "sqlite3", "-bail", "-readonly", d.Path, "SELECT count(*) FROM sqlite_master;")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			check.Err = fmt.Errorf("sqlite3 cannot read the database: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+		checks = append(checks, check)
+	}
+
+	return append(checks, backup.SizeCheck(d.EstimateSize(ctx)))
+}
+
+// EstimateSize returns the size of the database file. A WAL file holds committed pages that
+// are not yet in the main file, so it is counted as well.
+func (d *Dumper) EstimateSize(ctx context.Context) (int64, error) {
+	info, err := os.Stat(d.Path)
+	if err != nil {
+		return 0, err
+	}
+
+	size := info.Size()
+	if wal, err := os.Stat(d.Path + "-wal"); err == nil {
+		size += wal.Size()
+	}
+	return size, nil
+}
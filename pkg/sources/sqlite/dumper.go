@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+)
+
+// Backup methods
+const (
+	// MethodBackup copies pages with the online backup API (sqlite3 .backup)
+	MethodBackup = "backup"
+	// MethodVacuum writes a compacted copy with VACUUM INTO (SQLite 3.27+)
+	MethodVacuum = "vacuum"
+)
+
+type Dumper struct {
+	Path        string
+	Method      string
+	BusyTimeout time.Duration
+}
+
+// CreateBackupStream snapshots the database to a temporary file, which stays consistent
+// while the application keeps writing, and streams the copy
+func (d *Dumper) CreateBackupStream(ctx context.Context) (io.ReadCloser, error) {
+	info, err := os.Stat(d.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access SQLite database: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("SQLite database path %s is a directory", d.Path)
+	}
+
+	workDir, err := os.MkdirTemp("", "dbbackup-sqlite-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(workDir) }
+
+	snapshot := filepath.Join(workDir, "snapshot.sqlite")
+	cmd, err := d.snapshotCommand(ctx, snapshot)
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	if output, err := backup.CombinedOutput(cmd); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("sqlite3 failed: %w\nOutput: %s", err, string(output))
+	}
+
+	file, err := os.Open(snapshot)
+	if err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to open SQLite snapshot: %w", err)
+	}
+
+	return &snapshotReader{file: file, cleanup: cleanup}, nil
+}
+
+// snapshotCommand builds the sqlite3 command that writes a snapshot of the database to path
+func (d *Dumper) snapshotCommand(ctx context.Context, snapshot string) (*exec.Cmd, error) {
+	var command string
+	switch d.Method {
+	case "", MethodBackup:
+		command = fmt.Sprintf(".backup %s", quote(snapshot))
+	case MethodVacuum:
+		command = fmt.Sprintf("VACUUM INTO %s;", quote(snapshot))
+	default:
+		return nil, fmt.Errorf("unsupported SQLite backup method %q (expected %s or %s)", d.Method, MethodBackup, MethodVacuum)
+	}
+
+	// -readonly keeps sqlite3 from creating or modifying the source database, and the
+	// busy timeout waits for writers holding locks instead of failing immediately
+	args := []string{
+		"-bail",
+		"-readonly",
+		d.Path,
+		fmt.Sprintf(".timeout %d", d.BusyTimeout.Milliseconds()),
+		command,
+	}
+
+	return exec.CommandContext(ctx, "sqlite3", args...), nil
+}
+
+// DescribeCommand returns the sqlite3 command that snapshots the database to a temporary file
+func (d *Dumper) DescribeCommand() ([]string, error) {
+	cmd, err := d.snapshotCommand(context.Background(), filepath.Join(os.TempDir(), "dbbackup-sqlite-<random>", "snapshot.sqlite"))
+	if err != nil {
+		return nil, err
+	}
+	return []string{backup.FormatCommand(cmd), "then streams the snapshot file"}, nil
+}
+
+// snapshotReader streams the snapshot file and removes it when closed
+type snapshotReader struct {
+	file    *os.File
+	cleanup func()
+}
+
+func (sr *snapshotReader) Read(p []byte) (int, error) {
+	return sr.file.Read(p)
+}
+
+func (sr *snapshotReader) Close() error {
+	defer sr.cleanup()
+	return sr.file.Close()
+}
+
+// quote returns s as a single-quoted SQLite string literal
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// GetFileExtension returns the file extension for SQLite backups
+func (d *Dumper) GetFileExtension() string {
+	return ".sqlite"
+}
+
+// GetDatabaseType returns the database type
+func (d *Dumper) GetDatabaseType() string {
+	return "sqlite"
+}
+
+// GetDatabaseName returns the database file name without its extension
+func (d *Dumper) GetDatabaseName() string {
+	name := filepath.Base(d.Path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
@@ -0,0 +1,94 @@
+package sqlite
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// sidecarSuffixes are the journal files SQLite keeps next to a database
+var sidecarSuffixes = []string{"-wal", "-shm", "-journal"}
+
+// Restorer writes a SQLite backup to a database file
+type Restorer struct {
+	Path string
+	// Force replaces an existing database. The application must be stopped first.
+	Force bool
+}
+
+// Restore writes the (optionally gzip-compressed) backup next to the target, checks its
+// integrity and atomically moves it into place
+func (r *Restorer) Restore(ctx context.Context, reader io.Reader) error {
+	if _, err := os.Stat(r.Path); err == nil && !r.Force {
+		return fmt.Errorf("%s already exists (use --force to replace it)", r.Path)
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(r.Path), "."+filepath.Base(r.Path)+".restore-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tempPath := temp.Name()
+	defer func() {
+		// Opening a WAL-mode copy for the integrity check creates -wal and -shm files
+		for _, suffix := range []string{"", "-wal", "-shm"} {
+			os.Remove(tempPath + suffix)
+		}
+	}()
+
+	buffered := bufio.NewReader(reader)
+	source := io.Reader(buffered)
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			temp.Close()
+			return fmt.Errorf("failed to open gzip stream: %w", err)
+		}
+		defer gz.Close()
+		source = gz
+	}
+
+	if _, err := io.Copy(temp, source); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	if err := checkIntegrity(ctx, tempPath); err != nil {
+		return err
+	}
+
+	// A stale WAL or shared-memory file would be applied to the restored database
+	for _, suffix := range sidecarSuffixes {
+		if err := os.Remove(r.Path + suffix); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", r.Path+suffix, err)
+		}
+	}
+
+	if err := os.Rename(tempPath, r.Path); err != nil {
+		return fmt.Errorf("failed to move restored database into place: %w", err)
+	}
+
+	return nil
+}
+
+func checkIntegrity(ctx context.Context, path string) error {
+	cmd := exec.CommandContext(ctx, "sqlite3", "-bail", "-readonly", path, "PRAGMA integrity_check;")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("sqlite3 integrity check failed: %w\nOutput: %s", err, string(output))
+	}
+
+	if result := strings.TrimSpace(string(output)); result != "ok" {
+		return fmt.Errorf("restored database failed integrity check: %s", result)
+	}
+
+	return nil
+}
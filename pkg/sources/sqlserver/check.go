@@ -0,0 +1,91 @@
+package sqlserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+)
+
+// Probe checks that sqlcmd (and bcp) are installed, that the credentials work, that the
+// login may run the backup method and how large the database files are
+func (d *Dumper) Probe(ctx context.Context) []backup.Check {
+	var checks []backup.Check
+	method := d.method()
+	switch {
+	case d.Database == "":
+		return append(checks, backup.Check{Name: "Options", Err: fmt.Errorf("a database name is required")})
+	case method != MethodBackup && method != MethodBCP:
+		return append(checks, backup.Check{Name: "Options", Err: fmt.Errorf("unsupported SQL Server backup method %q (expected %s or %s)", d.Method, MethodBackup, MethodBCP)})
+	}
+
+	sqlcmd := backup.ToolCheck(ctx, "sqlcmd", "", "-?")
+	checks = append(checks, sqlcmd)
+	if method == MethodBCP {
+		checks = append(checks, backup.ToolCheck(ctx, "bcp", "", "-v"))
+		if d.Password != "" && !d.AllowPasswordArg {
+			checks = append(checks, backup.Check{Name: "bcp authentication", Err: d.checkBCPAuth()})
+		}
+	}
+	if sqlcmd.Err != nil {
+		return checks
+	}
+
+	login, err := d.queryValue(ctx, "SELECT SUSER_SNAME()")
+	if err != nil {
+		return append(checks, backup.Check{Name: "Authentication", Err: err})
+	}
+	checks = append(checks, backup.Check{Name: "Authentication", Detail: fmt.Sprintf("connected to %s as %s", d.Database, login)})
+
+	privileges := backup.Check{Name: "Privileges"}
+	query := "SELECT CASE WHEN IS_SRVROLEMEMBER('sysadmin') = 1 OR IS_MEMBER('db_owner') = 1 OR IS_MEMBER('db_backupoperator') = 1 THEN 1 ELSE 0 END"
+	if method == MethodBCP {
+		query = "SELECT HAS_PERMS_BY_NAME(DB_NAME(), 'DATABASE', 'SELECT')"
+	}
+	if granted, err := d.queryValue(ctx, query); err != nil {
+		privileges.Err = err
+	} else if granted != "1" && method == MethodBCP {
+		privileges.Err = fmt.Errorf("%s cannot SELECT from %s", login, d.Database)
+	} else if granted != "1" {
+		privileges.Err = fmt.Errorf("%s needs sysadmin, db_owner or db_backupoperator to run BACKUP DATABASE", login)
+	} else {
+		privileges.Detail = "allowed to run " + method
+	}
+	checks = append(checks, privileges)
+
+	if method == MethodBackup {
+		dir := localDir(d.BackupDir, d.LocalBackupDir)
+		check := backup.Check{Name: "Backup directory", Detail: dir}
+		if d.BackupDir == "" {
+			check.Err = fmt.Errorf("--backup-dir is required for BACKUP DATABASE dumps")
+		} else if info, err := os.Stat(dir); err != nil {
+			check.Err = fmt.Errorf("backup files are read from %s: %w", dir, err)
+		} else if !info.IsDir() {
+			check.Err = fmt.Errorf("%s is not a directory", dir)
+		}
+		checks = append(checks, check)
+	}
+
+	return append(checks, backup.SizeCheck(d.EstimateSize(ctx)))
+}
+
+// EstimateSize returns the allocated data file pages of 8 KB, leaving out the transaction log
+func (d *Dumper) EstimateSize(ctx context.Context) (int64, error) {
+	output, err := d.queryValue(ctx, "SELECT SUM(CAST(size AS BIGINT)) * 8192 FROM sys.database_files WHERE type = 0")
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(output, 10, 64)
+}
+
+// queryValue runs a query in the dumped database and returns its single value
+func (d *Dumper) queryValue(ctx context.Context, query string) (string, error) {
+	output, err := d.runSQL(ctx, d.Database, "SET NOCOUNT ON; "+query)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
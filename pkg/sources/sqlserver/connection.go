@@ -0,0 +1,189 @@
+package sqlserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// Connection holds the settings shared by the dumper and restorer
+type Connection struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	TLS      backup.TLSConfig
+
+	// AllowPasswordArg lets bcp receive the password with -P, where other local users can
+	// read it in ps output. Without it bcp only runs with integrated authentication.
+	AllowPasswordArg bool
+}
+
+func (c *Connection) server() string {
+	return fmt.Sprintf("tcp:%s,%d", c.Host, c.Port)
+}
+
+// tlsArgs maps TLS options to sqlcmd/bcp options. Both tools verify the server against the
+// system trust store, so a custom CA must be installed there rather than passed as a file.
+func (c *Connection) tlsArgs() ([]string, bool, error) {
+	mode, err := c.TLS.NormalizedMode()
+	if err != nil {
+		return nil, false, err
+	}
+	if c.TLS.CAFile != "" || c.TLS.CertFile != "" || c.TLS.KeyFile != "" {
+		return nil, false, fmt.Errorf("SQL Server tools do not accept certificate files; add the CA to the system trust store and use --db-sslmode")
+	}
+
+	switch mode {
+	case backup.SSLModeRequire:
+		// Encrypt, trusting the server certificate
+		return []string{"-N"}, true, nil
+	case backup.SSLModeVerifyCA, backup.SSLModeVerifyFull:
+		return []string{"-N"}, false, nil
+	}
+
+	return nil, false, nil
+}
+
+// runSQL executes a query with sqlcmd
+func (c *Connection) runSQL(ctx context.Context, database, query string) (string, error) {
+	cmd, err := c.sqlcmdCommand(ctx, database, query)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("sqlcmd failed: %w\nOutput: %s", err, string(output))
+	}
+
+	return string(output), nil
+}
+
+// sqlcmdCommand builds a sqlcmd command for a query. The password is passed via
+// SQLCMDPASSWORD so it is not visible in ps output.
+func (c *Connection) sqlcmdCommand(ctx context.Context, database, query string) (*exec.Cmd, error) {
+	args := []string{
+		"-S", c.server(),
+		"-b",       // exit with an error code when a statement fails
+		"-h", "-1", // no column headers
+		"-W", // trim trailing spaces
+		"-Q", query,
+	}
+	if c.Username != "" {
+		args = append(args, "-U", c.Username)
+	}
+	if database != "" {
+		args = append(args, "-d", database)
+	}
+
+	tlsArgs, trustServerCertificate, err := c.tlsArgs()
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, tlsArgs...)
+	if trustServerCertificate {
+		args = append(args, "-C")
+	}
+
+	cmd := exec.CommandContext(ctx, "sqlcmd", args...)
+	if c.Password != "" {
+		cmd.Env = append(os.Environ(), "SQLCMDPASSWORD="+c.Password)
+	}
+	return cmd, nil
+}
+
+var errBCPPassword = errors.New("bcp takes the password on its command line, where other local users can read it; " +
+	"use integrated authentication (no password), the backup method, or --bcp-password-arg to accept this")
+
+// checkBCPAuth refuses SQL logins for bcp unless the password may be passed as an argument,
+// since bcp cannot read it from the environment or a file
+func (c *Connection) checkBCPAuth() error {
+	if c.Password == "" {
+		return nil
+	}
+	if !c.AllowPasswordArg {
+		return errBCPPassword
+	}
+	logger.Warnf("⚠️  bcp receives the SQL Server password on its command line; other users on this machine can read it in ps output")
+	return nil
+}
+
+// runBCP copies a table in or out with bcp in native format
+func (c *Connection) runBCP(ctx context.Context, database, table, direction, file string) error {
+	cmd, err := c.bcpCommand(ctx, database, table, direction, file)
+	if err != nil {
+		return err
+	}
+
+	output, err := backup.CombinedOutput(cmd)
+	if err != nil {
+		return fmt.Errorf("bcp %s %s failed: %w\nOutput: %s", direction, table, err, string(output))
+	}
+
+	return nil
+}
+
+// bcpCommand builds a bcp command copying a table in or out in native format
+func (c *Connection) bcpCommand(ctx context.Context, database, table, direction, file string) (*exec.Cmd, error) {
+	args := []string{
+		table, direction, file,
+		"-n", // native format
+		"-S", c.server(),
+		"-d", database,
+	}
+	if c.Password == "" {
+		// Integrated authentication (Kerberos, or Windows on Windows)
+		args = append(args, "-T")
+	} else {
+		if !c.AllowPasswordArg {
+			return nil, errBCPPassword
+		}
+		args = append(args, "-U", c.Username, "-P", c.Password)
+	}
+
+	_, trustServerCertificate, err := c.tlsArgs()
+	if err != nil {
+		return nil, err
+	}
+	if trustServerCertificate {
+		args = append(args, "-u")
+	}
+	if direction == "in" {
+		// Keep identity values from the export
+		args = append(args, "-E")
+	}
+
+	return exec.CommandContext(ctx, "bcp", args...), nil
+}
+
+// quoteString returns s as a T-SQL Unicode string literal
+func quoteString(s string) string {
+	return "N'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// quoteIdentifier returns s as a bracketed T-SQL identifier
+func quoteIdentifier(s string) string {
+	return "[" + strings.ReplaceAll(s, "]", "]]") + "]"
+}
+
+// fileReader streams a file and runs cleanup when closed
+type fileReader struct {
+	file    *os.File
+	cleanup func()
+}
+
+func (fr *fileReader) Read(p []byte) (int, error) {
+	return fr.file.Read(p)
+}
+
+func (fr *fileReader) Close() error {
+	defer fr.cleanup()
+	return fr.file.Close()
+}
@@ -0,0 +1,224 @@
+package sqlserver
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// Backup methods
+const (
+	// MethodBackup runs BACKUP DATABASE ... TO DISK and streams the .bak file
+	MethodBackup = "backup"
+	// MethodBCP exports every user table in bcp native format (data only)
+	MethodBCP = "bcp"
+)
+
+// TableIndexFile lists the tables of a bcp archive as schema<TAB>table<TAB>file lines
+const TableIndexFile = "tables.tsv"
+
+type Dumper struct {
+	Connection
+	Database string
+	Method   string
+
+	// BackupDir is where the server writes the .bak file. LocalBackupDir is the same
+	// directory as seen from this machine (e.g. a shared volume); it defaults to BackupDir.
+	BackupDir      string
+	LocalBackupDir string
+}
+
+func (d *Dumper) CreateBackupStream(ctx context.Context) (io.ReadCloser, error) {
+	if d.Database == "" {
+		return nil, fmt.Errorf("a database name is required")
+	}
+
+	switch d.method() {
+	case MethodBackup:
+		return d.createBackup(ctx)
+	case MethodBCP:
+		return d.createBCPExport(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported SQL Server backup method %q (expected %s or %s)", d.Method, MethodBackup, MethodBCP)
+	}
+}
+
+func (d *Dumper) createBackup(ctx context.Context) (io.ReadCloser, error) {
+	if d.BackupDir == "" {
+		return nil, fmt.Errorf("--backup-dir is required for BACKUP DATABASE dumps")
+	}
+
+	name := fmt.Sprintf("dbbackup_%s_%d.bak", d.Database, time.Now().UnixNano())
+	statement := d.backupStatement(name)
+
+	logger.Infof("📦 Running %s", statement)
+	if _, err := d.runSQL(ctx, "", statement); err != nil {
+		return nil, err
+	}
+
+	localPath := filepath.Join(localDir(d.BackupDir, d.LocalBackupDir), name)
+	file, err := os.Open(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("backup file not found at %s (set --local-backup-dir to where this machine sees --backup-dir): %w", localPath, err)
+	}
+
+	return &fileReader{file: file, cleanup: func() { os.Remove(localPath) }}, nil
+}
+
+// backupStatement returns the BACKUP DATABASE statement writing the file name into BackupDir.
+// COPY_ONLY leaves the server's own backup chain untouched.
+func (d *Dumper) backupStatement(name string) string {
+	return fmt.Sprintf("BACKUP DATABASE %s TO DISK = %s WITH COPY_ONLY, CHECKSUM, INIT",
+		quoteIdentifier(d.Database), quoteString(serverPath(d.BackupDir, name)))
+}
+
+// DescribeCommand returns the sqlcmd BACKUP DATABASE command, or the bcp command run for
+// each table, with passwords redacted
+func (d *Dumper) DescribeCommand() ([]string, error) {
+	if d.Database == "" {
+		return nil, fmt.Errorf("a database name is required")
+	}
+
+	ctx := context.Background()
+	switch d.method() {
+	case MethodBackup:
+		if d.BackupDir == "" {
+			return nil, fmt.Errorf("--backup-dir is required for BACKUP DATABASE dumps")
+		}
+		name := fmt.Sprintf("dbbackup_%s_<timestamp>.bak", d.Database)
+		cmd, err := d.sqlcmdCommand(ctx, "", d.backupStatement(name))
+		if err != nil {
+			return nil, err
+		}
+		return []string{
+			backup.FormatCommand(cmd),
+			"then streams " + filepath.Join(localDir(d.BackupDir, d.LocalBackupDir), name),
+		}, nil
+	case MethodBCP:
+		cmd, err := d.bcpCommand(ctx, d.Database, "<schema>.<table>", "out", "table-<n>.bcp")
+		if err != nil {
+			return nil, err
+		}
+		// bcp takes the password as an argument, which FormatCommand cannot recognize
+		cmd.Args = redactPasswordArg(cmd.Args)
+		return []string{
+			"lists the user tables of " + d.Database + " with sqlcmd",
+			backup.FormatCommand(cmd) + " for each table",
+			"then archives the exported files with " + TableIndexFile,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported SQL Server backup method %q (expected %s or %s)", d.Method, MethodBackup, MethodBCP)
+	}
+}
+
+// redactPasswordArg returns a copy of bcp arguments with the value of -P redacted
+func redactPasswordArg(args []string) []string {
+	redacted := append([]string(nil), args...)
+	for i := 0; i+1 < len(redacted); i++ {
+		if redacted[i] == "-P" {
+			redacted[i+1] = logger.Redacted
+		}
+	}
+	return redacted
+}
+
+func (d *Dumper) createBCPExport(ctx context.Context) (io.ReadCloser, error) {
+	if err := d.checkBCPAuth(); err != nil {
+		return nil, err
+	}
+
+	output, err := d.runSQL(ctx, d.Database, "SET NOCOUNT ON; "+
+		"SELECT s.name + CHAR(9) + t.name FROM sys.tables t "+
+		"JOIN sys.schemas s ON s.schema_id = t.schema_id "+
+		"WHERE t.is_ms_shipped = 0 ORDER BY s.name, t.name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	workDir, err := os.MkdirTemp("", "dbbackup-bcp-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(workDir) }
+
+	var index strings.Builder
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for count := 0; scanner.Scan(); {
+		schema, table, found := strings.Cut(strings.TrimSpace(scanner.Text()), "\t")
+		if !found {
+			continue
+		}
+
+		file := fmt.Sprintf("table-%04d.bcp", count)
+		count++
+
+		qualified := fmt.Sprintf("%s.%s", quoteIdentifier(schema), quoteIdentifier(table))
+		logger.Infof("📦 Exporting %s", qualified)
+		if err := d.runBCP(ctx, d.Database, qualified, "out", filepath.Join(workDir, file)); err != nil {
+			cleanup()
+			return nil, err
+		}
+
+		fmt.Fprintf(&index, "%s\t%s\t%s\n", schema, table, file)
+	}
+
+	if err := os.WriteFile(filepath.Join(workDir, TableIndexFile), []byte(index.String()), 0600); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to write table index: %w", err)
+	}
+
+	return backup.StreamTarDirectory(workDir, cleanup), nil
+}
+
+func (d *Dumper) method() string {
+	if d.Method == "" {
+		return MethodBackup
+	}
+	return d.Method
+}
+
+// serverPath joins a file name onto a directory on the server, which may run on Windows
+func serverPath(dir, name string) string {
+	if strings.Contains(dir, `\`) {
+		return strings.TrimRight(dir, `\`) + `\` + name
+	}
+	return strings.TrimRight(dir, "/") + "/" + name
+}
+
+func localDir(backupDir, localBackupDir string) string {
+	if localBackupDir != "" {
+		return localBackupDir
+	}
+	return backupDir
+}
+
+// GetFileExtension returns the file extension for SQL Server backups
+func (d *Dumper) GetFileExtension() string {
+	if d.method() == MethodBCP {
+		return ".tar"
+	}
+	return ".bak"
+}
+
+// GetDatabaseType returns the database type
+func (d *Dumper) GetDatabaseType() string {
+	return "sqlserver"
+}
+
+// GetDatabaseName returns the database name
+func (d *Dumper) GetDatabaseName() string {
+	return d.Database
+}
+
+// GetDatabaseHost returns the database host for object key templates
+func (d *Dumper) GetDatabaseHost() string {
+	return d.Host
+}
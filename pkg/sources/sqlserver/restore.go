@@ -0,0 +1,115 @@
+package sqlserver
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dbbackup-io/cli/pkg/backup"
+	"github.com/dbbackup-io/cli/pkg/logger"
+)
+
+// Restorer loads SQL Server backups
+type Restorer struct {
+	Connection
+	Database string
+
+	// BackupDir and LocalBackupDir locate a directory shared with the server, as for the dumper
+	BackupDir      string
+	LocalBackupDir string
+
+	// Move relocates database files on restore, as logical name => physical path
+	Move map[string]string
+}
+
+// RestoreBackup copies a .bak file to the shared directory and runs RESTORE DATABASE ... WITH REPLACE
+func (r *Restorer) RestoreBackup(ctx context.Context, reader io.Reader) error {
+	if r.BackupDir == "" {
+		return fmt.Errorf("--backup-dir is required to restore a .bak backup")
+	}
+
+	input, closeInput, err := backup.MaybeGunzip(reader)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	name := fmt.Sprintf("dbbackup_restore_%d.bak", time.Now().UnixNano())
+	localPath := filepath.Join(localDir(r.BackupDir, r.LocalBackupDir), name)
+
+	file, err := os.Create(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", localPath, err)
+	}
+	defer os.Remove(localPath)
+
+	if _, err := io.Copy(file, input); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", localPath, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", localPath, err)
+	}
+
+	options := []string{"REPLACE", "CHECKSUM"}
+	for logical, physical := range r.Move {
+		options = append(options, fmt.Sprintf("MOVE %s TO %s", quoteString(logical), quoteString(physical)))
+	}
+
+	statement := fmt.Sprintf("RESTORE DATABASE %s FROM DISK = %s WITH %s",
+		quoteIdentifier(r.Database), quoteString(serverPath(r.BackupDir, name)), strings.Join(options, ", "))
+
+	logger.Infof("📦 Running %s", statement)
+	_, err = r.runSQL(ctx, "", statement)
+	return err
+}
+
+// RestoreBCP loads a bcp archive into existing tables of the target database
+func (r *Restorer) RestoreBCP(ctx context.Context, reader io.Reader) error {
+	if err := r.checkBCPAuth(); err != nil {
+		return err
+	}
+
+	input, closeInput, err := backup.MaybeGunzip(reader)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	workDir, err := os.MkdirTemp("", "dbbackup-bcp-restore-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	if err := backup.ExtractTar(input, workDir); err != nil {
+		return err
+	}
+
+	index, err := os.Open(filepath.Join(workDir, TableIndexFile))
+	if err != nil {
+		return fmt.Errorf("archive has no %s: %w", TableIndexFile, err)
+	}
+	defer index.Close()
+
+	scanner := bufio.NewScanner(index)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 3 {
+			continue
+		}
+
+		qualified := fmt.Sprintf("%s.%s", quoteIdentifier(fields[0]), quoteIdentifier(fields[1]))
+		logger.Infof("📦 Importing %s", qualified)
+		if err := r.runBCP(ctx, r.Database, qualified, "in", filepath.Join(workDir, filepath.Base(fields[2]))); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}